@@ -0,0 +1,344 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	maxAnalyticsBuckets   = 1000
+	defaultAnalyticsRange = 30 * 24 * time.Hour
+	// Bu süreden uzun açık kalan görevler (ör. gece boyunca kapatılmayanlar) süre istatistiklerine katılmaz
+	cleaningOutlierLimit = 4 * time.Hour
+)
+
+// analyticsFilter analiz uç noktalarında ortak kullanılan tarih ve kapsam filtresi
+type analyticsFilter struct {
+	From      time.Time
+	To        time.Time // Hariç
+	ToiletIDs []int     // nil ise tüm tuvaletler
+	CleanerID uint      // 0 ise tüm temizlikçiler
+}
+
+// parseTimeParam "2006-01-02" veya RFC3339 formatındaki tarihi çözer.
+// Sadece gün verilmişse ve endOfDay true ise günün sonunu (ertesi gün 00:00) döner.
+func parseTimeParam(value string, endOfDay bool) (time.Time, error) {
+	if day, err := time.ParseInLocation("2006-01-02", value, facilityLocation); err == nil {
+		if endOfDay {
+			return day.AddDate(0, 0, 1), nil
+		}
+		return day, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// parseAnalyticsFilter sorgu parametrelerinden filtreyi oluşturur, geçersiz parametrede doğrulama hatası döner
+func parseAnalyticsFilter(c *gin.Context, defaultRange time.Duration) (analyticsFilter, error) {
+	filter := analyticsFilter{To: facilityNow()}
+
+	if to := c.Query("to"); to != "" {
+		parsed, err := parseTimeParam(to, true)
+		if err != nil {
+			return filter, invalidParam("to")
+		}
+		filter.To = parsed
+	}
+
+	filter.From = filter.To.Add(-defaultRange)
+	if from := c.Query("from"); from != "" {
+		parsed, err := parseTimeParam(from, false)
+		if err != nil {
+			return filter, invalidParam("from")
+		}
+		filter.From = parsed
+	}
+
+	if !filter.From.Before(filter.To) {
+		return filter, validationError(fieldError("from", RuleBefore, "to"))
+	}
+
+	if cleanerID := c.Query("cleaner_id"); cleanerID != "" {
+		id, err := strconv.ParseUint(cleanerID, 10, 32)
+		if err != nil {
+			return filter, invalidParam("cleaner_id")
+		}
+		filter.CleanerID = uint(id)
+	}
+
+	// Tuvalet ve kat filtreleri tuvalet ID listesine çevrilir
+	toiletID := c.Query("toilet_id")
+	floor := c.Query("floor")
+	if toiletID != "" || floor != "" {
+		query := DB.Model(&Toilet{})
+		if toiletID != "" {
+			id, err := strconv.Atoi(toiletID)
+			if err != nil {
+				return filter, invalidParam("toilet_id")
+			}
+			query = query.Where("id = ?", id)
+		}
+		if floor != "" {
+			query = query.Where("location = ?", floor)
+		}
+
+		filter.ToiletIDs = []int{}
+		if err := query.Pluck("id", &filter.ToiletIDs).Error; err != nil {
+			return filter, internalError(err, "Tuvaletler getirilirken hata oluştu")
+		}
+	}
+
+	return filter, nil
+}
+
+// scopeToilets sorguyu filtredeki tuvaletlerle sınırlar
+func (f analyticsFilter) scopeToilets(query *gorm.DB) *gorm.DB {
+	if f.ToiletIDs != nil {
+		return query.Where("toilet_id IN ?", f.ToiletIDs)
+	}
+	return query
+}
+
+// ratingsInRange filtre aralığındaki puanlamaları getirir
+func (f analyticsFilter) ratingsInRange() ([]Rating, error) {
+	var ratings []Rating
+	err := f.scopeToilets(DB.Model(&Rating{})).
+		Where("created_at >= ? AND created_at < ?", f.From, f.To).
+		Order("created_at ASC").
+		Find(&ratings).Error
+	return ratings, err
+}
+
+// completedTasksInRange filtre aralığında tamamlanan görevleri getirir
+func (f analyticsFilter) completedTasksInRange() ([]CleaningTask, error) {
+	query := f.scopeToilets(DB.Model(&CleaningTask{})).
+		Where("status = ? AND completed_at >= ? AND completed_at < ?", "completed", f.From, f.To)
+	if f.CleanerID != 0 {
+		query = query.Where("cleaner_id = ?", f.CleanerID)
+	}
+
+	var tasks []CleaningTask
+	err := query.Order("completed_at ASC").Find(&tasks).Error
+	return tasks, err
+}
+
+// cleaningMinutes görevin başlangıç ve bitişi arasındaki süreyi saniye hassasiyetinde dakika olarak döner
+func cleaningMinutes(task CleaningTask) (float64, bool) {
+	if task.StartedAt == nil || task.CompletedAt == nil || task.CompletedAt.Before(*task.StartedAt) {
+		return 0, false
+	}
+	return task.CompletedAt.Sub(*task.StartedAt).Seconds() / 60, true
+}
+
+// isCleaningOutlier sürenin istatistiklerden çıkarılacak kadar uzun olup olmadığını döner
+func isCleaningOutlier(minutes float64) bool {
+	return minutes > cleaningOutlierLimit.Minutes()
+}
+
+// percentile sıralı değerlerde doğrusal enterpolasyonla p (0-1 arası) yüzdelik değerini hesaplar
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	rank := p * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+// truncateToBucket zamanı ait olduğu aralığın başlangıcına indirir (haftalar pazartesi başlar)
+func truncateToBucket(t time.Time, bucket string) time.Time {
+	switch bucket {
+	case "hour":
+		// Yerel saate göre kesilir; yaz saati geçişinde tekrar eden saat iki ayrı aralık olarak kalır
+		_, offset := t.In(facilityLocation).Zone()
+		shift := time.Duration(offset) * time.Second
+		return t.Add(shift).Truncate(time.Hour).Add(-shift).In(facilityLocation)
+	case "week":
+		return startOfWeek(t)
+	default:
+		return startOfDay(t)
+	}
+}
+
+// nextBucket bir sonraki aralığın başlangıcını döner.
+// Saatlik aralıklar mutlak bir saat ilerler; yaz saati geçişinde gün 23 veya 25 aralık içerir.
+func nextBucket(start time.Time, bucket string) time.Time {
+	switch bucket {
+	case "hour":
+		return start.Add(time.Hour)
+	case "week":
+		return start.AddDate(0, 0, 7)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// errTooManyBuckets seçilen aralık birimiyle tarih aralığı maxAnalyticsBuckets sınırını aştığında döner
+var errTooManyBuckets = errors.New("çok fazla zaman aralığı")
+
+// buildAnalyticsSeries filtre aralığını boş aralıklar dahil zaman serisine böler ve metrikleri hesaplar
+func buildAnalyticsSeries(filter analyticsFilter, bucket string) ([]AnalyticsBucket, error) {
+	series := []AnalyticsBucket{}
+	index := map[int64]int{}
+	for start := truncateToBucket(filter.From, bucket); start.Before(filter.To); start = nextBucket(start, bucket) {
+		if len(series) >= maxAnalyticsBuckets {
+			return nil, errTooManyBuckets
+		}
+		index[start.Unix()] = len(series)
+		series = append(series, AnalyticsBucket{Start: start, ProblemsByType: map[int]int{}})
+	}
+
+	ratings, err := filter.ratingsInRange()
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := filter.completedTasksInRange()
+	if err != nil {
+		return nil, err
+	}
+
+	ratingSums := make([]int, len(series))
+	for _, rating := range ratings {
+		i, ok := index[truncateToBucket(rating.CreatedAt, bucket).Unix()]
+		if !ok {
+			continue
+		}
+
+		series[i].RatingCount++
+		ratingSums[i] += rating.Rating
+
+		for _, problemID := range ratingProblemIDs(rating) {
+			series[i].ProblemCount++
+			series[i].ProblemsByType[problemID]++
+		}
+	}
+
+	durations := make([][]float64, len(series))
+	for _, task := range tasks {
+		i, ok := index[truncateToBucket(*task.CompletedAt, bucket).Unix()]
+		if !ok {
+			continue
+		}
+
+		series[i].TasksCompleted++
+		if minutes, ok := cleaningMinutes(task); ok && !isCleaningOutlier(minutes) {
+			durations[i] = append(durations[i], minutes)
+		}
+	}
+
+	for i := range series {
+		if series[i].RatingCount > 0 {
+			avg := float64(ratingSums[i]) / float64(series[i].RatingCount)
+			series[i].AverageRating = &avg
+		}
+		if len(durations[i]) > 0 {
+			sort.Float64s(durations[i])
+			median := percentile(durations[i], 0.5)
+			series[i].MedianCleaningMinutes = &median
+		}
+	}
+
+	return series, nil
+}
+
+// getAnalytics puanlama ve temizlik metriklerini saatlik/günlük/haftalık zaman serisi olarak döner (sadece admin erişimi)
+func getAnalytics(c *gin.Context) {
+	bucket := c.DefaultQuery("bucket", "day")
+	if bucket != "hour" && bucket != "day" && bucket != "week" {
+		respondError(c, validationError(fieldError("bucket", RuleOneOf, "hour day week")))
+		return
+	}
+
+	filter, err := parseAnalyticsFilter(c, defaultAnalyticsRange)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	series, err := buildAnalyticsSeries(filter, bucket)
+	if errors.Is(err, errTooManyBuckets) {
+		respondError(c, apiError(CodeRangeTooLarge))
+		return
+	}
+	if err != nil {
+		respondError(c, internalError(err, "Analiz verileri getirilirken hata oluştu"))
+		return
+	}
+
+	c.JSON(http.StatusOK, AnalyticsResponse{
+		Success:      true,
+		Message:      messageText(c, MsgAnalyticsFetched),
+		Bucket:       bucket,
+		From:         &filter.From,
+		To:           &filter.To,
+		ProblemTypes: problemTypeLabels(requestLanguage(c)),
+		Series:       series,
+	})
+}
+
+// getProblemHeatmap problem bildirimlerini haftanın günü ve günün saatine göre 7x24 matris olarak döner (sadece admin erişimi)
+func getProblemHeatmap(c *gin.Context) {
+	filter, err := parseAnalyticsFilter(c, defaultAnalyticsRange)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	problemType := 0
+	if value := c.Query("problem_type"); value != "" {
+		id, err := strconv.Atoi(value)
+		if _, known := ProblemTypes[id]; err != nil || !known {
+			respondError(c, validationError(fieldError("problem_type", RuleUnknownValue, value)))
+			return
+		}
+		problemType = id
+	}
+
+	ratings, err := filter.ratingsInRange()
+	if err != nil {
+		respondError(c, internalError(err, "Puanlamalar getirilirken hata oluştu"))
+		return
+	}
+
+	matrix := make([][]int, 7)
+	for i := range matrix {
+		matrix[i] = make([]int, 24)
+	}
+
+	total, peak := 0, 0
+	for _, rating := range ratings {
+		local := rating.CreatedAt.In(facilityLocation)
+		day := (int(local.Weekday()) + 6) % 7
+
+		for _, problemID := range ratingProblemIDs(rating) {
+			if problemType != 0 && problemID != problemType {
+				continue
+			}
+			matrix[day][local.Hour()]++
+			total++
+			if matrix[day][local.Hour()] > peak {
+				peak = matrix[day][local.Hour()]
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, HeatmapResponse{
+		Success:     true,
+		Message:     messageText(c, MsgHeatmapFetched),
+		From:        &filter.From,
+		To:          &filter.To,
+		ProblemType: problemType,
+		Total:       total,
+		Max:         peak,
+		Weekdays:    weekdayLabels(c),
+		Matrix:      matrix,
+	})
+}
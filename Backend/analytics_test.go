@@ -0,0 +1,216 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// insertRating puanlamayı verilen zamanla doğrudan veritabanına ekler
+func (s *testServer) insertRating(toiletID, rating int, problems string, createdAt time.Time) Rating {
+	s.t.Helper()
+
+	r := Rating{ToiletID: toiletID, Rating: rating, Problems: problems, CreatedAt: createdAt}
+	if err := s.db.Create(&r).Error; err != nil {
+		s.t.Fatalf("Puanlama eklenemedi: %v", err)
+	}
+	return r
+}
+
+func TestTruncateAndNextBucket(t *testing.T) {
+	loc := setFacilityTimezone(t, "Europe/Istanbul")
+	// 2026-03-11 çarşamba
+	at := time.Date(2026, 3, 11, 14, 37, 12, 0, loc)
+
+	tests := []struct {
+		bucket          string
+		wantStart, next time.Time
+	}{
+		{"hour", time.Date(2026, 3, 11, 14, 0, 0, 0, loc), time.Date(2026, 3, 11, 15, 0, 0, 0, loc)},
+		{"day", time.Date(2026, 3, 11, 0, 0, 0, 0, loc), time.Date(2026, 3, 12, 0, 0, 0, 0, loc)},
+		{"week", time.Date(2026, 3, 9, 0, 0, 0, 0, loc), time.Date(2026, 3, 16, 0, 0, 0, 0, loc)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.bucket, func(t *testing.T) {
+			start := truncateToBucket(at, tt.bucket)
+			if !start.Equal(tt.wantStart) {
+				t.Fatalf("Aralık başı %s, beklenen %s", start, tt.wantStart)
+			}
+			if next := nextBucket(start, tt.bucket); !next.Equal(tt.next) {
+				t.Fatalf("Sonraki aralık %s, beklenen %s", next, tt.next)
+			}
+			// Aralık başı zaten kendi aralığındadır
+			if again := truncateToBucket(start, tt.bucket); !again.Equal(start) {
+				t.Fatalf("Aralık başı tekrar kesilince %s oldu", again)
+			}
+		})
+	}
+}
+
+func TestBuildAnalyticsSeries(t *testing.T) {
+	s := newTestServer(t)
+	loc := setFacilityTimezone(t, "Europe/Istanbul")
+	cleaner := s.createUser("analiz", "Analiz", "temizlikci")
+
+	day := func(d, hour int) time.Time { return time.Date(2026, 3, d, hour, 0, 0, 0, loc) }
+
+	// 10 Mart: iki puanlama ve iki görev, 11 Mart boş, 12 Mart: bir puanlama, aralık dışında bir puanlama
+	s.insertRating(1, 2, "[1,3]", day(10, 9))
+	s.insertRating(2, 5, "[]", day(10, 18))
+	s.insertRating(1, 3, "[3]", day(12, 8))
+	s.insertRating(1, 1, "[2]", day(13, 0))
+	s.insertTask(cleaner, 1, "completed", timePtr(day(10, 10)), timePtr(day(10, 10).Add(10*time.Minute)))
+	s.insertTask(cleaner, 2, "completed", timePtr(day(10, 11)), timePtr(day(10, 11).Add(20*time.Minute)))
+	// Aykırı süre medyana katılmaz ama tamamlanan görev sayılır
+	s.insertTask(cleaner, 3, "completed", timePtr(day(12, 1)), timePtr(day(12, 1).Add(cleaningOutlierLimit+time.Minute)))
+
+	series, err := buildAnalyticsSeries(analyticsFilter{From: day(10, 0), To: day(13, 0)}, "day")
+	if err != nil {
+		t.Fatalf("Zaman serisi oluşturulamadı: %v", err)
+	}
+
+	type want struct {
+		ratings, problems, tasks int
+		average, median          float64 // -1: null
+		byType                   map[int]int
+	}
+	wants := []want{
+		{2, 2, 2, 3.5, 15, map[int]int{1: 1, 3: 1}},
+		{0, 0, 0, -1, -1, map[int]int{}},
+		{1, 1, 1, 3, -1, map[int]int{3: 1}},
+	}
+	if len(series) != len(wants) {
+		t.Fatalf("Aralık sayısı %d, beklenen %d", len(series), len(wants))
+	}
+
+	nullable := func(v *float64) float64 {
+		if v == nil {
+			return -1
+		}
+		return *v
+	}
+	for i, w := range wants {
+		b := series[i]
+		if !b.Start.Equal(day(10+i, 0)) {
+			t.Fatalf("%d. aralık başı %s", i, b.Start)
+		}
+		if b.RatingCount != w.ratings || b.ProblemCount != w.problems || b.TasksCompleted != w.tasks ||
+			math.Abs(nullable(b.AverageRating)-w.average) > 1e-9 || math.Abs(nullable(b.MedianCleaningMinutes)-w.median) > 1e-9 {
+			t.Fatalf("%d. aralık %+v, beklenen %+v", i, b, w)
+		}
+		if fmt.Sprint(b.ProblemsByType) != fmt.Sprint(w.byType) {
+			t.Fatalf("%d. aralık problem dağılımı %v, beklenen %v", i, b.ProblemsByType, w.byType)
+		}
+	}
+
+	// Tuvalet ve temizlikçi filtresi
+	series, err = buildAnalyticsSeries(analyticsFilter{From: day(10, 0), To: day(11, 0), ToiletIDs: []int{2}, CleanerID: cleaner.ID}, "day")
+	if err != nil {
+		t.Fatalf("Zaman serisi oluşturulamadı: %v", err)
+	}
+	if b := series[0]; b.RatingCount != 1 || b.ProblemCount != 0 || b.TasksCompleted != 1 || *b.MedianCleaningMinutes != 20 {
+		t.Fatalf("Filtrelenmiş aralık %+v", b)
+	}
+}
+
+func TestAnalyticsBucketLimit(t *testing.T) {
+	s := newTestServer(t)
+	loc := setFacilityTimezone(t, "Europe/Istanbul")
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, loc)
+
+	tests := []struct {
+		name    string
+		bucket  string
+		to      time.Time
+		wantErr bool
+	}{
+		{"sınırda saatlik", "hour", from.Add(maxAnalyticsBuckets * time.Hour), false},
+		{"sınırı aşan saatlik", "hour", from.Add((maxAnalyticsBuckets + 1) * time.Hour), true},
+		{"aynı aralık günlük", "day", from.Add((maxAnalyticsBuckets + 1) * time.Hour), false},
+		{"sınırı aşan günlük", "day", from.AddDate(0, 0, maxAnalyticsBuckets+1), true},
+		{"aynı aralık haftalık", "week", from.AddDate(0, 0, maxAnalyticsBuckets+1), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			series, err := buildAnalyticsSeries(analyticsFilter{From: from, To: tt.to}, tt.bucket)
+			if tt.wantErr {
+				if !errors.Is(err, errTooManyBuckets) {
+					t.Fatalf("errTooManyBuckets bekleniyordu, hata: %v", err)
+				}
+				return
+			}
+			if err != nil || len(series) > maxAnalyticsBuckets {
+				t.Fatalf("%d aralık, hata: %v", len(series), err)
+			}
+		})
+	}
+
+	expectError(t, s, http.MethodGet, "/api/admin/analytics?bucket=hour&from=2024-01-01&to=2026-01-01", nil, s.adminHeaders(), http.StatusBadRequest, CodeRangeTooLarge)
+}
+
+func TestAnalyticsRequiresAdmin(t *testing.T) {
+	s := newTestServer(t)
+	cleaner := s.createUser("ayse", "Ayşe", "temizlikci")
+	path := "/api/admin/analytics?bucket=day"
+
+	expectError(t, s, http.MethodGet, path, nil, nil, http.StatusUnauthorized, CodeUnauthorized)
+	expectError(t, s, http.MethodGet, path, nil, staffHeaders(cleaner), http.StatusForbidden, CodeForbidden)
+	expectStatus(t, s.request(http.MethodGet, path, nil, s.adminHeaders()), http.StatusOK)
+}
+
+func TestPercentile(t *testing.T) {
+	tests := []struct {
+		name   string
+		sorted []float64
+		p      float64
+		want   float64
+	}{
+		{"tek değer", []float64{7}, 0.9, 7},
+		{"tek sayıda medyan", []float64{1, 3, 9}, 0.5, 3},
+		{"çift sayıda medyan", []float64{1, 3, 5, 9}, 0.5, 4},
+		{"%90 enterpolasyon", []float64{10, 20, 30, 40, 50, 60, 70, 80, 90, 100}, 0.9, 91},
+		{"en küçük", []float64{2, 4, 8}, 0, 2},
+		{"en büyük", []float64{2, 4, 8}, 1, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := percentile(tt.sorted, tt.p); math.Abs(got-tt.want) > 1e-9 {
+				t.Fatalf("percentile(%v, %v) = %v, beklenen %v", tt.sorted, tt.p, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCleaningMinutesAndOutliers(t *testing.T) {
+	start := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		started     *time.Time
+		completed   *time.Time
+		wantMinutes float64
+		wantOK      bool
+		outlier     bool
+	}{
+		{"saniye hassasiyeti", timePtr(start), timePtr(start.Add(12*time.Minute + 30*time.Second)), 12.5, true, false},
+		{"sınırda", timePtr(start), timePtr(start.Add(cleaningOutlierLimit)), cleaningOutlierLimit.Minutes(), true, false},
+		{"gece açık kalmış", timePtr(start), timePtr(start.Add(cleaningOutlierLimit + time.Second)), cleaningOutlierLimit.Minutes() + 1.0/60, true, true},
+		{"başlamadan bitmiş", timePtr(start), timePtr(start.Add(-time.Minute)), 0, false, false},
+		{"başlangıç yok", nil, timePtr(start), 0, false, false},
+		{"bitiş yok", timePtr(start), nil, 0, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			minutes, ok := cleaningMinutes(CleaningTask{StartedAt: tt.started, CompletedAt: tt.completed})
+			if ok != tt.wantOK || math.Abs(minutes-tt.wantMinutes) > 1e-9 {
+				t.Fatalf("cleaningMinutes = %v, %v; beklenen %v, %v", minutes, ok, tt.wantMinutes, tt.wantOK)
+			}
+			if ok && isCleaningOutlier(minutes) != tt.outlier {
+				t.Fatalf("isCleaningOutlier(%v) = %v", minutes, !tt.outlier)
+			}
+		})
+	}
+}
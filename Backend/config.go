@@ -0,0 +1,553 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// defaultConfigFile CONFIG_FILE verilmemişse aranan yapılandırma dosyası (yoksa atlanır)
+const defaultConfigFile = "config.json"
+
+// Duration yapılandırma dosyasında "15s", "2m" gibi yazılan süre
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("süre metin olarak yazılmalı (ör. \"15s\")")
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	d.Duration = parsed
+	return nil
+}
+
+// ServerConfig HTTP sunucusu ayarları
+type ServerConfig struct {
+	Port         int      `json:"port"`
+	ReadTimeout  Duration `json:"read_timeout"`
+	WriteTimeout Duration `json:"write_timeout"` // Büyük dışa aktarmalar için yeterince uzun olmalı
+	IdleTimeout  Duration `json:"idle_timeout"`
+	// Kapanırken devam eden isteklerin ve arka plan işlerinin bitmesi için beklenen en uzun süre
+	ShutdownTimeout Duration `json:"shutdown_timeout"`
+}
+
+// DatabaseConfig veritabanı bağlantı ayarları
+type DatabaseConfig struct {
+	Driver   string `json:"driver"` // mysql, postgres, sqlite
+	User     string `json:"user"`
+	Password string `json:"password"`
+	Host     string `json:"host"`
+	Port     string `json:"port"`
+	Name     string `json:"name"`
+	SSLMode  string `json:"sslmode"` // Sadece PostgreSQL
+	Path     string `json:"path"`    // Sadece SQLite
+}
+
+// CORSConfig tarayıcıdan erişime izin verilen kaynaklar
+type CORSConfig struct {
+	// Personel ve yönetim panelinin çalıştığı kaynaklar
+	AllowedOrigins []string `json:"allowed_origins"`
+	// Herkese açık puanlama endpoint'lerine erişebilen kaynaklar; "*" tüm kaynaklar
+	PublicOrigins []string `json:"public_origins"`
+	// Panel isteklerinde çerez/oturum bilgisi gönderilmesine izin verir ("*" ile birlikte kullanılamaz)
+	AllowCredentials bool `json:"allow_credentials"`
+	// Tarayıcının preflight yanıtını önbellekte tutacağı süre
+	MaxAge Duration `json:"max_age"`
+}
+
+// WebhookConfig giden webhook istekleri
+type WebhookConfig struct {
+	// Yerel ve özel ağdaki adreslere gönderime izin verir; sadece alıcı bilerek iç ağda çalışıyorsa açılmalı
+	AllowPrivateTargets bool `json:"allow_private_targets"`
+}
+
+// SMTPConfig e-posta kanalının SMTP sürücüsü
+type SMTPConfig struct {
+	Host     string `json:"host"`
+	Port     string `json:"port"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	From     string `json:"from"`
+}
+
+// SMSConfig SMS kanalının HTTP API sürücüsü
+type SMSConfig struct {
+	APIURL   string `json:"api_url"`
+	APIToken string `json:"api_token"`
+	Sender   string `json:"sender"`
+}
+
+// VAPIDConfig Web Push anahtarları; ikisi de boşsa anahtarlar veritabanında üretilir
+type VAPIDConfig struct {
+	PublicKey  string `json:"public_key"`
+	PrivateKey string `json:"private_key"`
+	Subject    string `json:"subject"` // mailto: veya https: adresi
+}
+
+// NotifyConfig bildirim kanallarının sürücüleri; "sink" bildirimleri dış servise göndermeden dosyaya/loga yazar
+type NotifyConfig struct {
+	EmailDriver string      `json:"email_driver"` // sink, smtp
+	SMSDriver   string      `json:"sms_driver"`   // sink, http
+	PushDriver  string      `json:"push_driver"`  // sink, webpush
+	SinkFile    string      `json:"sink_file"`    // Boşsa loga yazılır
+	SMTP        SMTPConfig  `json:"smtp"`
+	SMS         SMSConfig   `json:"sms"`
+	VAPID       VAPIDConfig `json:"vapid"`
+}
+
+// PDFConfig PDF çıktılarında kullanılacak UTF-8 TrueType fontlar; boşsa Helvetica kullanılır
+type PDFConfig struct {
+	FontPath     string `json:"font_path"`
+	FontBoldPath string `json:"font_bold_path"` // Boşsa normal font kalın yazı için de kullanılır
+}
+
+// ReportConfig zamanlanmış yönetim raporları
+type ReportConfig struct {
+	Dir               string   `json:"dir"`
+	Schedule          []string `json:"schedule"` // weekly, monthly; kapatmak için ["off"]
+	EmailTo           []string `json:"email_to"`
+	ResolveSLAMinutes int      `json:"resolve_sla_minutes"` // Problem bildiriminden temizliğin bitmesine kadar hedef süre
+}
+
+// Config uygulamanın başlangıçta yüklenen ve doğrulanan yapılandırması
+type Config struct {
+	Server            ServerConfig   `json:"server"`
+	Database          DatabaseConfig `json:"database"`
+	CORS              CORSConfig     `json:"cors"`
+	Webhooks          WebhookConfig  `json:"webhooks"`
+	Notify            NotifyConfig   `json:"notify"`
+	PDF               PDFConfig      `json:"pdf"`
+	Reports           ReportConfig   `json:"reports"`
+	PublicRatingURL   string         `json:"public_rating_url"` // QR kodlarının yönlendireceği puanlama sayfası
+	RatingTokenSecret string         `json:"rating_token_secret"`
+	SessionSecret     string         `json:"session_secret"`    // Personel oturum tokenlarını imzalar
+	FacilityTimezone  string         `json:"facility_timezone"` // Boşsa sunucunun saat dilimi
+}
+
+// config LoadConfig ile yüklenen yapılandırma
+var config = defaultConfig()
+
+// defaultConfig .env veya yapılandırma dosyası olmadan kullanılan varsayılanlar
+func defaultConfig() Config {
+	return Config{
+		Server: ServerConfig{
+			Port:            8080,
+			ReadTimeout:     Duration{15 * time.Second},
+			WriteTimeout:    Duration{2 * time.Minute},
+			IdleTimeout:     Duration{2 * time.Minute},
+			ShutdownTimeout: Duration{30 * time.Second},
+		},
+		Database: DatabaseConfig{
+			Driver:  DriverMySQL,
+			Host:    "localhost",
+			Name:    "temizlik_takip",
+			SSLMode: "disable",
+			Path:    "temizlik_takip.db",
+		},
+		CORS: CORSConfig{
+			AllowedOrigins:   []string{"http://localhost:5173"},
+			PublicOrigins:    []string{"*"},
+			AllowCredentials: true,
+			MaxAge:           Duration{10 * time.Minute},
+		},
+		Notify: NotifyConfig{
+			EmailDriver: "sink",
+			SMSDriver:   "sink",
+			PushDriver:  "sink",
+		},
+		Reports: ReportConfig{
+			Dir:               defaultReportDir,
+			Schedule:          []string{"weekly", "monthly"},
+			ResolveSLAMinutes: 60,
+		},
+		PublicRatingURL: defaultPublicRatingURL,
+	}
+}
+
+// LoadConfig yapılandırmayı sırasıyla varsayılanlar, yapılandırma dosyası, .env ve ortam değişkenlerinden yükler;
+// sonraki kaynak öncekini ezer. Geçersiz değerler tek hatada listelenir.
+func LoadConfig() error {
+	cfg := defaultConfig()
+
+	path := os.Getenv("CONFIG_FILE")
+	if err := loadConfigFile(&cfg, path); err != nil {
+		return err
+	}
+
+	// .env opsiyoneldir; konteynerlerde değerler doğrudan ortamdan gelir
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf(".env dosyası okunamadı: %w", err)
+	}
+
+	if err := applyEnv(&cfg); err != nil {
+		return err
+	}
+	if cfg.Database.Port == "" {
+		cfg.Database.Port = defaultDatabasePort(cfg.Database.Driver)
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	config = cfg
+	return nil
+}
+
+// loadConfigFile JSON yapılandırma dosyasını okur; path boşsa varsayılan dosya varsa okunur
+func loadConfigFile(cfg *Config, path string) error {
+	explicit := path != ""
+	if !explicit {
+		path = defaultConfigFile
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !explicit {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("yapılandırma dosyası okunamadı: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(cfg); err != nil {
+		return fmt.Errorf("yapılandırma dosyası (%s) geçersiz: %w", path, err)
+	}
+	return nil
+}
+
+// applyEnv boş olmayan ortam değişkenlerini yapılandırmaya uygular
+func applyEnv(cfg *Config) error {
+	var errs []error
+
+	setString := func(name string, target *string) {
+		if value := os.Getenv(name); value != "" {
+			*target = value
+		}
+	}
+	setList := func(name string, target *[]string) {
+		if value := os.Getenv(name); value != "" {
+			*target = splitList(value)
+		}
+	}
+	setBool := func(name string, target *bool) {
+		if value := os.Getenv(name); value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s true veya false olmalı: %q", name, value))
+				return
+			}
+			*target = parsed
+		}
+	}
+	setDuration := func(name string, target *Duration) {
+		if value := os.Getenv(name); value != "" {
+			parsed, err := time.ParseDuration(value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s geçersiz süre: %q (ör. 15s, 2m)", name, value))
+				return
+			}
+			target.Duration = parsed
+		}
+	}
+
+	if value := os.Getenv("PORT"); value != "" {
+		port, err := strconv.Atoi(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("PORT sayı olmalı: %q", value))
+		} else {
+			cfg.Server.Port = port
+		}
+	}
+	setDuration("HTTP_READ_TIMEOUT", &cfg.Server.ReadTimeout)
+	setDuration("HTTP_WRITE_TIMEOUT", &cfg.Server.WriteTimeout)
+	setDuration("HTTP_IDLE_TIMEOUT", &cfg.Server.IdleTimeout)
+	setDuration("SHUTDOWN_TIMEOUT", &cfg.Server.ShutdownTimeout)
+
+	setString("DB_DRIVER", &cfg.Database.Driver)
+	setString("DB_USER", &cfg.Database.User)
+	setString("DB_PASSWORD", &cfg.Database.Password)
+	setString("DB_HOST", &cfg.Database.Host)
+	setString("DB_PORT", &cfg.Database.Port)
+	setString("DB_NAME", &cfg.Database.Name)
+	setString("DB_SSLMODE", &cfg.Database.SSLMode)
+	setString("DB_PATH", &cfg.Database.Path)
+
+	setList("CORS_ALLOWED_ORIGINS", &cfg.CORS.AllowedOrigins)
+	setList("CORS_PUBLIC_ORIGINS", &cfg.CORS.PublicOrigins)
+	setBool("CORS_ALLOW_CREDENTIALS", &cfg.CORS.AllowCredentials)
+	setDuration("CORS_MAX_AGE", &cfg.CORS.MaxAge)
+
+	setBool("WEBHOOK_ALLOW_PRIVATE_TARGETS", &cfg.Webhooks.AllowPrivateTargets)
+
+	setString("NOTIFY_EMAIL_DRIVER", &cfg.Notify.EmailDriver)
+	setString("NOTIFY_SMS_DRIVER", &cfg.Notify.SMSDriver)
+	setString("NOTIFY_PUSH_DRIVER", &cfg.Notify.PushDriver)
+	setString("NOTIFY_SINK_FILE", &cfg.Notify.SinkFile)
+	setString("SMTP_HOST", &cfg.Notify.SMTP.Host)
+	setString("SMTP_PORT", &cfg.Notify.SMTP.Port)
+	setString("SMTP_USERNAME", &cfg.Notify.SMTP.Username)
+	setString("SMTP_PASSWORD", &cfg.Notify.SMTP.Password)
+	setString("SMTP_FROM", &cfg.Notify.SMTP.From)
+	setString("SMS_API_URL", &cfg.Notify.SMS.APIURL)
+	setString("SMS_API_TOKEN", &cfg.Notify.SMS.APIToken)
+	setString("SMS_SENDER", &cfg.Notify.SMS.Sender)
+	setString("VAPID_PUBLIC_KEY", &cfg.Notify.VAPID.PublicKey)
+	setString("VAPID_PRIVATE_KEY", &cfg.Notify.VAPID.PrivateKey)
+	setString("VAPID_SUBJECT", &cfg.Notify.VAPID.Subject)
+
+	setString("PDF_FONT_PATH", &cfg.PDF.FontPath)
+	setString("PDF_FONT_BOLD_PATH", &cfg.PDF.FontBoldPath)
+
+	setString("REPORT_DIR", &cfg.Reports.Dir)
+	setList("REPORT_SCHEDULE", &cfg.Reports.Schedule)
+	setList("REPORT_EMAIL_TO", &cfg.Reports.EmailTo)
+	if value := os.Getenv("RESOLVE_SLA_MINUTES"); value != "" {
+		minutes, err := strconv.Atoi(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("RESOLVE_SLA_MINUTES sayı olmalı: %q", value))
+		} else {
+			cfg.Reports.ResolveSLAMinutes = minutes
+		}
+	}
+
+	setString("PUBLIC_RATING_URL", &cfg.PublicRatingURL)
+
+	setString("RATING_TOKEN_SECRET", &cfg.RatingTokenSecret)
+	setString("SESSION_SECRET", &cfg.SessionSecret)
+	setString("FACILITY_TIMEZONE", &cfg.FacilityTimezone)
+
+	return errors.Join(errs...)
+}
+
+// defaultDatabasePort sürücünün varsayılan portunu döner
+func defaultDatabasePort(driver string) string {
+	if driver == DriverPostgres {
+		return "5432"
+	}
+	return "3306"
+}
+
+// splitList virgülle ayrılmış listeyi boşlukları temizleyerek böler
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Validate yapılandırmadaki tüm hataları toplayıp döner
+func (c Config) Validate() error {
+	var errs []error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		fail("PORT 1-65535 aralığında olmalı: %d", c.Server.Port)
+	}
+	timeouts := []struct {
+		name  string
+		value Duration
+	}{
+		{"HTTP_READ_TIMEOUT", c.Server.ReadTimeout},
+		{"HTTP_WRITE_TIMEOUT", c.Server.WriteTimeout},
+		{"HTTP_IDLE_TIMEOUT", c.Server.IdleTimeout},
+		{"SHUTDOWN_TIMEOUT", c.Server.ShutdownTimeout},
+	}
+	for _, timeout := range timeouts {
+		if timeout.value.Duration <= 0 {
+			fail("%s sıfırdan büyük olmalı", timeout.name)
+		}
+	}
+
+	switch c.Database.Driver {
+	case DriverMySQL, DriverPostgres:
+		if c.Database.Host == "" || c.Database.Name == "" || c.Database.User == "" {
+			fail("%s için DB_HOST, DB_NAME ve DB_USER gerekli", c.Database.Driver)
+		}
+		if _, err := strconv.Atoi(c.Database.Port); err != nil {
+			fail("DB_PORT sayı olmalı: %q", c.Database.Port)
+		}
+	case DriverSQLite:
+		if c.Database.Path == "" {
+			fail("sqlite için DB_PATH gerekli")
+		}
+	default:
+		fail("desteklenmeyen DB_DRIVER: %q (mysql, postgres veya sqlite olmalı)", c.Database.Driver)
+	}
+
+	origins := []struct {
+		name   string
+		values []string
+	}{
+		{"CORS_ALLOWED_ORIGINS", c.CORS.AllowedOrigins},
+		{"CORS_PUBLIC_ORIGINS", c.CORS.PublicOrigins},
+	}
+	for _, list := range origins {
+		for _, origin := range list.values {
+			if origin == "*" {
+				continue
+			}
+			u, err := url.Parse(origin)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || (u.Path != "" && u.Path != "/") {
+				fail("%s geçersiz kaynak: %q (ör. https://panel.example.com)", list.name, origin)
+			}
+		}
+	}
+	// Tarayıcılar kimlik bilgili isteklerde "*" yanıtını kabul etmez; açık bırakmak da oturumları her siteye açar
+	if c.CORS.AllowCredentials && slices.Contains(c.CORS.AllowedOrigins, "*") {
+		fail("CORS_ALLOW_CREDENTIALS açıkken CORS_ALLOWED_ORIGINS \"*\" olamaz, panel kaynaklarını tek tek yazın")
+	}
+	if c.CORS.MaxAge.Duration < 0 {
+		fail("CORS_MAX_AGE negatif olamaz")
+	}
+
+	c.Notify.validate(fail)
+	c.PDF.validate(fail)
+	c.Reports.validate(fail)
+
+	if !isHTTPURL(c.PublicRatingURL) {
+		fail("PUBLIC_RATING_URL http veya https adresi olmalı: %q", c.PublicRatingURL)
+	}
+
+	if len(c.RatingTokenSecret) < 16 {
+		fail("RATING_TOKEN_SECRET en az 16 karakter olmalı")
+	}
+	if len(c.SessionSecret) < 16 {
+		fail("SESSION_SECRET en az 16 karakter olmalı")
+	}
+
+	if c.FacilityTimezone != "" {
+		if _, err := time.LoadLocation(c.FacilityTimezone); err != nil {
+			fail("geçersiz FACILITY_TIMEZONE: %q", c.FacilityTimezone)
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("yapılandırma hatalı:\n%w", errors.Join(errs...))
+}
+
+// isHTTPURL değerin host içeren bir http veya https adresi olup olmadığını döner
+func isHTTPURL(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// isEmailAddress değerin tek bir geçerli e-posta adresi olup olmadığını döner
+func isEmailAddress(value string) bool {
+	addr, err := mail.ParseAddress(value)
+	return err == nil && addr.Address == value
+}
+
+// validate bildirim sürücülerini ve seçilen sürücünün gerektirdiği ayarları doğrular
+func (n NotifyConfig) validate(fail func(format string, args ...interface{})) {
+	drivers := []struct {
+		name    string
+		value   string
+		allowed []string
+	}{
+		{"NOTIFY_EMAIL_DRIVER", n.EmailDriver, []string{"sink", "smtp"}},
+		{"NOTIFY_SMS_DRIVER", n.SMSDriver, []string{"sink", "http"}},
+		{"NOTIFY_PUSH_DRIVER", n.PushDriver, []string{"sink", "webpush"}},
+	}
+	for _, driver := range drivers {
+		if !slices.Contains(driver.allowed, driver.value) {
+			fail("desteklenmeyen %s: %q (%s olmalı)", driver.name, driver.value, strings.Join(driver.allowed, " veya "))
+		}
+	}
+
+	if n.EmailDriver == "smtp" {
+		if n.SMTP.Host == "" {
+			fail("smtp e-posta sürücüsü için SMTP_HOST gerekli")
+		}
+		if port, err := strconv.Atoi(n.SMTP.Port); err != nil || port < 1 || port > 65535 {
+			fail("SMTP_PORT 1-65535 aralığında olmalı: %q", n.SMTP.Port)
+		}
+		if !isEmailAddress(n.SMTP.From) {
+			fail("SMTP_FROM geçerli bir e-posta adresi olmalı: %q", n.SMTP.From)
+		}
+		if n.SMTP.Password != "" && n.SMTP.Username == "" {
+			fail("SMTP_PASSWORD verildiğinde SMTP_USERNAME de gerekli")
+		}
+	}
+
+	if n.SMSDriver == "http" && !isHTTPURL(n.SMS.APIURL) {
+		fail("http SMS sürücüsü için SMS_API_URL http veya https adresi olmalı: %q", n.SMS.APIURL)
+	}
+
+	if (n.VAPID.PublicKey == "") != (n.VAPID.PrivateKey == "") {
+		fail("VAPID_PUBLIC_KEY ve VAPID_PRIVATE_KEY birlikte verilmeli ya da ikisi de boş bırakılmalı")
+	}
+	if n.PushDriver == "webpush" && !strings.HasPrefix(n.VAPID.Subject, "mailto:") && !strings.HasPrefix(n.VAPID.Subject, "https://") {
+		fail("webpush sürücüsü için VAPID_SUBJECT mailto: veya https:// ile başlamalı: %q", n.VAPID.Subject)
+	}
+}
+
+// validate font dosyalarının okunabilir olduğunu kontrol eder
+func (p PDFConfig) validate(fail func(format string, args ...interface{})) {
+	if p.FontBoldPath != "" && p.FontPath == "" {
+		fail("PDF_FONT_BOLD_PATH sadece PDF_FONT_PATH ile birlikte kullanılabilir")
+	}
+	fonts := []struct {
+		name string
+		path string
+	}{
+		{"PDF_FONT_PATH", p.FontPath},
+		{"PDF_FONT_BOLD_PATH", p.FontBoldPath},
+	}
+	for _, font := range fonts {
+		if font.path == "" {
+			continue
+		}
+		if info, err := os.Stat(font.path); err != nil || info.IsDir() {
+			fail("%s okunamadı: %q", font.name, font.path)
+		}
+	}
+}
+
+// validate rapor klasörünü, dönemleri, alıcıları ve hedef süreyi doğrular
+func (r ReportConfig) validate(fail func(format string, args ...interface{})) {
+	if r.Dir == "" {
+		fail("REPORT_DIR boş olamaz")
+	}
+
+	if !(len(r.Schedule) == 1 && r.Schedule[0] == "off") {
+		for _, period := range r.Schedule {
+			if _, ok := reportPeriodNames[period]; !ok {
+				fail("REPORT_SCHEDULE geçersiz dönem: %q (weekly, monthly veya sadece off)", period)
+			}
+		}
+	}
+
+	for _, recipient := range r.EmailTo {
+		if !isEmailAddress(recipient) {
+			fail("REPORT_EMAIL_TO geçersiz adres: %q", recipient)
+		}
+	}
+
+	if r.ResolveSLAMinutes < 1 {
+		fail("RESOLVE_SLA_MINUTES en az 1 olmalı: %d", r.ResolveSLAMinutes)
+	}
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// configEnvVars LoadConfig'in okuduğu ortam değişkenleri
+var configEnvVars = []string{
+	"CONFIG_FILE", "PORT", "HTTP_READ_TIMEOUT", "HTTP_WRITE_TIMEOUT", "HTTP_IDLE_TIMEOUT", "SHUTDOWN_TIMEOUT",
+	"DB_DRIVER", "DB_USER", "DB_PASSWORD", "DB_HOST", "DB_PORT", "DB_NAME", "DB_SSLMODE", "DB_PATH",
+	"CORS_ALLOWED_ORIGINS", "CORS_PUBLIC_ORIGINS", "CORS_ALLOW_CREDENTIALS", "CORS_MAX_AGE", "RATING_TOKEN_SECRET", "SESSION_SECRET", "FACILITY_TIMEZONE",
+	"WEBHOOK_ALLOW_PRIVATE_TARGETS", "PUBLIC_RATING_URL", "PDF_FONT_PATH", "PDF_FONT_BOLD_PATH",
+	"NOTIFY_EMAIL_DRIVER", "NOTIFY_SMS_DRIVER", "NOTIFY_PUSH_DRIVER", "NOTIFY_SINK_FILE",
+	"SMTP_HOST", "SMTP_PORT", "SMTP_USERNAME", "SMTP_PASSWORD", "SMTP_FROM", "SMS_API_URL", "SMS_API_TOKEN", "SMS_SENDER",
+	"VAPID_PUBLIC_KEY", "VAPID_PRIVATE_KEY", "VAPID_SUBJECT",
+	"REPORT_DIR", "REPORT_SCHEDULE", "REPORT_EMAIL_TO", "RESOLVE_SLA_MINUTES",
+}
+
+// isolateConfig testi .env ve config.json olmayan boş bir dizinde, yapılandırma değişkenleri tanımsız olarak çalıştırır
+func isolateConfig(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	t.Chdir(dir)
+	for _, name := range configEnvVars {
+		t.Setenv(name, "") // Test sonunda eski değer geri yüklenir
+		os.Unsetenv(name)
+	}
+
+	previous := config
+	t.Cleanup(func() { config = previous })
+	return dir
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Dosya yazılamadı: %v", err)
+	}
+}
+
+func TestLoadConfigFromEnvironmentOnly(t *testing.T) {
+	isolateConfig(t)
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DB_PATH", "veri.db")
+	t.Setenv("RATING_TOKEN_SECRET", "0123456789abcdef")
+	t.Setenv("SESSION_SECRET", "fedcba9876543210")
+	t.Setenv("HTTP_WRITE_TIMEOUT", "5m")
+
+	if err := LoadConfig(); err != nil {
+		t.Fatalf(".env olmadan yapılandırma yüklenemedi: %v", err)
+	}
+	if config.Server.Port != 8080 || config.Server.ReadTimeout.Duration != 15*time.Second || config.Server.WriteTimeout.Duration != 5*time.Minute {
+		t.Fatalf("Beklenmeyen sunucu ayarları: %+v", config.Server)
+	}
+	if config.Database.Driver != DriverSQLite || config.Database.Path != "veri.db" {
+		t.Fatalf("Beklenmeyen veritabanı ayarları: %+v", config.Database)
+	}
+	if strings.Join(config.CORS.AllowedOrigins, " ") != "http://localhost:5173" || strings.Join(config.CORS.PublicOrigins, " ") != "*" || !config.CORS.AllowCredentials {
+		t.Fatalf("Varsayılan CORS ayarları: %+v", config.CORS)
+	}
+}
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	dir := isolateConfig(t)
+	writeFile(t, filepath.Join(dir, "config.json"), `{
+		"server": {"port": 9000, "read_timeout": "30s"},
+		"database": {"driver": "postgres", "user": "dosya", "host": "db", "name": "temizlik"},
+		"cors": {"allowed_origins": ["https://panel.example.com"]},
+		"rating_token_secret": "dosyadaki-gizli-anahtar",
+		"session_secret": "dosyadaki-oturum-anahtari",
+		"facility_timezone": "Europe/Berlin"
+	}`)
+	writeFile(t, filepath.Join(dir, ".env"), "DB_USER=dotenv\nPORT=9100\n")
+	t.Setenv("PORT", "9200")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://a.example.com, https://b.example.com")
+
+	if err := LoadConfig(); err != nil {
+		t.Fatalf("Yapılandırma yüklenemedi: %v", err)
+	}
+
+	// Ortam değişkeni .env'i, .env dosyayı, dosya varsayılanları ezer
+	if config.Server.Port != 9200 {
+		t.Fatalf("Port %d, beklenen 9200", config.Server.Port)
+	}
+	if config.Database.User != "dotenv" {
+		t.Fatalf("DB kullanıcısı %q, beklenen dotenv", config.Database.User)
+	}
+	if config.Server.ReadTimeout.Duration != 30*time.Second || config.Server.IdleTimeout.Duration != 2*time.Minute {
+		t.Fatalf("Beklenmeyen zaman aşımları: %+v", config.Server)
+	}
+	if config.Database.Port != "5432" {
+		t.Fatalf("PostgreSQL varsayılan portu %q, beklenen 5432", config.Database.Port)
+	}
+	if got := strings.Join(config.CORS.AllowedOrigins, " "); got != "https://a.example.com https://b.example.com" {
+		t.Fatalf("CORS kaynakları %q", got)
+	}
+	if config.FacilityTimezone != "Europe/Berlin" {
+		t.Fatalf("Saat dilimi %q", config.FacilityTimezone)
+	}
+}
+
+func TestLoadConfigReportsAllErrors(t *testing.T) {
+	isolateConfig(t)
+	t.Setenv("PORT", "70000")
+	t.Setenv("HTTP_READ_TIMEOUT", "on beş saniye")
+	t.Setenv("DB_DRIVER", "oracle")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "panel.example.com")
+	t.Setenv("RATING_TOKEN_SECRET", "kisa")
+	t.Setenv("SESSION_SECRET", "kisa")
+	t.Setenv("FACILITY_TIMEZONE", "Mars/Olympus")
+
+	err := LoadConfig()
+	if err == nil {
+		t.Fatal("Hatalı yapılandırma kabul edildi")
+	}
+	// Süre hatası ayrıştırma sırasında döner, diğerleri doğrulamada birlikte listelenir
+	if !strings.Contains(err.Error(), "HTTP_READ_TIMEOUT") {
+		t.Fatalf("Süre hatası bildirilmedi: %v", err)
+	}
+
+	t.Setenv("HTTP_READ_TIMEOUT", "")
+	err = LoadConfig()
+	if err == nil {
+		t.Fatal("Hatalı yapılandırma kabul edildi")
+	}
+	for _, want := range []string{"PORT", "DB_DRIVER", "CORS_ALLOWED_ORIGINS", "RATING_TOKEN_SECRET", "SESSION_SECRET", "FACILITY_TIMEZONE"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Hata mesajında %s yok: %v", want, err)
+		}
+	}
+	if config.Database.Driver == "oracle" {
+		t.Fatal("Geçersiz yapılandırma uygulandı")
+	}
+}
+
+func TestLoadConfigFileErrors(t *testing.T) {
+	dir := isolateConfig(t)
+	t.Setenv("RATING_TOKEN_SECRET", "0123456789abcdef")
+	t.Setenv("SESSION_SECRET", "fedcba9876543210")
+
+	t.Setenv("CONFIG_FILE", filepath.Join(dir, "yok.json"))
+	if err := LoadConfig(); err == nil {
+		t.Fatal("Açıkça verilen ama olmayan yapılandırma dosyası kabul edildi")
+	}
+
+	path := filepath.Join(dir, "hatali.json")
+	writeFile(t, path, `{"server": {"prot": 9000}}`)
+	t.Setenv("CONFIG_FILE", path)
+	if err := LoadConfig(); err == nil || !strings.Contains(err.Error(), "prot") {
+		t.Fatalf("Bilinmeyen alan bildirilmedi: %v", err)
+	}
+
+	writeFile(t, path, `{"server": {"read_timeout": 15}}`)
+	if err := LoadConfig(); err == nil {
+		t.Fatal("Birimsiz süre kabul edildi")
+	}
+}
+
+func TestLoadConfigNotifyPDFAndReportSettings(t *testing.T) {
+	dir := isolateConfig(t)
+	font := filepath.Join(dir, "font.ttf")
+	writeFile(t, font, "font")
+	writeFile(t, filepath.Join(dir, "config.json"), `{
+		"notify": {"email_driver": "smtp", "smtp": {"host": "mail", "port": "2525", "from": "temizlik@example.com"}},
+		"reports": {"schedule": ["monthly"], "resolve_sla_minutes": 45}
+	}`)
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("RATING_TOKEN_SECRET", "0123456789abcdef")
+	t.Setenv("SESSION_SECRET", "fedcba9876543210")
+	t.Setenv("NOTIFY_SMS_DRIVER", "http")
+	t.Setenv("SMS_API_URL", "https://sms.example.com/send")
+	t.Setenv("PDF_FONT_PATH", font)
+	t.Setenv("REPORT_EMAIL_TO", "a@example.com, b@example.com")
+	t.Setenv("PUBLIC_RATING_URL", "https://puan.example.com/rating")
+
+	if err := LoadConfig(); err != nil {
+		t.Fatalf("Yapılandırma yüklenemedi: %v", err)
+	}
+	if config.Notify.EmailDriver != "smtp" || config.Notify.SMTP.Port != "2525" || config.Notify.SMSDriver != "http" || config.Notify.PushDriver != "sink" {
+		t.Fatalf("Beklenmeyen bildirim ayarları: %+v", config.Notify)
+	}
+	if config.PDF.FontPath != font || config.PublicRatingURL != "https://puan.example.com/rating" {
+		t.Fatalf("Beklenmeyen PDF/QR ayarları: %+v %q", config.PDF, config.PublicRatingURL)
+	}
+	if strings.Join(config.Reports.Schedule, " ") != "monthly" || strings.Join(config.Reports.EmailTo, " ") != "a@example.com b@example.com" ||
+		config.Reports.ResolveSLAMinutes != 45 || config.Reports.Dir != defaultReportDir {
+		t.Fatalf("Beklenmeyen rapor ayarları: %+v", config.Reports)
+	}
+
+	InitReports()
+	t.Cleanup(func() { reportDir, reportPeriods, reportRecipients, resolveSLA = defaultReportDir, nil, nil, time.Hour })
+	if strings.Join(reportPeriods, " ") != "monthly" || len(reportRecipients) != 2 || resolveSLA != 45*time.Minute {
+		t.Fatalf("Rapor ayarları uygulanmadı: %v %v %s", reportPeriods, reportRecipients, resolveSLA)
+	}
+
+	// off zamanlanmış raporları kapatır
+	t.Setenv("REPORT_SCHEDULE", "off")
+	if err := LoadConfig(); err != nil {
+		t.Fatalf("Yapılandırma yüklenemedi: %v", err)
+	}
+	InitReports()
+	if len(reportPeriods) != 0 {
+		t.Fatalf("Zamanlanmış raporlar kapanmadı: %v", reportPeriods)
+	}
+}
+
+func TestLoadConfigRejectsBadNotifyPDFAndReportSettings(t *testing.T) {
+	dir := isolateConfig(t)
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("RATING_TOKEN_SECRET", "0123456789abcdef")
+	t.Setenv("SESSION_SECRET", "fedcba9876543210")
+	if err := LoadConfig(); err != nil {
+		t.Fatalf("Varsayılan ayarlar reddedildi: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{"bilinmeyen e-posta sürücüsü", map[string]string{"NOTIFY_EMAIL_DRIVER": "sendgrid"}, "NOTIFY_EMAIL_DRIVER"},
+		{"smtp portu", map[string]string{"NOTIFY_EMAIL_DRIVER": "smtp", "SMTP_HOST": "mail", "SMTP_PORT": "x", "SMTP_FROM": "a@example.com"}, "SMTP_PORT"},
+		{"smtp gönderen", map[string]string{"NOTIFY_EMAIL_DRIVER": "smtp", "SMTP_HOST": "mail", "SMTP_PORT": "25", "SMTP_FROM": "temizlik"}, "SMTP_FROM"},
+		{"smtp sunucusu", map[string]string{"NOTIFY_EMAIL_DRIVER": "smtp", "SMTP_PORT": "25", "SMTP_FROM": "a@example.com"}, "SMTP_HOST"},
+		{"sms adresi", map[string]string{"NOTIFY_SMS_DRIVER": "http", "SMS_API_URL": "sms.example.com"}, "SMS_API_URL"},
+		{"bilinmeyen push sürücüsü", map[string]string{"NOTIFY_PUSH_DRIVER": "fcm"}, "NOTIFY_PUSH_DRIVER"},
+		{"vapid konusu", map[string]string{"NOTIFY_PUSH_DRIVER": "webpush", "VAPID_SUBJECT": "temizlik@example.com"}, "VAPID_SUBJECT"},
+		{"tek vapid anahtarı", map[string]string{"VAPID_PUBLIC_KEY": "abc"}, "VAPID_PRIVATE_KEY"},
+		{"olmayan font", map[string]string{"PDF_FONT_PATH": filepath.Join(dir, "yok.ttf")}, "PDF_FONT_PATH"},
+		{"yalnız kalın font", map[string]string{"PDF_FONT_BOLD_PATH": filepath.Join(dir, "yok.ttf")}, "PDF_FONT_BOLD_PATH"},
+		{"rapor dönemi", map[string]string{"REPORT_SCHEDULE": "weekly,daily"}, "REPORT_SCHEDULE"},
+		{"off ile dönem", map[string]string{"REPORT_SCHEDULE": "off,weekly"}, "REPORT_SCHEDULE"},
+		{"rapor alıcısı", map[string]string{"REPORT_EMAIL_TO": "yonetim"}, "REPORT_EMAIL_TO"},
+		{"sayı olmayan SLA", map[string]string{"RESOLVE_SLA_MINUTES": "bir saat"}, "RESOLVE_SLA_MINUTES"},
+		{"sıfır SLA", map[string]string{"RESOLVE_SLA_MINUTES": "0"}, "RESOLVE_SLA_MINUTES"},
+		{"puanlama adresi", map[string]string{"PUBLIC_RATING_URL": "/rating"}, "PUBLIC_RATING_URL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			err := LoadConfig()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("%s hatası bekleniyordu: %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestConfigExampleIsValid(t *testing.T) {
+	example, err := filepath.Abs("config.example.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	isolateConfig(t)
+	t.Setenv("CONFIG_FILE", example)
+
+	if err := LoadConfig(); err != nil {
+		t.Fatalf("Örnek yapılandırma geçersiz: %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// corsPolicy bir route grubunun hangi kaynaklardan, hangi başlıklarla çağrılabileceğini belirler
+type corsPolicy struct {
+	origins          []string // "*" tüm kaynaklar
+	allowCredentials bool
+	allowHeaders     string
+}
+
+// publicCORSPolicy QR kodunu okutan her cihazın kullandığı puanlama endpoint'leri için politika
+func publicCORSPolicy() corsPolicy {
+	return corsPolicy{
+		origins:      config.CORS.PublicOrigins,
+		allowHeaders: "Accept, Content-Type",
+	}
+}
+
+// staffCORSPolicy personel ve yönetim endpoint'leri için sadece izinli panel kaynaklarına açık politika
+func staffCORSPolicy() corsPolicy {
+	return corsPolicy{
+		origins:          config.CORS.AllowedOrigins,
+		allowCredentials: config.CORS.AllowCredentials,
+		allowHeaders:     "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-User-ID, X-User-Name",
+	}
+}
+
+// allowOrigin isteğin kaynağına izin veriliyorsa Access-Control-Allow-Origin değerini, verilmiyorsa boş döner
+func (p corsPolicy) allowOrigin(origin string) string {
+	if origin == "" {
+		return ""
+	}
+	for _, allowed := range p.origins {
+		if allowed == "*" {
+			return "*"
+		}
+		if strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return origin
+		}
+	}
+	return ""
+}
+
+// corsGroup aynı CORS politikasını paylaşan route grubu; kaydedilen her yol için preflight (OPTIONS) route'u da eklenir
+type corsGroup struct {
+	group   *gin.RouterGroup
+	policy  corsPolicy
+	methods map[string][]string // tam yol -> izin verilen metotlar
+}
+
+// newCORSGroup parent altında politikayı uygulayan bir route grubu oluşturur
+func newCORSGroup(parent *gin.RouterGroup, policy corsPolicy) *corsGroup {
+	g := &corsGroup{policy: policy, methods: make(map[string][]string)}
+	g.group = parent.Group("", g.handleCORS)
+	return g
+}
+
+func (g *corsGroup) GET(path string, handlers ...gin.HandlerFunc) {
+	g.handle(http.MethodGet, path, handlers)
+}
+
+func (g *corsGroup) POST(path string, handlers ...gin.HandlerFunc) {
+	g.handle(http.MethodPost, path, handlers)
+}
+
+func (g *corsGroup) PUT(path string, handlers ...gin.HandlerFunc) {
+	g.handle(http.MethodPut, path, handlers)
+}
+
+func (g *corsGroup) DELETE(path string, handlers ...gin.HandlerFunc) {
+	g.handle(http.MethodDelete, path, handlers)
+}
+
+// handle route'u kaydeder; yol ilk kez görülüyorsa preflight isteklerini yanıtlayan OPTIONS route'unu da ekler
+func (g *corsGroup) handle(method, path string, handlers []gin.HandlerFunc) {
+	g.group.Handle(method, path, handlers...)
+
+	fullPath := g.group.BasePath() + path
+	if _, ok := g.methods[fullPath]; !ok {
+		// Yanıtı handleCORS verir
+		g.group.OPTIONS(path, func(c *gin.Context) {})
+	}
+	g.methods[fullPath] = append(g.methods[fullPath], method)
+}
+
+// handleCORS izinli kaynaklara CORS başlıklarını ekler ve preflight isteklerini yanıtlar
+func (g *corsGroup) handleCORS(c *gin.Context) {
+	origin := g.policy.allowOrigin(c.GetHeader("Origin"))
+
+	c.Writer.Header().Add("Vary", "Origin")
+	if origin != "" {
+		c.Header("Access-Control-Allow-Origin", origin)
+		if g.policy.allowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+	}
+
+	if c.Request.Method != http.MethodOptions {
+		c.Next()
+		return
+	}
+
+	// İzin verilmeyen kaynaktan gelen preflight reddedilir; tarayıcı asıl isteği göndermez
+	if c.GetHeader("Origin") != "" && origin == "" {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	methods := append([]string{http.MethodOptions}, g.methods[c.FullPath()]...)
+	c.Header("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+	c.Header("Access-Control-Allow-Headers", g.policy.allowHeaders)
+	if maxAge := config.CORS.MaxAge.Duration; maxAge > 0 {
+		c.Header("Access-Control-Max-Age", strconv.Itoa(int(maxAge.Seconds())))
+	}
+	c.AbortWithStatus(http.StatusNoContent)
+}
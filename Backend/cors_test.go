@@ -0,0 +1,109 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setCORS test süresince CORS ayarlarını değiştirir; route'lar politikayı kurulumda okuduğu için sunucudan önce çağrılmalı
+func setCORS(t *testing.T, cors CORSConfig) {
+	t.Helper()
+	previous := config.CORS
+	config.CORS = cors
+	t.Cleanup(func() { config.CORS = previous })
+}
+
+// preflight tarayıcının preflight isteğinde gönderdiği başlıkları döner
+func preflight(origin, method string) map[string]string {
+	return map[string]string{
+		"Origin":                        origin,
+		"Access-Control-Request-Method": method,
+	}
+}
+
+func TestCORSPolicies(t *testing.T) {
+	setCORS(t, CORSConfig{
+		AllowedOrigins:   []string{"https://panel.example.com"},
+		PublicOrigins:    []string{"*"},
+		AllowCredentials: true,
+		MaxAge:           Duration{5 * time.Minute},
+	})
+	s := newTestServer(t)
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		origin      string
+		status      int
+		allowOrigin string
+		credentials bool
+	}{
+		{"puanlama her kaynaktan", http.MethodOptions, "/api/rating", "https://baska.example.org", http.StatusNoContent, "*", false},
+		{"token çözümleme her kaynaktan", http.MethodOptions, "/api/rating-token/abc", "https://baska.example.org", http.StatusNoContent, "*", false},
+		{"panel kaynağı yönetim route'una", http.MethodOptions, "/api/admin/users", "https://panel.example.com", http.StatusNoContent, "https://panel.example.com", true},
+		{"yabancı kaynak yönetim route'una", http.MethodOptions, "/api/admin/users/5", "https://baska.example.org", http.StatusForbidden, "", false},
+		{"yabancı kaynak girişe", http.MethodOptions, "/api/login", "https://baska.example.org", http.StatusForbidden, "", false},
+		{"panel kaynağından asıl istek", http.MethodGet, "/api/toilets/status", "https://panel.example.com", http.StatusOK, "https://panel.example.com", true},
+		{"yabancı kaynaktan asıl istek başlıksız", http.MethodGet, "/api/toilets/status", "https://baska.example.org", http.StatusOK, "", false},
+		{"herkese açık asıl istek", http.MethodGet, "/api/toilets", "https://baska.example.org", http.StatusOK, "*", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := s.request(tt.method, tt.path, nil, preflight(tt.origin, http.MethodPost))
+			expectStatus(t, rec, tt.status)
+
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.allowOrigin {
+				t.Fatalf("Access-Control-Allow-Origin %q, beklenen %q", got, tt.allowOrigin)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Credentials") == "true"; got != tt.credentials {
+				t.Fatalf("Access-Control-Allow-Credentials %v, beklenen %v", got, tt.credentials)
+			}
+			if !slices.Contains(rec.Header().Values("Vary"), "Origin") {
+				t.Fatal("Vary: Origin başlığı eksik")
+			}
+		})
+	}
+
+	// Preflight yanıtı sadece o yolda tanımlı metotları ve grubun başlıklarını listeler
+	rec := s.request(http.MethodOptions, "/api/admin/users/5", nil, preflight("https://panel.example.com", http.MethodPut))
+	expectStatus(t, rec, http.StatusNoContent)
+	if got := rec.Header().Get("Access-Control-Allow-Methods"); got != "OPTIONS, PUT, DELETE" {
+		t.Fatalf("Access-Control-Allow-Methods %q", got)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Headers"); !strings.Contains(got, "Authorization") {
+		t.Fatalf("Yönetim route'u Authorization başlığına izin vermiyor: %q", got)
+	}
+	if got := rec.Header().Get("Access-Control-Max-Age"); got != "300" {
+		t.Fatalf("Access-Control-Max-Age %q, beklenen 300", got)
+	}
+
+	rec = s.request(http.MethodOptions, "/api/rating", nil, preflight("https://baska.example.org", http.MethodPost))
+	if got := rec.Header().Get("Access-Control-Allow-Headers"); strings.Contains(got, "Authorization") {
+		t.Fatalf("Herkese açık route Authorization başlığına izin veriyor: %q", got)
+	}
+}
+
+func TestCORSCredentialsRequireExplicitOrigins(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Database = DatabaseConfig{Driver: DriverSQLite, Path: "test.db"}
+	cfg.RatingTokenSecret = "0123456789abcdef"
+	cfg.SessionSecret = "fedcba9876543210"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Varsayılan CORS ayarları geçersiz: %v", err)
+	}
+
+	cfg.CORS.AllowedOrigins = []string{"*"}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "CORS_ALLOW_CREDENTIALS") {
+		t.Fatalf("Kimlik bilgili \"*\" kabul edildi: %v", err)
+	}
+
+	cfg.CORS.AllowCredentials = false
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Kimlik bilgisiz \"*\" reddedildi: %v", err)
+	}
+}
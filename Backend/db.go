@@ -3,43 +3,86 @@ package main
 import (
 	"fmt"
 	"log"
-	"os"
 
-	"github.com/joho/godotenv"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
 var DB *gorm.DB
 
-// InitDatabase MySQL veritabanı bağlantısını başlatır
+// Desteklenen veritabanı sürücüleri (DB_DRIVER)
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// dbDriver kullanımdaki veritabanı sürücüsü
+var dbDriver = DriverMySQL
+
+// defaultBuilding bina bilgisi girilmemiş tuvaletlerin bağlı olduğu bina; migration 9'un varsayılanıyla aynıdır
+const defaultBuilding = "Ana Bina"
+
+// InitDatabase yapılandırmada seçilen veritabanı bağlantısını başlatır
 func InitDatabase() {
-	// .env dosyasını yükle
-	err := godotenv.Load()
+	dbDriver = config.Database.Driver
+
+	dialector, err := databaseDialector(config.Database)
 	if err != nil {
-		log.Fatal(".env dosyası yüklenemedi: ", err)
+		log.Fatal("Veritabanı yapılandırması hatalı: ", err)
 	}
 
-	// Environment variables'dan bağlantı bilgilerini al
-	dbUser := os.Getenv("DB_USER")
-	dbPassword := os.Getenv("DB_PASSWORD")
-	dbHost := os.Getenv("DB_HOST")
-	dbPort := os.Getenv("DB_PORT")
-	dbName := os.Getenv("DB_NAME")
-
-	// MySQL bağlantı string'i oluştur
-	// Format: username:password@tcp(host:port)/dbname?charset=utf8mb4&parseTime=True&loc=Local
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local&collation=utf8mb4_unicode_ci&interpolateParams=true",
-		dbUser, dbPassword, dbHost, dbPort, dbName)
-
-	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{
+	DB, err = gorm.Open(dialector, &gorm.Config{
 		DisableForeignKeyConstraintWhenMigrating: true,
 	})
 	if err != nil {
 		log.Fatal("Veritabanı bağlantısı başarısız: ", err)
 	}
 
-	// Veritabanı charset'ini ayarla
+	if dbDriver == DriverMySQL {
+		setMySQLCharset()
+	}
+
+	log.Println("Veritabanı bağlantısı başarılı!")
+}
+
+// PrepareDatabase şemanın güncel olduğunu doğrular ve başlangıç verilerini hazırlar.
+// Tablolar burada oluşturulmaz; şema "go run . migrate" ile kurulur.
+func PrepareDatabase() {
+	requireMigratedSchema()
+
+	// İlk tuvaletleri oluştur (sadece bir kez)
+	createInitialToilets()
+	syncToiletSequence()
+
+	// Puanlama özetleri hiç oluşturulmamışsa geçmişten hesapla
+	ensureRatingSummaries()
+}
+
+// databaseDialector sürücüye göre bağlantı bilgilerinden GORM dialector'ünü döner
+func databaseDialector(cfg DatabaseConfig) (gorm.Dialector, error) {
+	switch cfg.Driver {
+	case DriverMySQL:
+		// Format: username:password@tcp(host:port)/dbname?charset=utf8mb4&parseTime=True&loc=Local
+		dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local&collation=utf8mb4_unicode_ci&interpolateParams=true",
+			cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.Name)
+		return mysql.Open(dsn), nil
+
+	case DriverPostgres:
+		dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+			cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name, cfg.SSLMode)
+		return postgres.Open(dsn), nil
+
+	case DriverSQLite:
+		return openSQLite(cfg.Path)
+	}
+
+	return nil, fmt.Errorf("desteklenmeyen DB_DRIVER: %s (mysql, postgres veya sqlite olmalı)", cfg.Driver)
+}
+
+// setMySQLCharset bağlantının karakter setini utf8mb4 olarak ayarlar
+func setMySQLCharset() {
 	charsetCommands := []string{
 		"SET NAMES utf8mb4 COLLATE utf8mb4_unicode_ci",
 		"SET character_set_connection=utf8mb4",
@@ -55,20 +98,17 @@ func InitDatabase() {
 			log.Printf("Charset ayarı hatası (%s): %v", cmd, err)
 		}
 	}
+}
 
-	// Veritabanı tablolarını otomatik oluştur
-	err = DB.AutoMigrate(&User{}, &Rating{}, &Toilet{}, &CleaningTask{})
-	if err != nil {
-		log.Fatal("Tablo oluşturma hatası: ", err)
+// syncToiletSequence sabit ID ile eklenen tuvaletlerden sonra PostgreSQL ID sırasını ileri alır
+func syncToiletSequence() {
+	if dbDriver != DriverPostgres {
+		return
 	}
 
-	// CleaningTask tablosunu sadece yoksa oluştur (charset sorunu için)
-	createCleaningTaskTableIfNotExists()
-
-	// İlk tuvaletleri oluştur (sadece bir kez)
-	createInitialToilets()
-
-	log.Println("Veritabanı bağlantısı başarılı!")
+	if err := DB.Exec("SELECT setval(pg_get_serial_sequence('toilets', 'id'), COALESCE((SELECT MAX(id) FROM toilets), 1))").Error; err != nil {
+		log.Printf("Tuvalet ID sırası güncellenemedi: %v", err)
+	}
 }
 
 // GetDB veritabanı bağlantısını döndürür
@@ -85,12 +125,12 @@ func createInitialToilets() {
 	// Eğer hiç tuvalet yoksa, 6 tane oluştur
 	if count == 0 {
 		toilets := []Toilet{
-			{ID: 1, Name: "1. Kat Erkek Tuvaleti", Location: "1. Kat", IsActive: true},
-			{ID: 2, Name: "1. Kat Kadın Tuvaleti", Location: "1. Kat", IsActive: true},
-			{ID: 3, Name: "2. Kat Erkek Tuvaleti", Location: "2. Kat", IsActive: true},
-			{ID: 4, Name: "2. Kat Kadın Tuvaleti", Location: "2. Kat", IsActive: true},
-			{ID: 5, Name: "3. Kat Erkek Tuvaleti", Location: "3. Kat", IsActive: true},
-			{ID: 6, Name: "3. Kat Kadın Tuvaleti", Location: "3. Kat", IsActive: true},
+			{ID: 1, Name: "1. Kat Erkek Tuvaleti", Location: "1. Kat", Building: defaultBuilding, IsActive: true},
+			{ID: 2, Name: "1. Kat Kadın Tuvaleti", Location: "1. Kat", Building: defaultBuilding, IsActive: true},
+			{ID: 3, Name: "2. Kat Erkek Tuvaleti", Location: "2. Kat", Building: defaultBuilding, IsActive: true},
+			{ID: 4, Name: "2. Kat Kadın Tuvaleti", Location: "2. Kat", Building: defaultBuilding, IsActive: true},
+			{ID: 5, Name: "3. Kat Erkek Tuvaleti", Location: "3. Kat", Building: defaultBuilding, IsActive: true},
+			{ID: 6, Name: "3. Kat Kadın Tuvaleti", Location: "3. Kat", Building: defaultBuilding, IsActive: true},
 		}
 
 		for _, toilet := range toilets {
@@ -102,38 +142,3 @@ func createInitialToilets() {
 		log.Println("İlk 6 tuvalet başarıyla oluşturuldu!")
 	}
 }
-
-// createCleaningTaskTableIfNotExists CleaningTask tablosunu sadece yoksa oluşturur
-func createCleaningTaskTableIfNotExists() {
-	// Mevcut tabloyu kontrol et
-	var tableExists bool
-	DB.Raw("SELECT COUNT(*) > 0 FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = 'cleaning_tasks'").Scan(&tableExists)
-
-	if !tableExists {
-		log.Println("CleaningTask tablosu bulunamadı, oluşturuluyor...")
-
-		// Tabloyu doğru charset ve collation ile oluştur
-		createTableSQL := `
-		CREATE TABLE cleaning_tasks (
-			id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
-			toilet_id INT NOT NULL,
-			cleaner_id INT UNSIGNED NOT NULL,
-			cleaner_name VARCHAR(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
-			status VARCHAR(50) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci DEFAULT 'assigned',
-			started_at TIMESTAMP NULL,
-			completed_at TIMESTAMP NULL,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-			INDEX idx_toilet_status (toilet_id, status)
-		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;`
-
-		result := DB.Exec(createTableSQL)
-		if result.Error != nil {
-			log.Printf("CleaningTask tablosu oluşturma hatası: %v", result.Error)
-		} else {
-			log.Println("CleaningTask tablosu başarıyla UTF8MB4 ile oluşturuldu!")
-		}
-	} else {
-		log.Println("CleaningTask tablosu zaten mevcut, korunuyor...")
-	}
-}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+// ErrorCode istemcinin hatayı mesaj metninden bağımsız tanıyabilmesi için kararlı hata kodu
+type ErrorCode string
+
+// Hata kodları; yayınlandıktan sonra değiştirilmemeli, istemciler bu değerlere göre davranır
+const (
+	CodeInvalidRequest           ErrorCode = "invalid_request"
+	CodeValidationFailed         ErrorCode = "validation_failed"
+	CodeInvalidCredentials       ErrorCode = "invalid_credentials"
+	CodeUnauthorized             ErrorCode = "unauthorized"
+	CodeForbidden                ErrorCode = "forbidden"
+	CodeRatingTokenRequired      ErrorCode = "rating_token_required"
+	CodeRatingTokenInvalid       ErrorCode = "rating_token_invalid"
+	CodeRatingTokenRevoked       ErrorCode = "rating_token_revoked"
+	CodeRatingScanRequired       ErrorCode = "rating_scan_required"
+	CodeRatingNotFound           ErrorCode = "rating_not_found"
+	CodeToiletNotFound           ErrorCode = "toilet_not_found"
+	CodeNoToiletsOnFloor         ErrorCode = "no_toilets_on_floor"
+	CodeTaskNotFound             ErrorCode = "task_not_found"
+	CodeTaskAlreadyActive        ErrorCode = "task_already_active"
+	CodeUserNotFound             ErrorCode = "user_not_found"
+	CodeCleanerNotFound          ErrorCode = "cleaner_not_found"
+	CodeUsernameTaken            ErrorCode = "username_taken"
+	CodeReportNotFound           ErrorCode = "report_not_found"
+	CodeReportFileMissing        ErrorCode = "report_file_missing"
+	CodeFutureReportPeriod       ErrorCode = "future_report_period"
+	CodeRangeTooLarge            ErrorCode = "range_too_large"
+	CodeWebhookNotFound          ErrorCode = "webhook_not_found"
+	CodeWebhookDeliveryNotFound  ErrorCode = "webhook_delivery_not_found"
+	CodeWebhookDeliveryDelivered ErrorCode = "webhook_delivery_delivered"
+	CodePushNotConfigured        ErrorCode = "push_not_configured"
+	CodePushEndpointTaken        ErrorCode = "push_endpoint_taken"
+	CodeVAPIDManagedByEnv        ErrorCode = "vapid_managed_by_env"
+	CodeInternal                 ErrorCode = "internal_error"
+)
+
+// errorStatuses her hata kodunun HTTP durum kodu; mesajlar dil kataloglarındadır (messages_*.go)
+var errorStatuses = map[ErrorCode]int{
+	CodeInvalidRequest:           http.StatusBadRequest,
+	CodeValidationFailed:         http.StatusBadRequest,
+	CodeInvalidCredentials:       http.StatusUnauthorized,
+	CodeUnauthorized:             http.StatusUnauthorized,
+	CodeForbidden:                http.StatusForbidden,
+	CodeRatingTokenRequired:      http.StatusBadRequest,
+	CodeRatingTokenInvalid:       http.StatusNotFound,
+	CodeRatingTokenRevoked:       http.StatusGone,
+	CodeRatingScanRequired:       http.StatusUnauthorized,
+	CodeRatingNotFound:           http.StatusNotFound,
+	CodeToiletNotFound:           http.StatusNotFound,
+	CodeNoToiletsOnFloor:         http.StatusNotFound,
+	CodeTaskNotFound:             http.StatusNotFound,
+	CodeTaskAlreadyActive:        http.StatusConflict,
+	CodeUserNotFound:             http.StatusNotFound,
+	CodeCleanerNotFound:          http.StatusNotFound,
+	CodeUsernameTaken:            http.StatusConflict,
+	CodeReportNotFound:           http.StatusNotFound,
+	CodeReportFileMissing:        http.StatusNotFound,
+	CodeFutureReportPeriod:       http.StatusBadRequest,
+	CodeRangeTooLarge:            http.StatusBadRequest,
+	CodeWebhookNotFound:          http.StatusNotFound,
+	CodeWebhookDeliveryNotFound:  http.StatusNotFound,
+	CodeWebhookDeliveryDelivered: http.StatusConflict,
+	CodePushNotConfigured:        http.StatusServiceUnavailable,
+	CodePushEndpointTaken:        http.StatusConflict,
+	CodeVAPIDManagedByEnv:        http.StatusConflict,
+	CodeInternal:                 http.StatusInternalServerError,
+}
+
+// Alan doğrulama kuralları; FieldError.Code değerleri
+const (
+	RuleRequired      = "required"
+	RuleMin           = "min"
+	RuleMax           = "max"
+	RuleOneOf         = "oneof"
+	RuleRange         = "range"
+	RuleBefore        = "before"
+	RuleURL           = "url"
+	RuleEmail         = "email"
+	RulePhone         = "phone"
+	RulePublicHost    = "public_host"
+	RuleInvalidFormat = "invalid_format"
+	RuleInvalidType   = "invalid_type"
+	RuleUnknownValue  = "unknown_value"
+)
+
+// APIError istemciye dönecek hata; Err sadece loglanır, yanıta yazılmaz
+type APIError struct {
+	Code    ErrorCode
+	Details []FieldError
+	Err     error
+	Context string // Log mesajı için işlem açıklaması
+}
+
+func (e *APIError) Error() string {
+	if e.Err != nil {
+		return string(e.Code) + ": " + e.Context + ": " + e.Err.Error()
+	}
+	return string(e.Code)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// apiError verilen kodla hata oluşturur
+func apiError(code ErrorCode) *APIError {
+	return &APIError{Code: code}
+}
+
+// internalError beklenmeyen hatayı istemciden gizleyip loglanacak şekilde sarar
+func internalError(err error, context string) *APIError {
+	return &APIError{Code: CodeInternal, Err: err, Context: context}
+}
+
+// lookupError kayıt bulunamadıysa verilen kodu, diğer veritabanı hatalarında iç hatayı döner
+func lookupError(err error, code ErrorCode) *APIError {
+	if errors.Is(err, ErrNotFound) || errors.Is(err, gorm.ErrRecordNotFound) {
+		return apiError(code)
+	}
+	return internalError(err, "Kayıt getirilirken hata oluştu")
+}
+
+// validationError alan hatalarıyla doğrulama hatası oluşturur
+func validationError(details ...FieldError) *APIError {
+	return &APIError{Code: CodeValidationFailed, Details: details}
+}
+
+// invalidParam geçersiz path veya query parametresi için doğrulama hatası oluşturur
+func invalidParam(name string) *APIError {
+	return validationError(fieldError(name, RuleInvalidFormat, ""))
+}
+
+// fieldError alan hatası oluşturur; mesaj yanıt yazılırken isteğin dilinde doldurulur
+func fieldError(field, rule, param string) FieldError {
+	return FieldError{Field: field, Code: rule, Param: param}
+}
+
+// bindError ShouldBindJSON hatasını alan ayrıntılarıyla doğrulama hatasına çevirir
+func bindError(err error) *APIError {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		details := make([]FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			details = append(details, fieldError(fieldPath(fe), validationRule(fe.Tag()), fe.Param()))
+		}
+		return validationError(details...)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return validationError(fieldError(typeErr.Field, RuleInvalidType, ""))
+	}
+
+	return apiError(CodeInvalidRequest)
+}
+
+// fieldPath doğrulama hatasının JSON alan yolunu döner (ör. preferences[0].channel)
+func fieldPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return fe.Field()
+}
+
+// validationRule validator etiketini alan kuralına çevirir
+func validationRule(tag string) string {
+	switch tag {
+	case "required", "min", "max", "oneof", "url":
+		return tag
+	case "gte":
+		return RuleMin
+	case "lte":
+		return RuleMax
+	}
+	return RuleInvalidFormat
+}
+
+// respondError hatayı ortak zarfla isteğin dilinde yanıtlar; APIError olmayan hatalar iç hata olarak loglanır
+func respondError(c *gin.Context, err error) {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		apiErr = internalError(err, "İşlem başarısız")
+	}
+
+	if apiErr.Err != nil {
+		log.Printf("%s %s: %s: %v", c.Request.Method, c.Request.URL.Path, apiErr.Context, apiErr.Err)
+	}
+
+	code := apiErr.Code
+	status, ok := errorStatuses[code]
+	if !ok {
+		code, status = CodeInternal, errorStatuses[CodeInternal]
+	}
+
+	lang := requestLanguage(c)
+	details := make([]FieldError, len(apiErr.Details))
+	for i, detail := range apiErr.Details {
+		detail.Message = fieldText(lang, detail.Code, detail.Param)
+		details[i] = detail
+	}
+
+	c.AbortWithStatusJSON(status, ErrorResponse{
+		Success: false,
+		Code:    code,
+		Message: errorText(lang, code),
+		Details: details,
+	})
+}
+
+func init() {
+	// Alan hatalarında Go alan adı yerine JSON adı kullanılır
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// expectError yanıtın ortak hata zarfında verilen durum ve kodla döndüğünü kontrol eder
+func expectError(t *testing.T, s *testServer, method, path string, body interface{}, headers map[string]string, status int, code ErrorCode) ErrorResponse {
+	t.Helper()
+
+	rec := s.request(method, path, body, headers)
+	expectStatus(t, rec, status)
+
+	var resp ErrorResponse
+	decode(t, rec, &resp)
+	if resp.Success || resp.Code != code || resp.Message == "" {
+		t.Fatalf("Beklenmeyen hata yanıtı: %+v (beklenen kod %s)", resp, code)
+	}
+	return resp
+}
+
+func TestErrorEnvelopeCodes(t *testing.T) {
+	s := newTestServer(t)
+
+	expectError(t, s, http.MethodPost, "/api/login", LoginRequest{Username: "yok", Password: "yok"}, nil, http.StatusUnauthorized, CodeInvalidCredentials)
+	expectError(t, s, http.MethodPost, "/api/login", "{bozuk", nil, http.StatusBadRequest, CodeInvalidRequest)
+	expectError(t, s, http.MethodGet, "/api/rating/999", nil, nil, http.StatusNotFound, CodeRatingNotFound)
+	expectError(t, s, http.MethodPut, "/api/admin/users/999", UpdateUserRequest{Name: "Yok"}, s.adminHeaders(), http.StatusNotFound, CodeUserNotFound)
+	expectError(t, s, http.MethodGet, "/api/rating-token/gecersiz", nil, nil, http.StatusNotFound, CodeRatingTokenInvalid)
+	expectError(t, s, http.MethodPost, "/api/rating", RatingRequest{Rating: 3}, nil, http.StatusBadRequest, CodeRatingTokenRequired)
+
+	s.createUser("ayni", "Aynı", "temizlikci")
+	expectError(t, s, http.MethodPost, "/api/admin/users", CreateUserRequest{Username: "ayni", Password: "x", Name: "Aynı"}, s.adminHeaders(), http.StatusConflict, CodeUsernameTaken)
+
+	token := s.ratingToken(1)
+	expectStatus(t, s.request(http.MethodPost, "/api/admin/toilets/1/token/revoke", nil, s.adminHeaders()), http.StatusOK)
+	expectError(t, s, http.MethodGet, "/api/rating-token/"+token, nil, nil, http.StatusGone, CodeRatingTokenRevoked)
+}
+
+func TestValidationErrorDetails(t *testing.T) {
+	s := newTestServer(t)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   interface{}
+		want   []FieldError // Sadece Field, Code ve Param karşılaştırılır
+	}{
+		{
+			"zorunlu alanlar JSON adlarıyla",
+			http.MethodPost, "/api/admin/users", map[string]string{"username": "eksik"},
+			[]FieldError{{Field: "password", Code: RuleRequired}, {Field: "name", Code: RuleRequired}},
+		},
+		{
+			"sınır dışı değer",
+			http.MethodPost, "/api/rating", map[string]interface{}{"token": "x", "rating": 9},
+			[]FieldError{{Field: "rating", Code: RuleMax, Param: "5"}},
+		},
+		{
+			"yanlış veri tipi",
+			http.MethodPost, "/api/rating", map[string]interface{}{"token": "x", "rating": "beş"},
+			[]FieldError{{Field: "rating", Code: RuleInvalidType}},
+		},
+		{
+			"geçersiz path parametresi",
+			http.MethodGet, "/api/toilet/abc/ratings", nil,
+			[]FieldError{{Field: "toiletId", Code: RuleInvalidFormat}},
+		},
+		{
+			"sorgu parametresi seçenek dışı",
+			http.MethodGet, "/api/admin/analytics?bucket=year", nil,
+			[]FieldError{{Field: "bucket", Code: RuleOneOf, Param: "hour day week"}},
+		},
+		{
+			"tarih sırası",
+			http.MethodGet, "/api/admin/analytics?from=2026-03-10&to=2026-03-01", nil,
+			[]FieldError{{Field: "from", Code: RuleBefore, Param: "to"}},
+		},
+		{
+			"iç içe alanlar",
+			http.MethodPut, "/api/admin/users/1/notifications",
+			map[string]interface{}{"preferences": []map[string]interface{}{{"channel": "email"}, {"channel": "guvercin"}}},
+			[]FieldError{{Field: "preferences[0].target", Code: RuleRequired}, {Field: "preferences[1].channel", Code: RuleUnknownValue, Param: "guvercin"}},
+		},
+		{
+			"bildirim hedefleri",
+			http.MethodPut, "/api/admin/users/1/notifications",
+			map[string]interface{}{"preferences": []map[string]interface{}{
+				{"channel": "email", "target": "ayse@example.com\r\nBcc: saldirgan@example.com"},
+				{"channel": "email", "target": "ayse"},
+				{"channel": "sms", "target": "0555 111 22 33"},
+				{"channel": "log", "target": "satir\nsahte"},
+				{"channel": "sms", "target": "+905551112233"},
+			}},
+			[]FieldError{
+				{Field: "preferences[0].target", Code: RuleInvalidFormat},
+				{Field: "preferences[1].target", Code: RuleEmail},
+				{Field: "preferences[2].target", Code: RulePhone},
+				{Field: "preferences[3].target", Code: RuleInvalidFormat},
+			},
+		},
+		{
+			"webhook alanları",
+			http.MethodPost, "/api/admin/webhooks", map[string]interface{}{"url": "ftp://x", "events": []string{"rating.created", "yok"}},
+			[]FieldError{{Field: "url", Code: RuleURL}, {Field: "events[1]", Code: RuleUnknownValue, Param: "yok"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := expectError(t, s, tt.method, tt.path, tt.body, s.adminHeaders(), http.StatusBadRequest, CodeValidationFailed)
+			if len(resp.Details) != len(tt.want) {
+				t.Fatalf("Alan hataları %+v, beklenen %+v", resp.Details, tt.want)
+			}
+			for i, want := range tt.want {
+				got := resp.Details[i]
+				if got.Field != want.Field || got.Code != want.Code || got.Param != want.Param || got.Message == "" {
+					t.Fatalf("%d. alan hatası %+v, beklenen %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestInternalErrorsAreNotLeaked(t *testing.T) {
+	s := newTestServer(t)
+
+	// Tablonun kaybolması gibi beklenmeyen bir veritabanı hatası
+	if err := s.db.Exec("DROP TABLE webhook_subscriptions").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	rec := s.request(http.MethodGet, "/api/admin/webhooks", nil, s.adminHeaders())
+	expectStatus(t, rec, http.StatusInternalServerError)
+	var resp ErrorResponse
+	decode(t, rec, &resp)
+	if resp.Code != CodeInternal {
+		t.Fatalf("Kod %s, beklenen %s", resp.Code, CodeInternal)
+	}
+	if body := strings.ToLower(rec.Body.String()); strings.Contains(body, "webhook_subscriptions") || strings.Contains(body, "no such table") {
+		t.Fatalf("Veritabanı hatası istemciye sızdı: %s", rec.Body.String())
+	}
+
+	// Bulunamadı kontrolü de gerçek hatayı 404'e çevirmemeli
+	rec = s.request(http.MethodPut, "/api/admin/webhooks/1", map[string]interface{}{"is_active": false}, s.adminHeaders())
+	expectStatus(t, rec, http.StatusInternalServerError)
+}
+
+func TestEveryErrorCodeHasSpec(t *testing.T) {
+	codes := []ErrorCode{
+		CodeInvalidRequest, CodeValidationFailed, CodeInvalidCredentials, CodeUnauthorized, CodeForbidden,
+		CodeRatingTokenRequired, CodeRatingTokenInvalid, CodeRatingTokenRevoked, CodeRatingScanRequired,
+		CodeRatingNotFound, CodeToiletNotFound, CodeNoToiletsOnFloor, CodeTaskNotFound, CodeTaskAlreadyActive,
+		CodeUserNotFound, CodeCleanerNotFound, CodeUsernameTaken, CodeReportNotFound, CodeReportFileMissing,
+		CodeFutureReportPeriod, CodeRangeTooLarge, CodeWebhookNotFound, CodeWebhookDeliveryNotFound,
+		CodeWebhookDeliveryDelivered, CodePushNotConfigured, CodePushEndpointTaken, CodeVAPIDManagedByEnv, CodeInternal,
+	}
+	for _, code := range codes {
+		if status := errorStatuses[code]; status < 400 {
+			t.Errorf("%s için durum kodu tanımlı değil", code)
+		}
+		for lang, catalog := range catalogs {
+			if catalog.Errors[code] == "" {
+				t.Errorf("%s için %s mesajı tanımlı değil", code, lang)
+			}
+		}
+	}
+	if len(codes) != len(errorStatuses) {
+		t.Errorf("errorStatuses %d kod içeriyor, test %d kodu kontrol ediyor", len(errorStatuses), len(codes))
+	}
+}
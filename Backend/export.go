@@ -0,0 +1,322 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	exportBatchSize  = 500
+	exportTimeFormat = "2006-01-02 15:04:05"
+)
+
+// tableWriter dışa aktarılan tabloyu satır satır yazar
+type tableWriter interface {
+	WriteRow(values []interface{}) error
+	Close() error
+}
+
+// csvTableWriter satırları doğrudan yanıta CSV olarak yazar
+type csvTableWriter struct {
+	w *csv.Writer
+}
+
+func (t *csvTableWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, v := range escapeFormulaCells(values) {
+		record[i] = fmt.Sprint(v)
+	}
+	return t.w.Write(record)
+}
+
+func (t *csvTableWriter) Close() error {
+	t.w.Flush()
+	return t.w.Error()
+}
+
+// xlsxTableWriter satırları excelize stream writer ile yazar, dosya Close'da yanıta gönderilir.
+// Metinler hücreye metin tipinde yazıldığı için formül olarak yorumlanmaz, kaçırılmaz.
+type xlsxTableWriter struct {
+	file        *excelize.File
+	stream      *excelize.StreamWriter
+	out         http.ResponseWriter
+	row         int
+	headerStyle int
+}
+
+func (t *xlsxTableWriter) WriteRow(values []interface{}) error {
+	t.row++
+	cell, err := excelize.CoordinatesToCellName(1, t.row)
+	if err != nil {
+		return err
+	}
+	if t.row == 1 {
+		return t.stream.SetRow(cell, values, excelize.RowOpts{StyleID: t.headerStyle})
+	}
+	return t.stream.SetRow(cell, values)
+}
+
+func (t *xlsxTableWriter) Close() error {
+	defer t.file.Close()
+	if err := t.stream.Flush(); err != nil {
+		return err
+	}
+	return t.file.Write(t.out)
+}
+
+// newTableWriter istenen formata göre yanıt başlıklarını ayarlar ve yazıcıyı döner
+func newTableWriter(c *gin.Context, format, name, sheet string) (tableWriter, error) {
+	filename := name + "." + format
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	if format == "csv" {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Status(http.StatusOK)
+		// Excel'in Türkçe karakterleri doğru açması için UTF-8 BOM
+		c.Writer.Write([]byte("\xEF\xBB\xBF"))
+		return &csvTableWriter{w: csv.NewWriter(c.Writer)}, nil
+	}
+
+	file := excelize.NewFile()
+	if err := file.SetSheetName("Sheet1", sheet); err != nil {
+		return nil, err
+	}
+	stream, err := file.NewStreamWriter(sheet)
+	if err != nil {
+		return nil, err
+	}
+	headerStyle, err := file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return nil, err
+	}
+
+	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	c.Status(http.StatusOK)
+	return &xlsxTableWriter{file: file, stream: stream, out: c.Writer, headerStyle: headerStyle}, nil
+}
+
+// parseExportRequest format ve filtre parametrelerini okur, hata varsa yanıtı yazıp false döner
+func parseExportRequest(c *gin.Context) (string, analyticsFilter, bool) {
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "xlsx" {
+		respondError(c, validationError(fieldError("format", RuleOneOf, "csv xlsx")))
+		return "", analyticsFilter{}, false
+	}
+
+	filter, err := parseAnalyticsFilter(c, defaultAnalyticsRange)
+	if err != nil {
+		respondError(c, err)
+		return "", filter, false
+	}
+
+	return format, filter, true
+}
+
+// exportFileName dosya adına tarih aralığını ekler
+func exportFileName(prefix string, filter analyticsFilter) string {
+	return fmt.Sprintf("%s_%s_%s", prefix, filter.From.Format("2006-01-02"), filter.To.Add(-time.Second).Format("2006-01-02"))
+}
+
+// exportTime zamanı tabloda gösterilecek formata çevirir
+func exportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.In(facilityLocation).Format(exportTimeFormat)
+}
+
+// exportMinutes süreyi iki ondalık basamağa yuvarlar, değer yoksa boş hücre döner
+func exportMinutes(minutes *float64) interface{} {
+	if minutes == nil {
+		return ""
+	}
+	return math.Round(*minutes*100) / 100
+}
+
+// problemLabels puanlamadaki problem ID'lerini katalogdaki metinlere verilen dilde çevirir
+func problemLabels(rating Rating, lang string) string {
+	ids := ratingProblemIDs(rating)
+	labels := make([]string, 0, len(ids))
+	for _, id := range ids {
+		labels = append(labels, problemLabelOrUnknown(id, lang))
+	}
+	return strings.Join(labels, ", ")
+}
+
+// toiletLookup tuvalet ad ve konumlarını ID'ye göre getirir
+func toiletLookup() (map[int]Toilet, error) {
+	var toilets []Toilet
+	if err := DB.Find(&toilets).Error; err != nil {
+		return nil, err
+	}
+
+	lookup := make(map[int]Toilet, len(toilets))
+	for _, toilet := range toilets {
+		lookup[toilet.ID] = toilet
+	}
+	return lookup, nil
+}
+
+// escapeFormulaCells CSV'de formül olarak yorumlanabilecek metin hücrelerinin başına ' ekler;
+// puanlama açıklaması ve isimler gibi kullanıcı girdileri tabloda komut çalıştıramaz
+func escapeFormulaCells(values []interface{}) []interface{} {
+	escaped := make([]interface{}, len(values))
+	for i, v := range values {
+		if text, ok := v.(string); ok && text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+			v = "'" + text
+		}
+		escaped[i] = v
+	}
+	return escaped
+}
+
+// streamExport başlığı ve satırları yazar; yanıt başladıktan sonra oluşan hatalar sadece loglanır
+func streamExport(c *gin.Context, format, name, sheet string, header []interface{}, rows func(write func([]interface{}) error) error) {
+	writer, err := newTableWriter(c, format, name, sheet)
+	if err != nil {
+		respondError(c, internalError(err, "Dosya oluşturulamadı"))
+		return
+	}
+
+	if err := writer.WriteRow(header); err != nil {
+		log.Printf("Dışa aktarma başarısız (%s): %v", name, err)
+		return
+	}
+	if err := rows(writer.WriteRow); err != nil {
+		log.Printf("Dışa aktarma başarısız (%s): %v", name, err)
+		return
+	}
+	if err := writer.Close(); err != nil {
+		log.Printf("Dışa aktarma başarısız (%s): %v", name, err)
+	}
+}
+
+// exportRatings puanlamaları problem metinleriyle birlikte CSV veya XLSX olarak indirir (sadece admin erişimi)
+func exportRatings(c *gin.Context) {
+	format, filter, ok := parseExportRequest(c)
+	if !ok {
+		return
+	}
+
+	toilets, err := toiletLookup()
+	if err != nil {
+		respondError(c, internalError(err, "Tuvaletler getirilirken hata oluştu"))
+		return
+	}
+
+	header := []interface{}{"ID", "Tuvalet ID", "Tuvalet", "Kat", "Puan", "Problemler", "Diğer Açıklama", "Tarih"}
+	lang := requestLanguage(c)
+
+	streamExport(c, format, exportFileName("puanlamalar", filter), "Puanlamalar", header, func(write func([]interface{}) error) error {
+		var batch []Rating
+		return filter.scopeToilets(DB.Model(&Rating{})).
+			Where("created_at >= ? AND created_at < ?", filter.From, filter.To).
+			Order("id ASC").
+			FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+				for _, rating := range batch {
+					toilet := toilets[rating.ToiletID]
+					err := write([]interface{}{
+						rating.ID, rating.ToiletID, toilet.Name, toilet.Location, rating.Rating,
+						problemLabels(rating, lang), rating.OtherText, exportTime(&rating.CreatedAt),
+					})
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			}).Error
+	})
+}
+
+// exportTasks temizlik görevlerini CSV veya XLSX olarak indirir (sadece admin erişimi)
+func exportTasks(c *gin.Context) {
+	format, filter, ok := parseExportRequest(c)
+	if !ok {
+		return
+	}
+
+	toilets, err := toiletLookup()
+	if err != nil {
+		respondError(c, internalError(err, "Tuvaletler getirilirken hata oluştu"))
+		return
+	}
+
+	header := []interface{}{"ID", "Tuvalet ID", "Tuvalet", "Kat", "Temizlikçi ID", "Temizlikçi", "Durum",
+		"Oluşturulma", "Başlangıç", "Bitiş", "Süre (dk)"}
+
+	streamExport(c, format, exportFileName("temizlik-gorevleri", filter), "Görevler", header, func(write func([]interface{}) error) error {
+		query := filter.scopeToilets(DB.Model(&CleaningTask{})).
+			Where("created_at >= ? AND created_at < ?", filter.From, filter.To)
+		if filter.CleanerID != 0 {
+			query = query.Where("cleaner_id = ?", filter.CleanerID)
+		}
+
+		var batch []CleaningTask
+		return query.Order("id ASC").FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+			for _, task := range batch {
+				toilet := toilets[task.ToiletID]
+
+				var duration *float64
+				if minutes, ok := cleaningMinutes(task); ok {
+					duration = &minutes
+				}
+
+				err := write([]interface{}{
+					task.ID, task.ToiletID, toilet.Name, toilet.Location, task.CleanerID, task.CleanerName, task.Status,
+					exportTime(&task.CreatedAt), exportTime(task.StartedAt), exportTime(task.CompletedAt), exportMinutes(duration),
+				})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+	})
+}
+
+// exportCleanerStats temizlikçi istatistiklerini filtre aralığı ve kapsamında CSV veya XLSX olarak indirir (sadece admin erişimi)
+func (h *Handlers) exportCleanerStats(c *gin.Context) {
+	format, filter, ok := parseExportRequest(c)
+	if !ok {
+		return
+	}
+
+	cleanerStats, err := h.Stats.Cleaners(filter)
+	if err != nil {
+		respondError(c, internalError(err, "Temizlikçi istatistikleri getirilirken hata oluştu"))
+		return
+	}
+
+	header := []interface{}{"Temizlikçi ID", "Temizlikçi", "Durum", "Toplam Temizlik", "Ortalama (dk)", "Medyan (dk)",
+		"%90 (dk)", "En Hızlı (dk)", "En Yavaş (dk)", "Toplam Süre (dk)", "Son 7 Gün", "Son 30 Gün", "Devam Eden", "Hariç Tutulan"}
+
+	streamExport(c, format, exportFileName("temizlikci-istatistikleri", filter), "Temizlikçiler", header, func(write func([]interface{}) error) error {
+		for _, stats := range cleanerStats {
+			status := "Pasif"
+			if stats.IsActive {
+				status = "Aktif"
+			}
+
+			total := stats.TotalCleaningTime
+			err := write([]interface{}{
+				stats.CleanerID, stats.CleanerName, status, stats.TotalCompletedTasks,
+				exportMinutes(stats.AverageCleaningTime), exportMinutes(stats.MedianCleaningTime), exportMinutes(stats.P90CleaningTime),
+				exportMinutes(stats.FastestCleaningTime), exportMinutes(stats.SlowestCleaningTime), exportMinutes(&total),
+				stats.LastWeekTasks, stats.LastMonthTasks, stats.OngoingTasks, stats.ExcludedOutliers,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// readCSVExport BOM'u atlayarak CSV yanıtını satırlara ayırır
+func readCSVExport(t *testing.T, body []byte) [][]string {
+	t.Helper()
+
+	records, err := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(body, []byte("\xEF\xBB\xBF")))).ReadAll()
+	if err != nil {
+		t.Fatalf("CSV okunamadı: %v", err)
+	}
+	return records
+}
+
+func TestEscapeFormulaCells(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  interface{}
+	}{
+		{"eşittir", "=HYPERLINK(\"http://x\")", "'=HYPERLINK(\"http://x\")"},
+		{"artı", "+90 555", "'+90 555"},
+		{"eksi", "-1+2", "'-1+2"},
+		{"at", "@SUM(A1)", "'@SUM(A1)"},
+		{"sekme", "\t=1+1", "'\t=1+1"},
+		{"satır başı", "\r=1+1", "'\r=1+1"},
+		{"düz metin", "Lavabo kirli", "Lavabo kirli"},
+		{"ortada işaret", "a=b", "a=b"},
+		{"boş", "", ""},
+		{"negatif sayı", -3.5, -3.5},
+		{"tam sayı", 7, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escapeFormulaCells([]interface{}{tt.value})[0]; got != tt.want {
+				t.Fatalf("escapeFormulaCells(%v) = %v, beklenen %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportCleanerStatsUsesFilter(t *testing.T) {
+	s := newTestServer(t)
+	ayse := s.createUser("ayse", "=Ayşe", "temizlikci")
+	mehmet := s.createUser("mehmet", "Mehmet", "temizlikci")
+
+	now := time.Now()
+	task := func(cleaner User, toiletID int, completedAt time.Time) {
+		s.insertTask(cleaner, toiletID, "completed", timePtr(completedAt.Add(-10*time.Minute)), timePtr(completedAt))
+	}
+	task(ayse, 1, now.Add(-2*24*time.Hour))  // aralıkta, son hafta
+	task(ayse, 1, now.Add(-10*24*time.Hour)) // aralıkta, son ay
+	task(ayse, 1, now.Add(-40*24*time.Hour)) // aralık dışında
+	task(ayse, 3, now.Add(-3*24*time.Hour))  // başka kat
+	task(mehmet, 2, now.Add(-1*24*time.Hour))
+	s.insertTask(ayse, 1, "in_progress", timePtr(now), nil)
+
+	from := now.Add(-20 * 24 * time.Hour).Format(time.RFC3339)
+	to := now.Add(time.Hour).Format(time.RFC3339)
+
+	tests := []struct {
+		name  string
+		query url.Values
+		rows  [][]string // temizlikçi ID, ad, toplam, son 7 gün, son 30 gün, devam eden
+	}{
+		{
+			"tarih aralığı",
+			url.Values{},
+			[][]string{
+				{fmt.Sprint(ayse.ID), "'=Ayşe", "3", "2", "3", "1"},
+				{fmt.Sprint(mehmet.ID), "Mehmet", "1", "1", "1", "0"},
+			},
+		},
+		{
+			"kat filtresi",
+			url.Values{"floor": {"1. Kat"}},
+			[][]string{
+				{fmt.Sprint(ayse.ID), "'=Ayşe", "2", "1", "2", "1"},
+				{fmt.Sprint(mehmet.ID), "Mehmet", "1", "1", "1", "0"},
+			},
+		},
+		{
+			"temizlikçi ve tuvalet filtresi",
+			url.Values{"cleaner_id": {fmt.Sprint(ayse.ID)}, "toilet_id": {"3"}},
+			[][]string{
+				{fmt.Sprint(ayse.ID), "'=Ayşe", "1", "1", "1", "0"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.query.Set("from", from)
+			tt.query.Set("to", to)
+			rec := s.request(http.MethodGet, "/api/admin/export/cleaner-stats?"+tt.query.Encode(), nil, s.adminHeaders())
+			expectStatus(t, rec, http.StatusOK)
+
+			records := readCSVExport(t, rec.Body.Bytes())
+			if len(records)-1 != len(tt.rows) {
+				t.Fatalf("%d satır, beklenen %d: %v", len(records)-1, len(tt.rows), records)
+			}
+			for i, want := range tt.rows {
+				r := records[i+1]
+				got := []string{r[0], r[1], r[3], r[10], r[11], r[12]}
+				if strings.Join(got, "|") != strings.Join(want, "|") {
+					t.Fatalf("%d. satır %v, beklenen %v", i, got, want)
+				}
+			}
+		})
+	}
+
+	expectError(t, s, http.MethodGet, "/api/admin/export/cleaner-stats?from=x", nil, s.adminHeaders(), http.StatusBadRequest, CodeValidationFailed)
+}
+
+func TestExportRoutesRequireAdmin(t *testing.T) {
+	s := newTestServer(t)
+	cleaner := s.createUser("ayse", "Ayşe", "temizlikci")
+
+	for _, path := range []string{"/api/admin/export/ratings", "/api/admin/export/tasks", "/api/admin/export/cleaner-stats"} {
+		t.Run(path, func(t *testing.T) {
+			expectError(t, s, http.MethodGet, path, nil, nil, http.StatusUnauthorized, CodeUnauthorized)
+			expectError(t, s, http.MethodGet, path, nil, staffHeaders(cleaner), http.StatusForbidden, CodeForbidden)
+			expectStatus(t, s.request(http.MethodGet, path, nil, s.adminHeaders()), http.StatusOK)
+		})
+	}
+}
+
+func TestExportRatingsEscapesFormulas(t *testing.T) {
+	s := newTestServer(t)
+	rating := s.insertRating(1, 1, "[]", time.Now().Add(-time.Hour))
+	if err := s.db.Model(&rating).Update("other_text", "=cmd|' /C calc'!A0").Error; err != nil {
+		t.Fatalf("Açıklama güncellenemedi: %v", err)
+	}
+
+	rec := s.request(http.MethodGet, "/api/admin/export/ratings?format=csv", nil, s.adminHeaders())
+	expectStatus(t, rec, http.StatusOK)
+	records := readCSVExport(t, rec.Body.Bytes())
+	if len(records) != 2 || records[1][6] != "'=cmd|' /C calc'!A0" {
+		t.Fatalf("CSV açıklaması kaçırılmadı: %v", records)
+	}
+
+	rec = s.request(http.MethodGet, "/api/admin/export/ratings?format=xlsx", nil, s.adminHeaders())
+	expectStatus(t, rec, http.StatusOK)
+	file, err := excelize.OpenReader(rec.Body)
+	if err != nil {
+		t.Fatalf("XLSX okunamadı: %v", err)
+	}
+	defer file.Close()
+	// XLSX hücreye metin olarak yazılır; kaçırma işareti veriye eklenmez
+	value, err := file.GetCellValue("Puanlamalar", "G2")
+	if err != nil || value != "=cmd|' /C calc'!A0" {
+		t.Fatalf("XLSX açıklaması %q (%v)", value, err)
+	}
+	if formula, _ := file.GetCellFormula("Puanlamalar", "G2"); formula != "" {
+		t.Fatalf("Hücre formül olarak yazıldı: %q", formula)
+	}
+}
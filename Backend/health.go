@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// readinessTimeout hazır olma kontrolünde veritabanı ping'i için beklenen en uzun süre
+const readinessTimeout = 2 * time.Second
+
+var errDatabaseNotInitialized = errors.New("veritabanı bağlantısı kurulmadı")
+
+// shuttingDown kapanma başladığında işaretlenir; yük dengeleyici yeni trafik göndermeyi bırakır
+var shuttingDown atomic.Bool
+
+// healthz süreç ayakta olduğu sürece 200 döner (liveness)
+func healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, HealthResponse{Status: "ok"})
+}
+
+// readyz veritabanına erişilebiliyorsa ve kapanma başlamadıysa 200, aksi halde 503 döner (readiness)
+func readyz(c *gin.Context) {
+	if shuttingDown.Load() {
+		c.JSON(http.StatusServiceUnavailable, HealthResponse{Status: "shutting_down", Database: "unknown"})
+		return
+	}
+
+	// Hata ayrıntısı bağlantı bilgisi içerebileceği için yanıta değil loga yazılır
+	if err := pingDatabase(c.Request.Context()); err != nil {
+		log.Printf("Hazır olma kontrolü: veritabanına erişilemiyor: %v", err)
+		c.JSON(http.StatusServiceUnavailable, HealthResponse{Status: "unavailable", Database: "unreachable"})
+		return
+	}
+
+	c.JSON(http.StatusOK, HealthResponse{Status: "ok", Database: "ok"})
+}
+
+// pingDatabase veritabanı bağlantısını kısa bir zaman aşımıyla kontrol eder
+func pingDatabase(ctx context.Context) error {
+	if DB == nil {
+		return errDatabaseNotInitialized
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+	return sqlDB.PingContext(ctx)
+}
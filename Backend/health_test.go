@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestHealthAndReadiness(t *testing.T) {
+	s := newTestServer(t)
+
+	rec := s.request(http.MethodGet, "/healthz", nil, nil)
+	expectStatus(t, rec, http.StatusOK)
+
+	rec = s.request(http.MethodGet, "/readyz", nil, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var health HealthResponse
+	decode(t, rec, &health)
+	if health.Status != "ok" || health.Database != "ok" {
+		t.Fatalf("Beklenmeyen hazır olma yanıtı: %+v", health)
+	}
+
+	shuttingDown.Store(true)
+	rec = s.request(http.MethodGet, "/readyz", nil, nil)
+	shuttingDown.Store(false)
+	expectStatus(t, rec, http.StatusServiceUnavailable)
+
+	// Veritabanı kapandığında süreç canlı ama hazır değil
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.Close()
+
+	rec = s.request(http.MethodGet, "/readyz", nil, nil)
+	expectStatus(t, rec, http.StatusServiceUnavailable)
+	decode(t, rec, &health)
+	if health.Database != "unreachable" {
+		t.Fatalf("Beklenmeyen veritabanı durumu: %+v", health)
+	}
+	expectStatus(t, s.request(http.MethodGet, "/healthz", nil, nil), http.StatusOK)
+}
+
+func TestBackgroundWorkersStopOnCancel(t *testing.T) {
+	newTestServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	startWebhookWorker(ctx)
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		backgroundJobs.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Webhook işçisi iptalden sonra durmadı")
+	}
+}
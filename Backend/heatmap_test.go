@@ -0,0 +1,96 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestProblemHeatmap(t *testing.T) {
+	s := newTestServer(t)
+	loc := setFacilityTimezone(t, "Europe/Istanbul")
+
+	// 2026-03-09 pazartesi
+	at := func(day, hour, minute int) time.Time { return time.Date(2026, 3, day, hour, minute, 0, 0, loc) }
+	s.insertRating(1, 2, "[1,3]", at(9, 8, 15)) // pazartesi 08:00, iki problem
+	s.insertRating(2, 1, "[1]", at(9, 8, 59))   // pazartesi 08:00
+	s.insertRating(3, 2, "[2]", at(15, 23, 30)) // pazar 23:00, 2. kat
+	s.insertRating(1, 5, "[]", at(10, 12, 0))   // problemsiz puanlama sayılmaz
+	s.insertRating(1, 1, "[4]", at(16, 0, 0))   // aralık dışında
+	// UTC'de pazar 21:30, binada pazartesi 00:30
+	s.insertRating(2, 2, "[3]", time.Date(2026, 3, 8, 21, 30, 0, 0, time.UTC))
+
+	tests := []struct {
+		name  string
+		query url.Values
+		cells map[[2]int]int // {gün, saat} -> sayı; diğer hücreler 0
+		total int
+		max   int
+	}{
+		{
+			"tüm tuvaletler",
+			url.Values{},
+			map[[2]int]int{{0, 8}: 3, {0, 0}: 1, {6, 23}: 1},
+			5, 3,
+		},
+		{
+			"kat filtresi",
+			url.Values{"floor": {"1. Kat"}},
+			map[[2]int]int{{0, 8}: 3, {0, 0}: 1},
+			4, 3,
+		},
+		{
+			"tuvalet filtresi",
+			url.Values{"toilet_id": {"3"}},
+			map[[2]int]int{{6, 23}: 1},
+			1, 1,
+		},
+		{
+			"problem türü filtresi",
+			url.Values{"problem_type": {"1"}},
+			map[[2]int]int{{0, 8}: 2},
+			2, 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.query.Set("from", "2026-03-08")
+			tt.query.Set("to", "2026-03-15")
+			rec := s.request(http.MethodGet, "/api/admin/analytics/heatmap?"+tt.query.Encode(), nil, s.adminHeaders())
+			expectStatus(t, rec, http.StatusOK)
+
+			var resp HeatmapResponse
+			decode(t, rec, &resp)
+			if resp.Total != tt.total || resp.Max != tt.max {
+				t.Fatalf("Toplam %d, en yüksek %d; beklenen %d, %d", resp.Total, resp.Max, tt.total, tt.max)
+			}
+			if len(resp.Matrix) != 7 || len(resp.Weekdays) != 7 {
+				t.Fatalf("Matris %d satır, %d gün adı", len(resp.Matrix), len(resp.Weekdays))
+			}
+			for day, hours := range resp.Matrix {
+				if len(hours) != 24 {
+					t.Fatalf("%d. gün %d saat içeriyor", day, len(hours))
+				}
+				for hour, count := range hours {
+					if want := tt.cells[[2]int{day, hour}]; count != want {
+						t.Fatalf("Hücre [%d][%d] = %d, beklenen %d", day, hour, count, want)
+					}
+				}
+			}
+		})
+	}
+
+	expectError(t, s, http.MethodGet, "/api/admin/analytics/heatmap?problem_type=99", nil, s.adminHeaders(), http.StatusBadRequest, CodeValidationFailed)
+}
+
+func TestProblemHeatmapRequiresAdmin(t *testing.T) {
+	s := newTestServer(t)
+	cleaner := s.createUser("ayse", "Ayşe", "temizlikci")
+	path := "/api/admin/analytics/heatmap"
+
+	expectError(t, s, http.MethodGet, path, nil, nil, http.StatusUnauthorized, CodeUnauthorized)
+	expectError(t, s, http.MethodGet, path, nil, staffHeaders(cleaner), http.StatusForbidden, CodeForbidden)
+	expectStatus(t, s.request(http.MethodGet, path, nil, s.adminHeaders()), http.StatusOK)
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Desteklenen diller; kullanıcı tercihi ve Accept-Language bu değerlere eşlenir
+const (
+	LangTurkish = "tr"
+	LangEnglish = "en"
+
+	defaultLanguage = LangTurkish
+)
+
+// requestLanguageKey isteğin dilinin gin context'inde saklandığı anahtar
+const requestLanguageKey = "request_language"
+
+// MessageCode başarılı yanıt mesajlarının dilden bağımsız anahtarı
+type MessageCode string
+
+// Başarı mesajı kodları
+const (
+	MsgLoginSucceeded           MessageCode = "login_succeeded"
+	MsgRatingCreated            MessageCode = "rating_created"
+	MsgRatingsFetched           MessageCode = "ratings_fetched"
+	MsgProblemTypesFetched      MessageCode = "problem_types_fetched"
+	MsgTaskCreated              MessageCode = "task_created"
+	MsgTaskStarted              MessageCode = "task_started"
+	MsgTaskCompleted            MessageCode = "task_completed"
+	MsgTaskAssigned             MessageCode = "task_assigned"
+	MsgUsersFetched             MessageCode = "users_fetched"
+	MsgUserCreated              MessageCode = "user_created"
+	MsgUserUpdated              MessageCode = "user_updated"
+	MsgUserDeleted              MessageCode = "user_deleted"
+	MsgStatsFetched             MessageCode = "stats_fetched"
+	MsgAnalyticsFetched         MessageCode = "analytics_fetched"
+	MsgHeatmapFetched           MessageCode = "heatmap_fetched"
+	MsgPerformanceFetched       MessageCode = "performance_fetched"
+	MsgPreferencesFetched       MessageCode = "preferences_fetched"
+	MsgPreferencesUpdated       MessageCode = "preferences_updated"
+	MsgTokenFetched             MessageCode = "token_fetched"
+	MsgTokenRotated             MessageCode = "token_rotated"
+	MsgTokenRevoked             MessageCode = "token_revoked"
+	MsgReportsFetched           MessageCode = "reports_fetched"
+	MsgReportCreated            MessageCode = "report_created"
+	MsgReportEmailed            MessageCode = "report_emailed"
+	MsgReportEmailFailed        MessageCode = "report_email_failed"
+	MsgWebhookCreated           MessageCode = "webhook_created"
+	MsgWebhookUpdated           MessageCode = "webhook_updated"
+	MsgWebhookDeleted           MessageCode = "webhook_deleted"
+	MsgWebhookDeliveriesFetched MessageCode = "webhook_deliveries_fetched"
+	MsgWebhookTestSent          MessageCode = "webhook_test_sent"
+	MsgWebhookDeliveryRequeued  MessageCode = "webhook_delivery_requeued"
+	MsgPushSubscribed           MessageCode = "push_subscribed"
+	MsgPushUnsubscribed         MessageCode = "push_unsubscribed"
+	MsgVAPIDRotated             MessageCode = "vapid_rotated"
+)
+
+// NotificationText kullanıcılara gönderilen bildirim metinlerinin dilden bağımsız anahtarı
+type NotificationText string
+
+// Bildirim metni anahtarları; metinler fmt şablonudur
+const (
+	NoteBadRatingSubject    NotificationText = "bad_rating_subject"
+	NoteBadRatingBody       NotificationText = "bad_rating_body"
+	NoteBadRatingProblems   NotificationText = "bad_rating_problems"
+	NoteTaskAssignedSubject NotificationText = "task_assigned_subject"
+	NoteTaskAssignedBody    NotificationText = "task_assigned_body"
+	NoteUrgentProblem       NotificationText = "urgent_problem"
+)
+
+// messageCatalog bir dildeki tüm API metinleri
+type messageCatalog struct {
+	Errors        map[ErrorCode]string
+	Messages      map[MessageCode]string
+	Fields        map[string]string // Alan kuralı → mesaj şablonu; {param} kuralın parametresiyle değiştirilir
+	Notifications map[NotificationText]string
+	Weekdays      []string // Pazartesiden başlar
+}
+
+// catalogs desteklenen dillerin katalogları; eksik metinler varsayılan dilden alınır
+var catalogs = map[string]messageCatalog{
+	LangTurkish: turkishCatalog,
+	LangEnglish: englishCatalog,
+}
+
+// supportedLanguage dilin desteklenip desteklenmediğini döner
+func supportedLanguage(lang string) bool {
+	_, ok := catalogs[lang]
+	return ok
+}
+
+// negotiateLanguage Accept-Language başlığından q değerine göre desteklenen en uygun dili seçer.
+// Bölge kodu dikkate alınmaz (en-US → en); uygun dil yoksa varsayılan dil döner.
+func negotiateLanguage(header string) string {
+	type candidate struct {
+		lang    string
+		quality float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		lang := strings.ToLower(strings.TrimSpace(fields[0]))
+		if i := strings.IndexAny(lang, "-_"); i >= 0 {
+			lang = lang[:i]
+		}
+
+		quality := 1.0
+		for _, param := range fields[1:] {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if q, err := strconv.ParseFloat(value, 64); err == nil {
+					quality = q
+				}
+			}
+		}
+
+		if quality > 0 && supportedLanguage(lang) {
+			candidates = append(candidates, candidate{lang, quality})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return defaultLanguage
+	}
+
+	// Eşit q değerlerinde başlıktaki sıra korunur
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+	return candidates[0].lang
+}
+
+// setRequestLanguage isteğin dilini belirler ve yanıtın dil başlığını ayarlar
+func setRequestLanguage(c *gin.Context, lang string) {
+	c.Set(requestLanguageKey, lang)
+	c.Header("Content-Language", lang)
+}
+
+// requestLanguage isteğin dilini döner; belirlenmemişse Accept-Language başlığından seçer
+func requestLanguage(c *gin.Context) string {
+	if lang := c.GetString(requestLanguageKey); lang != "" {
+		return lang
+	}
+	lang := negotiateLanguage(c.GetHeader("Accept-Language"))
+	setRequestLanguage(c, lang)
+	return lang
+}
+
+// resolveLanguage giriş yapmış personelin kayıtlı dil tercihini, yoksa Accept-Language başlığını kullanır
+func (h *Handlers) resolveLanguage(c *gin.Context) {
+	c.Writer.Header().Add("Vary", "Accept-Language")
+
+	lang := ""
+	if c.GetHeader("Authorization") != "" && c.GetHeader("X-User-ID") != "" {
+		if user, ok := h.staffUserFromRequest(c); ok && supportedLanguage(user.Language) {
+			lang = user.Language
+		}
+	}
+	if lang == "" {
+		lang = negotiateLanguage(c.GetHeader("Accept-Language"))
+	}
+
+	setRequestLanguage(c, lang)
+	c.Next()
+}
+
+// catalogFor dilin kataloğunu döner, desteklenmeyen dillerde varsayılan katalog döner
+func catalogFor(lang string) messageCatalog {
+	if catalog, ok := catalogs[lang]; ok {
+		return catalog
+	}
+	return catalogs[defaultLanguage]
+}
+
+// errorText hata kodunun verilen dildeki mesajını döner
+func errorText(lang string, code ErrorCode) string {
+	if text, ok := catalogFor(lang).Errors[code]; ok {
+		return text
+	}
+	return catalogs[defaultLanguage].Errors[code]
+}
+
+// fieldText alan kuralının verilen dildeki mesajını parametreyle doldurarak döner
+func fieldText(lang, rule, param string) string {
+	template, ok := catalogFor(lang).Fields[rule]
+	if !ok {
+		template = catalogs[defaultLanguage].Fields[rule]
+	}
+	return strings.ReplaceAll(template, "{param}", param)
+}
+
+// messageText başarı mesajını isteğin dilinde döner
+func messageText(c *gin.Context, code MessageCode) string {
+	if text, ok := catalogFor(requestLanguage(c)).Messages[code]; ok {
+		return text
+	}
+	return catalogs[defaultLanguage].Messages[code]
+}
+
+// notificationText bildirim metnini verilen dilde şablonu doldurarak döner
+func notificationText(lang string, key NotificationText, args ...interface{}) string {
+	template, ok := catalogFor(lang).Notifications[key]
+	if !ok {
+		template = catalogs[defaultLanguage].Notifications[key]
+	}
+	return fmt.Sprintf(template, args...)
+}
+
+// weekdayLabels pazartesiden başlayan gün adlarını isteğin dilinde döner
+func weekdayLabels(c *gin.Context) []string {
+	return catalogFor(requestLanguage(c)).Weekdays
+}
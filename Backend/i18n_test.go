@@ -0,0 +1,127 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestNegotiateLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", LangTurkish},
+		{"en", LangEnglish},
+		{"en-US,en;q=0.9", LangEnglish},
+		{"de-DE,de;q=0.9,en;q=0.8,tr;q=0.7", LangEnglish},
+		{"tr;q=0.5,en;q=0.8", LangEnglish},
+		{"en;q=0,tr", LangTurkish},
+		{"fr, de", LangTurkish},
+		{"EN_gb", LangEnglish},
+		{"tr, en", LangTurkish},
+	}
+
+	for _, tt := range tests {
+		if got := negotiateLanguage(tt.header); got != tt.want {
+			t.Errorf("negotiateLanguage(%q) = %s, beklenen %s", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestCatalogsAreComplete(t *testing.T) {
+	base := catalogs[defaultLanguage]
+	for lang, catalog := range catalogs {
+		for code := range base.Messages {
+			if catalog.Messages[code] == "" {
+				t.Errorf("%s kataloğunda %s mesajı eksik", lang, code)
+			}
+		}
+		for rule := range base.Fields {
+			if catalog.Fields[rule] == "" {
+				t.Errorf("%s kataloğunda %s alan mesajı eksik", lang, rule)
+			}
+		}
+		for key := range base.Notifications {
+			if catalog.Notifications[key] == "" {
+				t.Errorf("%s kataloğunda %s bildirim metni eksik", lang, key)
+			}
+		}
+		if len(catalog.Weekdays) != 7 {
+			t.Errorf("%s kataloğunda %d gün adı var", lang, len(catalog.Weekdays))
+		}
+	}
+}
+
+func TestLocalizedMessages(t *testing.T) {
+	s := newTestServer(t)
+	english := map[string]string{"Accept-Language": "en-US,en;q=0.9"}
+
+	t.Run("hata ve alan mesajları", func(t *testing.T) {
+		rec := s.request(http.MethodPost, "/api/rating", map[string]interface{}{"token": "x", "rating": 9}, english)
+		expectStatus(t, rec, http.StatusBadRequest)
+		if got := rec.Header().Get("Content-Language"); got != LangEnglish {
+			t.Fatalf("Content-Language %q, beklenen en", got)
+		}
+
+		var resp ErrorResponse
+		decode(t, rec, &resp)
+		if resp.Message != "The submitted data is invalid" || len(resp.Details) != 1 || resp.Details[0].Message != "Must be at most 5" {
+			t.Fatalf("İngilizce hata yanıtı beklenirdi: %+v", resp)
+		}
+	})
+
+	t.Run("varsayılan dil Türkçe", func(t *testing.T) {
+		rec := s.request(http.MethodGet, "/api/rating/999", nil, nil)
+		var resp ErrorResponse
+		decode(t, rec, &resp)
+		if resp.Message != "Puanlama bulunamadı" || rec.Header().Get("Content-Language") != LangTurkish {
+			t.Fatalf("Türkçe hata yanıtı beklenirdi: %+v", resp)
+		}
+	})
+
+	t.Run("başarı mesajı", func(t *testing.T) {
+		headers := s.adminHeaders()
+		headers["Accept-Language"] = english["Accept-Language"]
+		rec := s.request(http.MethodGet, "/api/admin/users", nil, headers)
+		var resp UsersResponse
+		decode(t, rec, &resp)
+		if resp.Message != "Users fetched successfully" {
+			t.Fatalf("Mesaj %q", resp.Message)
+		}
+	})
+
+	t.Run("kullanıcı tercihi tarayıcı dilinden önce gelir", func(t *testing.T) {
+		rec := s.request(http.MethodPost, "/api/admin/users", CreateUserRequest{
+			Username: "john", Password: "parola-john", Name: "John", Role: "admin", Language: LangEnglish,
+		}, s.adminHeaders())
+		expectStatus(t, rec, http.StatusCreated)
+		var created UserResponse
+		decode(t, rec, &created)
+
+		rec = s.request(http.MethodPost, "/api/login", LoginRequest{Username: "john", Password: "parola-john"}, map[string]string{"Accept-Language": "tr"})
+		var login LoginResponse
+		decode(t, rec, &login)
+		if login.Message != "Signed in successfully" {
+			t.Fatalf("Giriş mesajı %q, kullanıcının dilinde beklenirdi", login.Message)
+		}
+
+		headers := staffHeaders(s.loadUser(created.User.ID))
+		headers["Accept-Language"] = "tr"
+		rec = s.request(http.MethodGet, "/api/admin/analytics?bucket=year", nil, headers)
+		var resp ErrorResponse
+		decode(t, rec, &resp)
+		if resp.Message != "The submitted data is invalid" || rec.Header().Get("Content-Language") != LangEnglish {
+			t.Fatalf("Kullanıcının dil tercihi uygulanmadı: %+v", resp)
+		}
+	})
+
+	t.Run("desteklenmeyen dil tercihi reddedilir", func(t *testing.T) {
+		rec := s.request(http.MethodPut, "/api/admin/users/1", UpdateUserRequest{Language: "de"}, s.adminHeaders())
+		expectStatus(t, rec, http.StatusBadRequest)
+		var resp ErrorResponse
+		decode(t, rec, &resp)
+		if len(resp.Details) != 1 || resp.Details[0].Field != "language" || resp.Details[0].Code != RuleOneOf {
+			t.Fatalf("Dil alanı hatası beklenirdi: %+v", resp)
+		}
+	})
+}
@@ -1,24 +1,166 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
+	// Yapılandırmayı yükle ve doğrula (varsayılanlar < config.json < .env < ortam değişkenleri)
+	if err := LoadConfig(); err != nil {
+		log.Fatal(err)
+	}
+
 	// Veritabanı bağlantısını başlat
 	InitDatabase()
 
+	// Bina saat dilimini yükle
+	InitFacilityTimezone()
+
+	// Bakım komutları: go run . migrate [up|down [n]|status], go run . rebuild-summaries,
+	// go run . create-admin <kullanıcı adı> <ad soyad>
+	if len(os.Args) > 1 {
+		runCommand(os.Args[1:])
+		return
+	}
+
+	// Şemayı doğrula ve başlangıç verilerini hazırla
+	PrepareDatabase()
+
+	// QR token ve oturum imza anahtarlarını yükle
+	InitRatingTokens()
+	InitSessions()
+
+	// Bildirim sürücülerini hazırla
+	InitNotifiers()
+
+	// Arka plan işçileri kapanma sinyalinde workerCtx iptal edilerek durdurulur
+	workerCtx, cancelWorkers := context.WithCancel(context.Background())
+	defer cancelWorkers()
+
+	// Zamanlanmış yönetim raporlarını başlat
+	InitReports()
+	startReportScheduler(workerCtx)
+
+	// Webhook gönderim kuyruğunu işleyen arka plan işçisini başlat
+	startWebhookWorker(workerCtx)
+
 	// Gin router'ı oluştur
 	router := gin.Default()
 
 	// Route'ları ayarla
-	SetupRoutes(router)
+	SetupRoutes(router, NewHandlers(NewGormRepositories(DB), channelEventNotifier{}))
+
+	// Sunucuyu yapılandırılan port ve zaman aşımlarıyla başlat
+	server := &http.Server{
+		Addr:         fmt.Sprintf(":%d", config.Server.Port),
+		Handler:      router,
+		ReadTimeout:  config.Server.ReadTimeout.Duration,
+		WriteTimeout: config.Server.WriteTimeout.Duration,
+		IdleTimeout:  config.Server.IdleTimeout.Duration,
+	}
+	log.Printf("Server %d portunda başlatılıyor...", config.Server.Port)
+	if err := runServer(server, cancelWorkers); err != nil {
+		log.Fatal("Server hatası: ", err)
+	}
+	log.Println("Server durduruldu")
+}
+
+// runCommand sunucu yerine verilen bakım komutunu çalıştırır
+func runCommand(args []string) {
+	switch args[0] {
+	case "migrate":
+		runMigrateCommand(args[1:])
+	case "rebuild-summaries":
+		requireMigratedSchema()
+		start := time.Now()
+		count, err := rebuildRatingSummaries(DB)
+		if err != nil {
+			log.Fatal("Puanlama özetleri yeniden oluşturulamadı: ", err)
+		}
+		log.Printf("%d tuvalet için puanlama özeti yeniden oluşturuldu (%s)", count, time.Since(start).Round(time.Millisecond))
+	case "create-admin":
+		runCreateAdminCommand(args[1:])
+	default:
+		log.Fatalf("Bilinmeyen komut: %s (kullanılabilir: migrate, rebuild-summaries, create-admin)", args[0])
+	}
+}
+
+// runCreateAdminCommand ilk admin hesabını oluşturur; şifre komut geçmişinde kalmaması için ADMIN_PASSWORD'den okunur
+func runCreateAdminCommand(args []string) {
+	if len(args) != 2 {
+		log.Fatal("Kullanım: create-admin <kullanıcı adı> <ad soyad> (şifre ADMIN_PASSWORD ortam değişkeninden okunur)")
+	}
+	password := os.Getenv("ADMIN_PASSWORD")
+	if password == "" {
+		log.Fatal("ADMIN_PASSWORD ortam değişkeni boş")
+	}
+
+	requireMigratedSchema()
+	admin, err := createAdmin(NewGormRepositories(DB).Users, args[0], password, args[1])
+	if err != nil {
+		log.Fatal("Admin oluşturulamadı: ", err)
+	}
+	log.Printf("Admin oluşturuldu: %s (ID %d)", admin.Username, admin.ID)
+}
+
+// runMigrateCommand şema migration'larını uygular, geri alır veya durumunu gösterir
+func runMigrateCommand(args []string) {
+	action := "up"
+	if len(args) > 0 {
+		action = args[0]
+	}
+
+	switch action {
+	case "up":
+		applied, err := migrateUp(DB)
+		for _, m := range applied {
+			log.Printf("Migration uygulandı: %d %s", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Şema güncel (sürüm %d)", latestSchemaVersion())
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				log.Fatalf("Geçersiz adım sayısı: %s", args[1])
+			}
+			steps = n
+		}
+		reverted, err := migrateDown(DB, steps)
+		for _, m := range reverted {
+			log.Printf("Migration geri alındı: %d %s", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+
+	case "status":
+		current, err := currentSchemaVersion(DB)
+		if err != nil {
+			log.Fatal("Şema sürümü okunamadı: ", err)
+		}
+		for _, m := range migrations {
+			state := "bekliyor"
+			if m.Version <= current {
+				state = "uygulandı"
+			}
+			log.Printf("%3d %-35s %s", m.Version, m.Name, state)
+		}
+		log.Printf("Şema sürümü: %d / %d", current, latestSchemaVersion())
 
-	// Sunucuyu 8080 portunda başlat
-	log.Println("Server 8080 portunda başlatılıyor...")
-	if err := router.Run(":8080"); err != nil {
-		log.Fatal("Server başlatılamadı: ", err)
+	default:
+		log.Fatalf("Bilinmeyen migrate komutu: %s (kullanılabilir: up, down [n], status)", action)
 	}
 }
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	log.SetOutput(io.Discard)
+	ratingTokenSecret = []byte("test-rating-token-secret")
+	sessionSecret = []byte("test-session-secret")
+	os.Exit(m.Run())
+}
+
+// testServer her test için ayrı bir SQLite veritabanı üzerinde kurulan router
+type testServer struct {
+	t      testing.TB
+	db     *gorm.DB
+	router *gin.Engine
+	admin  *User // adminHeaders ilk çağrıldığında oluşturulur
+}
+
+// newTestServer boş bir veritabanını migrate eder, ilk tuvaletleri ekler ve SetupRoutes ile router'ı kurar
+func newTestServer(t testing.TB) *testServer {
+	t.Helper()
+
+	previousDB, previousDriver := DB, dbDriver
+	dbDriver = DriverSQLite
+
+	dialector, err := openSQLite(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("SQLite açılamadı: %v", err)
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("SQLite bağlantısı başarısız: %v", err)
+	}
+	if _, err := migrateUp(db); err != nil {
+		t.Fatalf("Migration başarısız: %v", err)
+	}
+
+	DB = db
+	createInitialToilets()
+
+	t.Cleanup(func() {
+		DB, dbDriver = previousDB, previousDriver
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	router := gin.New()
+	SetupRoutes(router, NewHandlers(NewGormRepositories(db), nopEventNotifier{}))
+
+	return &testServer{t: t, db: db, router: router}
+}
+
+// request isteği router'a gönderir; body nil değilse JSON olarak kodlanır
+func (s *testServer) request(method, path string, body interface{}, headers map[string]string) *httptest.ResponseRecorder {
+	s.t.Helper()
+	return serve(s.t, s.router, method, path, body, headers)
+}
+
+// serve isteği verilen router'a gönderir ve yanıtı döner
+func serve(t testing.TB, router *gin.Engine, method, path string, body interface{}, headers map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case string:
+		reader = bytes.NewBufferString(b)
+	default:
+		encoded, err := json.Marshal(b)
+		if err != nil {
+			t.Fatalf("İstek gövdesi kodlanamadı: %v", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if reader != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+// decode yanıt gövdesini v'ye çözer
+func decode(t testing.TB, rec *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("Yanıt çözülemedi: %v (gövde: %s)", err, rec.Body.String())
+	}
+}
+
+// expectStatus yanıt kodunu kontrol eder
+func expectStatus(t testing.TB, rec *httptest.ResponseRecorder, want int) {
+	t.Helper()
+	if rec.Code != want {
+		t.Fatalf("Durum kodu %d, beklenen %d (gövde: %s)", rec.Code, want, rec.Body.String())
+	}
+}
+
+// createUser admin endpoint'i üzerinden kullanıcı oluşturur
+func (s *testServer) createUser(username, name, role string) User {
+	s.t.Helper()
+
+	rec := s.request(http.MethodPost, "/api/admin/users", CreateUserRequest{
+		Username: username,
+		Password: "parola-" + username,
+		Name:     name,
+		Role:     role,
+	}, s.adminHeaders())
+	expectStatus(s.t, rec, http.StatusCreated)
+
+	var resp UserResponse
+	decode(s.t, rec, &resp)
+	return s.loadUser(resp.User.ID)
+}
+
+// loadUser kullanıcıyı yanıtlarda gizlenen şifre hash'iyle birlikte okur; oturum tokenı bu hash'i de imzalar
+func (s *testServer) loadUser(id uint) User {
+	s.t.Helper()
+
+	var user User
+	if err := s.db.First(&user, id).Error; err != nil {
+		s.t.Fatalf("Kullanıcı %d okunamadı: %v", id, err)
+	}
+	return user
+}
+
+// staffHeaders giriş yapmış personelin gönderdiği başlıkları döner
+func staffHeaders(user User) map[string]string {
+	return map[string]string{
+		"Authorization": "Bearer " + userToken(user),
+		"X-User-ID":     strconv.FormatUint(uint64(user.ID), 10),
+		"X-User-Name":   user.Name,
+	}
+}
+
+// adminHeaders sadece admin erişimine açık route'lar için giriş yapmış bir adminin başlıklarını döner;
+// admin, create-admin komutundaki gibi HTTP dışından oluşturulur
+func (s *testServer) adminHeaders() map[string]string {
+	s.t.Helper()
+
+	if s.admin == nil {
+		admin, err := createAdmin(NewGormRepositories(s.db).Users, "yonetici", "parola-yonetici", "Yönetici")
+		if err != nil {
+			s.t.Fatalf("Admin oluşturulamadı: %v", err)
+		}
+		s.admin = &admin
+	}
+	return staffHeaders(*s.admin)
+}
+
+// ratingToken tuvaletin güncel QR tokenını döner
+func (s *testServer) ratingToken(toiletID int) string {
+	s.t.Helper()
+
+	var toilet Toilet
+	if err := s.db.First(&toilet, toiletID).Error; err != nil {
+		s.t.Fatalf("Tuvalet %d bulunamadı: %v", toiletID, err)
+	}
+	return toiletRatingToken(toilet)
+}
+
+// insertTask görevi HTTP akışı dışında, verilen zamanlarla doğrudan veritabanına ekler
+func (s *testServer) insertTask(cleaner User, toiletID int, status string, startedAt, completedAt *time.Time) CleaningTask {
+	s.t.Helper()
+
+	task := CleaningTask{
+		ToiletID:    toiletID,
+		CleanerID:   cleaner.ID,
+		CleanerName: cleaner.Name,
+		Status:      status,
+		StartedAt:   startedAt,
+		CompletedAt: completedAt,
+	}
+	if err := s.db.Create(&task).Error; err != nil {
+		s.t.Fatalf("Görev eklenemedi: %v", err)
+	}
+	return task
+}
+
+// setFacilityTimezone test süresince bina saat dilimini değiştirir
+func setFacilityTimezone(t *testing.T, name string) *time.Location {
+	t.Helper()
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("Saat dilimi yüklenemedi: %v", err)
+	}
+
+	previous := facilityLocation
+	facilityLocation = loc
+	t.Cleanup(func() { facilityLocation = previous })
+	return loc
+}
+
+// timePtr zaman değerinin adresini döner
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
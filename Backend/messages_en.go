@@ -0,0 +1,95 @@
+package main
+
+// englishCatalog yabancı ziyaretçiler ve Türkçe bilmeyen personel için İngilizce metinler
+var englishCatalog = messageCatalog{
+	Errors: map[ErrorCode]string{
+		CodeInvalidRequest:           "Invalid request format",
+		CodeValidationFailed:         "The submitted data is invalid",
+		CodeInvalidCredentials:       "Incorrect username or password",
+		CodeUnauthorized:             "Your session is missing or invalid, please sign in again",
+		CodeForbidden:                "This action requires administrator access",
+		CodeRatingTokenRequired:      "Rating code is missing",
+		CodeRatingTokenInvalid:       "This QR code is not valid, please inform the staff",
+		CodeRatingTokenRevoked:       "This QR code is no longer valid, please inform the staff",
+		CodeRatingScanRequired:       "Please scan the QR code to leave a rating",
+		CodeRatingNotFound:           "Rating not found",
+		CodeToiletNotFound:           "Toilet not found",
+		CodeNoToiletsOnFloor:         "No active toilets found on this floor",
+		CodeTaskNotFound:             "Cleaning task not found",
+		CodeTaskAlreadyActive:        "This toilet already has an active cleaning task",
+		CodeUserNotFound:             "User not found",
+		CodeCleanerNotFound:          "No active cleaner found",
+		CodeUsernameTaken:            "This username is already taken",
+		CodeReportNotFound:           "Report not found",
+		CodeReportFileMissing:        "Report file not found, please generate the report again",
+		CodeFutureReportPeriod:       "Reports can only be generated for completed periods",
+		CodeRangeTooLarge:            "The date range is too wide, please choose a larger bucket",
+		CodeWebhookNotFound:          "Webhook subscription not found",
+		CodeWebhookDeliveryNotFound:  "Webhook delivery not found",
+		CodeWebhookDeliveryDelivered: "This delivery has already succeeded",
+		CodePushNotConfigured:        "Web Push is not configured",
+		CodePushEndpointTaken:        "This device is registered for another user, who must disable notifications first",
+		CodeVAPIDManagedByEnv:        "VAPID keys are set in the configuration and cannot be changed here",
+		CodeInternal:                 "An unexpected error occurred, please try again later",
+	},
+	Messages: map[MessageCode]string{
+		MsgLoginSucceeded:           "Signed in successfully",
+		MsgRatingCreated:            "Rating saved successfully",
+		MsgRatingsFetched:           "Ratings fetched successfully",
+		MsgProblemTypesFetched:      "Problem types fetched successfully",
+		MsgTaskCreated:              "Cleaning task created successfully",
+		MsgTaskStarted:              "Cleaning task started",
+		MsgTaskCompleted:            "Cleaning task completed successfully",
+		MsgTaskAssigned:             "Cleaning task assigned successfully",
+		MsgUsersFetched:             "Users fetched successfully",
+		MsgUserCreated:              "User created successfully",
+		MsgUserUpdated:              "User updated successfully",
+		MsgUserDeleted:              "User deleted successfully",
+		MsgStatsFetched:             "Statistics fetched successfully",
+		MsgAnalyticsFetched:         "Analytics fetched successfully",
+		MsgHeatmapFetched:           "Problem heatmap fetched successfully",
+		MsgPerformanceFetched:       "Performance report fetched successfully",
+		MsgPreferencesFetched:       "Notification preferences fetched successfully",
+		MsgPreferencesUpdated:       "Notification preferences updated successfully",
+		MsgTokenFetched:             "Token fetched successfully",
+		MsgTokenRotated:             "Token rotated, please print the new QR code",
+		MsgTokenRevoked:             "Token revoked",
+		MsgReportsFetched:           "Reports fetched successfully",
+		MsgReportCreated:            "Report generated successfully",
+		MsgReportEmailed:            "Report generated and sent by email",
+		MsgReportEmailFailed:        "Report generated but the email could not be sent",
+		MsgWebhookCreated:           "Webhook subscription created successfully",
+		MsgWebhookUpdated:           "Webhook subscription updated successfully",
+		MsgWebhookDeleted:           "Webhook subscription deleted successfully",
+		MsgWebhookDeliveriesFetched: "Webhook deliveries fetched successfully",
+		MsgWebhookTestSent:          "Test delivery sent",
+		MsgWebhookDeliveryRequeued:  "Webhook delivery queued for retry",
+		MsgPushSubscribed:           "Notifications enabled for this device",
+		MsgPushUnsubscribed:         "Notifications disabled for this device",
+		MsgVAPIDRotated:             "VAPID keys rotated, devices need to subscribe again",
+	},
+	Fields: map[string]string{
+		RuleRequired:      "This field is required",
+		RuleMin:           "Must be at least {param}",
+		RuleMax:           "Must be at most {param}",
+		RuleOneOf:         "Must be one of: {param}",
+		RuleRange:         "Must be within {param}",
+		RuleBefore:        "Must be before {param}",
+		RuleURL:           "Must be a valid http or https URL",
+		RuleEmail:         "Must be a valid email address",
+		RulePhone:         "Must be a phone number in international format (e.g. +905551112233)",
+		RulePublicHost:    "Must not point to a local or private network address",
+		RuleInvalidFormat: "Invalid format",
+		RuleInvalidType:   "Invalid data type",
+		RuleUnknownValue:  "Unknown value: {param}",
+	},
+	Notifications: map[NotificationText]string{
+		NoteBadRatingSubject:    "Cleaning alert: %s",
+		NoteBadRatingBody:       "%s was rated %d/5.",
+		NoteBadRatingProblems:   " Problems: %s",
+		NoteTaskAssignedSubject: "New cleaning task: %s",
+		NoteTaskAssignedBody:    "You have been assigned to clean %s.",
+		NoteUrgentProblem:       " Urgent problem reported: %s",
+	},
+	Weekdays: []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"},
+}
@@ -0,0 +1,95 @@
+package main
+
+// turkishCatalog varsayılan dil; her anahtar burada tanımlı olmalı
+var turkishCatalog = messageCatalog{
+	Errors: map[ErrorCode]string{
+		CodeInvalidRequest:           "Geçersiz veri formatı",
+		CodeValidationFailed:         "Gönderilen veriler geçersiz",
+		CodeInvalidCredentials:       "Kullanıcı adı veya şifre hatalı",
+		CodeUnauthorized:             "Oturum bilgisi eksik veya geçersiz, lütfen tekrar giriş yapın",
+		CodeForbidden:                "Bu işlem için yönetici yetkisi gerekiyor",
+		CodeRatingTokenRequired:      "Değerlendirme kodu eksik",
+		CodeRatingTokenInvalid:       "Bu QR kod geçerli değil, lütfen görevliye bildirin",
+		CodeRatingTokenRevoked:       "Bu QR kod artık geçerli değil, lütfen görevliye bildirin",
+		CodeRatingScanRequired:       "Değerlendirme için QR kodu okutmanız gerekiyor",
+		CodeRatingNotFound:           "Puanlama bulunamadı",
+		CodeToiletNotFound:           "Tuvalet bulunamadı",
+		CodeNoToiletsOnFloor:         "Bu kat için aktif tuvalet bulunamadı",
+		CodeTaskNotFound:             "Temizlik görevi bulunamadı",
+		CodeTaskAlreadyActive:        "Bu tuvalet için zaten aktif bir temizlik görevi var",
+		CodeUserNotFound:             "Kullanıcı bulunamadı",
+		CodeCleanerNotFound:          "Aktif temizlikçi bulunamadı",
+		CodeUsernameTaken:            "Bu kullanıcı adı zaten kullanılıyor",
+		CodeReportNotFound:           "Rapor bulunamadı",
+		CodeReportFileMissing:        "Rapor dosyası bulunamadı, raporu yeniden oluşturun",
+		CodeFutureReportPeriod:       "Rapor sadece tamamlanmış dönemler için oluşturulabilir",
+		CodeRangeTooLarge:            "Tarih aralığı çok geniş, daha büyük bir aralık birimi seçin",
+		CodeWebhookNotFound:          "Webhook aboneliği bulunamadı",
+		CodeWebhookDeliveryNotFound:  "Webhook gönderimi bulunamadı",
+		CodeWebhookDeliveryDelivered: "Bu gönderim zaten başarıyla iletildi",
+		CodePushNotConfigured:        "Web Push yapılandırılmamış",
+		CodePushEndpointTaken:        "Bu cihaz başka bir kullanıcı için kayıtlı, önce o kullanıcı bildirimleri kapatmalı",
+		CodeVAPIDManagedByEnv:        "VAPID anahtarları yapılandırmada tanımlı, buradan değiştirilemez",
+		CodeInternal:                 "Beklenmeyen bir hata oluştu, lütfen daha sonra tekrar deneyin",
+	},
+	Messages: map[MessageCode]string{
+		MsgLoginSucceeded:           "Giriş başarılı",
+		MsgRatingCreated:            "Puanlama başarıyla kaydedildi",
+		MsgRatingsFetched:           "Değerlendirmeler başarıyla getirildi",
+		MsgProblemTypesFetched:      "Problem türleri başarıyla getirildi",
+		MsgTaskCreated:              "Temizlik görevi başarıyla oluşturuldu",
+		MsgTaskStarted:              "Temizlik görevi başlatıldı",
+		MsgTaskCompleted:            "Temizlik görevi başarıyla tamamlandı",
+		MsgTaskAssigned:             "Temizlik görevi başarıyla atandı",
+		MsgUsersFetched:             "Kullanıcılar başarıyla getirildi",
+		MsgUserCreated:              "Kullanıcı başarıyla oluşturuldu",
+		MsgUserUpdated:              "Kullanıcı başarıyla güncellendi",
+		MsgUserDeleted:              "Kullanıcı başarıyla silindi",
+		MsgStatsFetched:             "İstatistikler başarıyla getirildi",
+		MsgAnalyticsFetched:         "Analiz verileri başarıyla getirildi",
+		MsgHeatmapFetched:           "Problem ısı haritası başarıyla getirildi",
+		MsgPerformanceFetched:       "Performans raporu başarıyla getirildi",
+		MsgPreferencesFetched:       "Bildirim tercihleri başarıyla getirildi",
+		MsgPreferencesUpdated:       "Bildirim tercihleri başarıyla güncellendi",
+		MsgTokenFetched:             "Token başarıyla getirildi",
+		MsgTokenRotated:             "Token yenilendi, yeni QR kodu yazdırın",
+		MsgTokenRevoked:             "Token iptal edildi",
+		MsgReportsFetched:           "Raporlar başarıyla getirildi",
+		MsgReportCreated:            "Rapor başarıyla oluşturuldu",
+		MsgReportEmailed:            "Rapor oluşturuldu ve e-posta ile gönderildi",
+		MsgReportEmailFailed:        "Rapor oluşturuldu ancak e-posta gönderilemedi",
+		MsgWebhookCreated:           "Webhook aboneliği başarıyla oluşturuldu",
+		MsgWebhookUpdated:           "Webhook aboneliği başarıyla güncellendi",
+		MsgWebhookDeleted:           "Webhook aboneliği başarıyla silindi",
+		MsgWebhookDeliveriesFetched: "Webhook gönderimleri başarıyla getirildi",
+		MsgWebhookTestSent:          "Deneme gönderimi yapıldı",
+		MsgWebhookDeliveryRequeued:  "Webhook gönderimi tekrar kuyruğa alındı",
+		MsgPushSubscribed:           "Bildirimler bu cihaz için açıldı",
+		MsgPushUnsubscribed:         "Bildirimler bu cihaz için kapatıldı",
+		MsgVAPIDRotated:             "VAPID anahtarları yenilendi, cihazların tekrar abone olması gerekiyor",
+	},
+	Fields: map[string]string{
+		RuleRequired:      "Bu alan zorunludur",
+		RuleMin:           "En az {param} olmalı",
+		RuleMax:           "En fazla {param} olmalı",
+		RuleOneOf:         "Şu değerlerden biri olmalı: {param}",
+		RuleRange:         "{param} aralığında olmalı",
+		RuleBefore:        "{param} değerinden önce olmalı",
+		RuleURL:           "Geçerli bir http veya https adresi olmalı",
+		RuleEmail:         "Geçerli bir e-posta adresi olmalı",
+		RulePhone:         "Uluslararası formatta telefon numarası olmalı (ör. +905551112233)",
+		RulePublicHost:    "Yerel veya özel ağdaki adreslere gönderim yapılamaz",
+		RuleInvalidFormat: "Geçersiz format",
+		RuleInvalidType:   "Geçersiz veri tipi",
+		RuleUnknownValue:  "Bilinmeyen değer: {param}",
+	},
+	Notifications: map[NotificationText]string{
+		NoteBadRatingSubject:    "Temizlik uyarısı: %s",
+		NoteBadRatingBody:       "%s için %d/5 puan verildi.",
+		NoteBadRatingProblems:   " Sorunlar: %s",
+		NoteTaskAssignedSubject: "Yeni temizlik görevi: %s",
+		NoteTaskAssignedBody:    "%s için temizlik görevi size atandı.",
+		NoteUrgentProblem:       " Acil sorun bildirildi: %s",
+	},
+	Weekdays: []string{"Pazartesi", "Salı", "Çarşamba", "Perşembe", "Cuma", "Cumartesi", "Pazar"},
+}
@@ -0,0 +1,458 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migration numaralı, geri alınabilir bir şema değişikliği.
+// Migration'lar modellerin o anki halini değil, aşağıdaki dondurulmuş struct'ları kullanır;
+// böylece modeller değişse de eski migration'lar aynı şemayı üretir.
+type migration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// migrations sırayla uygulanan tüm şema değişiklikleri; yeni migration'lar sona eklenir, mevcutlar değiştirilmez
+var migrations = []migration{
+	{
+		Version: 1,
+		Name:    "create_core_tables",
+		Up: func(tx *gorm.DB) error {
+			return createTablesIfMissing(tx, &userV1{}, &ratingV1{}, &toiletV1{}, &cleaningTaskV1{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&cleaningTaskV1{}, &toiletV1{}, &ratingV1{}, &userV1{})
+		},
+	},
+	{
+		Version: 2,
+		Name:    "create_webhook_tables",
+		Up: func(tx *gorm.DB) error {
+			return createTablesIfMissing(tx, &webhookSubscriptionV2{}, &webhookDeliveryV2{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&webhookDeliveryV2{}, &webhookSubscriptionV2{})
+		},
+	},
+	{
+		Version: 3,
+		Name:    "create_notification_preferences",
+		Up: func(tx *gorm.DB) error {
+			return createTablesIfMissing(tx, &notificationPreferenceV3{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&notificationPreferenceV3{})
+		},
+	},
+	{
+		Version: 4,
+		Name:    "create_push_tables",
+		Up: func(tx *gorm.DB) error {
+			return createTablesIfMissing(tx, &vapidKeyV4{}, &pushSubscriptionV4{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&pushSubscriptionV4{}, &vapidKeyV4{})
+		},
+	},
+	{
+		Version: 5,
+		Name:    "add_toilet_rating_token",
+		Up: func(tx *gorm.DB) error {
+			return addColumnsIfMissing(tx, &toiletV5{}, "TokenVersion", "TokenRevokedAt")
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropColumnsIfPresent(tx, &toiletV5{}, "TokenRevokedAt", "TokenVersion")
+		},
+	},
+	{
+		Version: 6,
+		Name:    "create_toilet_rating_summary",
+		Up: func(tx *gorm.DB) error {
+			return createTablesIfMissing(tx, &toiletRatingSummaryV6{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&toiletRatingSummaryV6{})
+		},
+	},
+	{
+		Version: 7,
+		Name:    "create_management_reports",
+		Up: func(tx *gorm.DB) error {
+			return createTablesIfMissing(tx, &managementReportV7{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&managementReportV7{})
+		},
+	},
+	{
+		Version: 8,
+		Name:    "add_user_language",
+		Up: func(tx *gorm.DB) error {
+			return addColumnsIfMissing(tx, &userV8{}, "Language")
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropColumnsIfPresent(tx, &userV8{}, "Language")
+		},
+	},
+	{
+		Version: 9,
+		Name:    "add_toilet_building",
+		Up: func(tx *gorm.DB) error {
+			return addColumnsIfMissing(tx, &toiletV9{}, "Building")
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropColumnsIfPresent(tx, &toiletV9{}, "Building")
+		},
+	},
+}
+
+// latestSchemaVersion kodun beklediği şema sürümünü döner
+func latestSchemaVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// migrationTx MySQL'de tabloların utf8mb4 ile oluşturulmasını sağlar
+func migrationTx(tx *gorm.DB) *gorm.DB {
+	if dbDriver == DriverMySQL {
+		return tx.Set("gorm:table_options", "ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci")
+	}
+	return tx
+}
+
+// createTablesIfMissing tabloları yoksa oluşturur.
+// AutoMigrate ile kurulmuş eski veritabanlarında tablolar zaten olduğu için atlanır.
+func createTablesIfMissing(tx *gorm.DB, models ...interface{}) error {
+	for _, model := range models {
+		if tx.Migrator().HasTable(model) {
+			continue
+		}
+		if err := migrationTx(tx).Migrator().CreateTable(model); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addColumnsIfMissing kolonları yoksa ekler
+func addColumnsIfMissing(tx *gorm.DB, model interface{}, fields ...string) error {
+	for _, field := range fields {
+		if tx.Migrator().HasColumn(model, field) {
+			continue
+		}
+		if err := tx.Migrator().AddColumn(model, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropColumnsIfPresent kolonlar varsa siler
+func dropColumnsIfPresent(tx *gorm.DB, model interface{}, fields ...string) error {
+	for _, field := range fields {
+		if !tx.Migrator().HasColumn(model, field) {
+			continue
+		}
+		if err := tx.Migrator().DropColumn(model, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// currentSchemaVersion veritabanına uygulanmış son migration sürümünü döner, hiç uygulanmamışsa 0
+func currentSchemaVersion(db *gorm.DB) (int, error) {
+	if !db.Migrator().HasTable(&SchemaVersion{}) {
+		return 0, nil
+	}
+
+	var version int
+	err := db.Model(&SchemaVersion{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+	return version, err
+}
+
+// migrateUp bekleyen migration'ları sırayla uygular ve uygulananların listesini döner
+func migrateUp(db *gorm.DB) ([]migration, error) {
+	if err := createTablesIfMissing(db, &SchemaVersion{}); err != nil {
+		return nil, err
+	}
+
+	current, err := currentSchemaVersion(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []migration
+	for _, m := range migrations {
+		if m.Version <= current {
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaVersion{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return applied, fmt.Errorf("migration %d (%s) uygulanamadı: %w", m.Version, m.Name, err)
+		}
+		applied = append(applied, m)
+	}
+
+	return applied, nil
+}
+
+// migrateDown son uygulanan steps adet migration'ı geri alır
+func migrateDown(db *gorm.DB, steps int) ([]migration, error) {
+	var reverted []migration
+	for i := 0; i < steps; i++ {
+		current, err := currentSchemaVersion(db)
+		if err != nil {
+			return reverted, err
+		}
+		if current == 0 {
+			break
+		}
+
+		var m *migration
+		for j := range migrations {
+			if migrations[j].Version == current {
+				m = &migrations[j]
+				break
+			}
+		}
+		if m == nil {
+			return reverted, fmt.Errorf("veritabanındaki %d sürümlü migration kodda bulunamadı", current)
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaVersion{}, m.Version).Error
+		})
+		if err != nil {
+			return reverted, fmt.Errorf("migration %d (%s) geri alınamadı: %w", m.Version, m.Name, err)
+		}
+		reverted = append(reverted, *m)
+	}
+
+	return reverted, nil
+}
+
+// requireMigratedSchema şema sürümü kodun beklediğiyle aynı değilse sunucuyu başlatmaz
+func requireMigratedSchema() {
+	current, err := currentSchemaVersion(DB)
+	if err != nil {
+		log.Fatal("Şema sürümü okunamadı: ", err)
+	}
+
+	latest := latestSchemaVersion()
+	if current < latest {
+		log.Fatalf("Veritabanı şeması güncel değil (sürüm %d, beklenen %d). Önce \"go run . migrate\" çalıştırın", current, latest)
+	}
+	if current > latest {
+		log.Fatalf("Veritabanı şeması bu sürümden daha yeni (sürüm %d, beklenen %d)", current, latest)
+	}
+}
+
+// Aşağıdaki struct'lar migration'ların oluşturduğu şemanın dondurulmuş halidir; değiştirmeyin.
+
+// Migration 1
+type userV1 struct {
+	ID        uint   `gorm:"primaryKey"`
+	Username  string `gorm:"unique;not null"`
+	Password  string `gorm:"not null"`
+	Name      string `gorm:"not null"`
+	Role      string `gorm:"not null;default:'temizlikci'"`
+	IsActive  bool   `gorm:"default:true"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (userV1) TableName() string { return "users" }
+
+type ratingV1 struct {
+	ID        uint `gorm:"primaryKey"`
+	ToiletID  int  `gorm:"not null"`
+	Rating    int  `gorm:"not null"`
+	Problems  string
+	OtherText string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (ratingV1) TableName() string { return "ratings" }
+
+type toiletV1 struct {
+	ID        int    `gorm:"primaryKey"`
+	Name      string `gorm:"not null"`
+	Location  string `gorm:"not null"`
+	IsActive  bool   `gorm:"default:true"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (toiletV1) TableName() string { return "toilets" }
+
+type cleaningTaskV1 struct {
+	ID          uint   `gorm:"primaryKey"`
+	ToiletID    int    `gorm:"not null;index:idx_toilet_status,priority:1"`
+	CleanerID   uint   `gorm:"not null"`
+	CleanerName string `gorm:"not null;size:255"`
+	Status      string `gorm:"not null;default:'assigned';size:50;index:idx_toilet_status,priority:2"`
+	StartedAt   *time.Time
+	CompletedAt *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (cleaningTaskV1) TableName() string { return "cleaning_tasks" }
+
+// Migration 2
+type webhookSubscriptionV2 struct {
+	ID          uint   `gorm:"primaryKey"`
+	URL         string `gorm:"not null;size:1024"`
+	Secret      string `gorm:"not null;size:128"`
+	Events      string `gorm:"not null;size:512;default:'*'"`
+	Description string
+	IsActive    bool `gorm:"default:true"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (webhookSubscriptionV2) TableName() string { return "webhook_subscriptions" }
+
+type webhookDeliveryV2 struct {
+	ID             uint      `gorm:"primaryKey"`
+	SubscriptionID uint      `gorm:"not null;index"`
+	Event          string    `gorm:"not null;size:100"`
+	Payload        string    `gorm:"type:text"`
+	Status         string    `gorm:"not null;default:'pending';size:50;index"`
+	Attempts       int       `gorm:"default:0"`
+	NextAttemptAt  time.Time `gorm:"index"`
+	ResponseStatus int
+	LastError      string `gorm:"type:text"`
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func (webhookDeliveryV2) TableName() string { return "webhook_deliveries" }
+
+// Migration 3
+type notificationPreferenceV3 struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;index"`
+	Channel   string `gorm:"not null;size:20"`
+	Target    string `gorm:"size:1024"`
+	Events    string `gorm:"not null;size:512;default:'*'"`
+	IsEnabled bool   `gorm:"default:true"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (notificationPreferenceV3) TableName() string { return "notification_preferences" }
+
+// Migration 4
+type vapidKeyV4 struct {
+	ID         uint   `gorm:"primaryKey"`
+	PublicKey  string `gorm:"not null;size:255"`
+	PrivateKey string `gorm:"not null;size:255"`
+	CreatedAt  time.Time
+}
+
+func (vapidKeyV4) TableName() string { return "vapid_keys" }
+
+type pushSubscriptionV4 struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;index"`
+	Endpoint  string `gorm:"not null;size:512;uniqueIndex"`
+	P256dh    string `gorm:"not null;size:255"`
+	Auth      string `gorm:"not null;size:255"`
+	UserAgent string `gorm:"size:512"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (pushSubscriptionV4) TableName() string { return "push_subscriptions" }
+
+// Migration 5
+type toiletV5 struct {
+	ID             int    `gorm:"primaryKey"`
+	Name           string `gorm:"not null"`
+	Location       string `gorm:"not null"`
+	IsActive       bool   `gorm:"default:true"`
+	TokenVersion   int    `gorm:"not null;default:1"`
+	TokenRevokedAt *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func (toiletV5) TableName() string { return "toilets" }
+
+// Migration 6
+type toiletRatingSummaryV6 struct {
+	ToiletID      int   `gorm:"primaryKey;autoIncrement:false"`
+	RatingCount   int64 `gorm:"not null;default:0"`
+	RatingSum     int64 `gorm:"not null;default:0"`
+	LastRatingID  uint
+	LastRating    int
+	LastRatedAt   *time.Time
+	LastProblemAt *time.Time
+	ProblemCounts string `gorm:"type:text"`
+	UpdatedAt     time.Time
+}
+
+func (toiletRatingSummaryV6) TableName() string { return "toilet_rating_summary" }
+
+// Migration 7
+type managementReportV7 struct {
+	ID          uint      `gorm:"primaryKey"`
+	Period      string    `gorm:"not null;size:20;uniqueIndex:idx_management_report_period"`
+	PeriodStart time.Time `gorm:"not null;uniqueIndex:idx_management_report_period"`
+	PeriodEnd   time.Time `gorm:"not null"`
+	FileName    string    `gorm:"not null;size:255"`
+	Size        int64
+	EmailedAt   *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (managementReportV7) TableName() string { return "management_reports" }
+
+// Migration 8
+type userV8 struct {
+	ID        uint   `gorm:"primaryKey"`
+	Username  string `gorm:"unique;not null"`
+	Password  string `gorm:"not null"`
+	Name      string `gorm:"not null"`
+	Role      string `gorm:"not null;default:'temizlikci'"`
+	IsActive  bool   `gorm:"default:true"`
+	Language  string `gorm:"size:5"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (userV8) TableName() string { return "users" }
+
+// Migration 9; mevcut tuvaletler varsayılan binaya atanır
+type toiletV9 struct {
+	ID             int    `gorm:"primaryKey"`
+	Name           string `gorm:"not null"`
+	Location       string `gorm:"not null"`
+	Building       string `gorm:"not null;size:100;default:'Ana Bina'"`
+	IsActive       bool   `gorm:"default:true"`
+	TokenVersion   int    `gorm:"not null;default:1"`
+	TokenRevokedAt *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func (toiletV9) TableName() string { return "toilets" }
@@ -12,10 +12,27 @@ type User struct {
 	Name      string    `json:"name" gorm:"not null"`
 	Role      string    `json:"role" gorm:"not null;default:'temizlikci'"` // admin, temizlikci
 	IsActive  bool      `json:"is_active" gorm:"default:true"`
+	Language  string    `json:"language" gorm:"size:5"` // tr, en; boşsa tarayıcının dili kullanılır
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// ErrorResponse tüm hata yanıtlarının ortak zarfı
+type ErrorResponse struct {
+	Success bool         `json:"success"`
+	Code    ErrorCode    `json:"code"`
+	Message string       `json:"message"`
+	Details []FieldError `json:"details,omitempty"` // Alan bazlı doğrulama hataları
+}
+
+// FieldError tek bir alanın doğrulama hatası
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"` // required, min, max, oneof, invalid_format...
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
 // LoginRequest giriş isteği için struct
 type LoginRequest struct {
 	Username string `json:"username" binding:"required"`
@@ -42,8 +59,10 @@ type Rating struct {
 }
 
 // RatingRequest frontend'den gelen rating verisini temsil eden struct
+// Ziyaretçiler QR koddaki imzalı token ile, giriş yapmış personel toilet_id ile puanlar
 type RatingRequest struct {
-	ToiletID  int    `json:"toilet_id" binding:"required,min=1"`
+	Token     string `json:"token"`
+	ToiletID  int    `json:"toilet_id" binding:"omitempty,min=1"`
 	Rating    int    `json:"rating" binding:"required,min=1,max=5"`
 	Problems  []int  `json:"problems"`
 	OtherText string `json:"other_text"`
@@ -58,12 +77,15 @@ type RatingResponse struct {
 
 // Toilet tuvaletleri temsil eden model
 type Toilet struct {
-	ID        int       `json:"id" gorm:"primaryKey"`
-	Name      string    `json:"name" gorm:"not null"`
-	Location  string    `json:"location" gorm:"not null"`
-	IsActive  bool      `json:"is_active" gorm:"default:true"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID             int        `json:"id" gorm:"primaryKey"`
+	Name           string     `json:"name" gorm:"not null"`
+	Location       string     `json:"location" gorm:"not null"`
+	Building       string     `json:"building" gorm:"not null"` // Raporlarda bina bazında gruplama için
+	IsActive       bool       `json:"is_active" gorm:"default:true"`
+	TokenVersion   int        `json:"-" gorm:"not null;default:1"` // QR token sürümü, yenilendiğinde eski tokenlar geçersiz olur
+	TokenRevokedAt *time.Time `json:"-"`                           // Dolu ise QR token iptal edilmiştir
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
 }
 
 // ToiletStatus tuvalet durumu için struct
@@ -109,6 +131,7 @@ type CreateUserRequest struct {
 	Password string `json:"password" binding:"required"`
 	Name     string `json:"name" binding:"required"`
 	Role     string `json:"role"`
+	Language string `json:"language" binding:"omitempty,oneof=tr en"`
 }
 
 // UpdateUserRequest kullanıcı güncellemek için struct
@@ -117,6 +140,7 @@ type UpdateUserRequest struct {
 	Password string `json:"password"`
 	Name     string `json:"name"`
 	Role     string `json:"role"`
+	Language string `json:"language" binding:"omitempty,oneof=tr en"`
 	IsActive *bool  `json:"is_active"`
 }
 
@@ -135,18 +159,22 @@ type UsersResponse struct {
 }
 
 // CleanerStats temizlikçi istatistikleri için struct
+// Süreler saniye hassasiyetinde dakika olarak; süre ölçülebilen görev yoksa null döner
 type CleanerStats struct {
-	CleanerID           uint    `json:"cleaner_id"`
-	CleanerName         string  `json:"cleaner_name"`
-	TotalCompletedTasks int64   `json:"total_completed_tasks"`
-	AverageCleaningTime float64 `json:"average_cleaning_time"` // dakika olarak
-	LastWeekTasks       int64   `json:"last_week_tasks"`
-	LastMonthTasks      int64   `json:"last_month_tasks"`
-	IsActive            bool    `json:"is_active"`
-	OngoingTasks        int64   `json:"ongoing_tasks"`
-	FastestCleaningTime float64 `json:"fastest_cleaning_time"` // dakika olarak
-	SlowestCleaningTime float64 `json:"slowest_cleaning_time"` // dakika olarak
-	TotalCleaningTime   float64 `json:"total_cleaning_time"`   // dakika olarak
+	CleanerID           uint     `json:"cleaner_id"`
+	CleanerName         string   `json:"cleaner_name"`
+	TotalCompletedTasks int64    `json:"total_completed_tasks"`
+	AverageCleaningTime *float64 `json:"average_cleaning_time"`
+	MedianCleaningTime  *float64 `json:"median_cleaning_time"`
+	P90CleaningTime     *float64 `json:"p90_cleaning_time"`
+	LastWeekTasks       int64    `json:"last_week_tasks"`  // Son 7 günde tamamlanan (takvim haftası değil)
+	LastMonthTasks      int64    `json:"last_month_tasks"` // Son 30 günde tamamlanan (takvim ayı değil)
+	IsActive            bool     `json:"is_active"`
+	OngoingTasks        int64    `json:"ongoing_tasks"`
+	FastestCleaningTime *float64 `json:"fastest_cleaning_time"`
+	SlowestCleaningTime *float64 `json:"slowest_cleaning_time"`
+	TotalCleaningTime   float64  `json:"total_cleaning_time"`
+	ExcludedOutliers    int64    `json:"excluded_outliers"` // Aşırı uzun sürdüğü için hesaba katılmayan görevler
 }
 
 // SystemStats sistem geneli istatistikleri için struct
@@ -162,6 +190,12 @@ type SystemStats struct {
 	OngoingTasks        int64   `json:"ongoing_tasks"`
 }
 
+// HealthResponse /healthz ve /readyz yanıtı
+type HealthResponse struct {
+	Status   string `json:"status"`             // ok, unavailable, shutting_down
+	Database string `json:"database,omitempty"` // ok, unreachable, unknown
+}
+
 // StatsResponse istatistik yanıtı için struct
 type StatsResponse struct {
 	Success      bool           `json:"success"`
@@ -170,20 +204,38 @@ type StatsResponse struct {
 	CleanerStats []CleanerStats `json:"cleaner_stats,omitempty"`
 }
 
-// Problem türlerini tanımlayan sabitler
-var ProblemTypes = map[int]string{
-	1: "Tuvalet Kağıdı yok",
-	2: "Sabun yok",
-	3: "Peçete yok",
-	4: "Çöp kutusu dolu",
-	5: "Klozet kirli",
-	6: "Diğer",
+// ProblemLabels bir problem türünün dil koduna göre metinleri
+type ProblemLabels map[string]string
+
+// Problem türlerini tanımlayan sabitler. Metinler catalogs'taki dillerle (şu an tr ve en) sınırlıdır;
+// yeni bir dil önce i18n kataloğuna eklenir, desteklenmeyen dillerde varsayılan dil (tr) gösterilir.
+var ProblemTypes = map[int]ProblemLabels{
+	1: {LangTurkish: "Tuvalet Kağıdı yok", LangEnglish: "No toilet paper"},
+	2: {LangTurkish: "Sabun yok", LangEnglish: "No soap"},
+	3: {LangTurkish: "Peçete yok", LangEnglish: "No paper towels"},
+	4: {LangTurkish: "Çöp kutusu dolu", LangEnglish: "Trash bin is full"},
+	5: {LangTurkish: "Klozet kirli", LangEnglish: "Toilet bowl is dirty"},
+	6: {LangTurkish: "Diğer", LangEnglish: "Other"},
+}
+
+// ProblemTypeOption puanlama sayfasında gösterilen tek bir problem seçeneği
+type ProblemTypeOption struct {
+	ID    int    `json:"id"`
+	Label string `json:"label"`
+}
+
+// ProblemTypesResponse problem kataloğu yanıtı
+type ProblemTypesResponse struct {
+	Success      bool                `json:"success"`
+	Message      string              `json:"message"`
+	Language     string              `json:"language"`
+	ProblemTypes []ProblemTypeOption `json:"problem_types"`
 }
 
 // RatingDetail değerlendirme detayları için struct
 type RatingDetail struct {
 	Rating    `json:",inline"`
-	Problems  []string  `json:"problem_texts"`
+	Problems  []string  `json:"problem_texts"` // İsteği yapanın dilinde
 	CreatedAt time.Time `json:"created_at"`
 }
 
@@ -200,3 +252,269 @@ type PaginatedRatingsResponse struct {
 	HasNext     bool           `json:"has_next"`
 	HasPrevious bool           `json:"has_previous"`
 }
+
+// WebhookSubscription dış sistemlere olay bildirimi gönderilecek webhook aboneliği
+type WebhookSubscription struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	URL         string    `json:"url" gorm:"not null;size:1024"`
+	Secret      string    `json:"-" gorm:"not null;size:128"`                  // HMAC imzası için, JSON'da gizli
+	Events      string    `json:"events" gorm:"not null;size:512;default:'*'"` // Virgülle ayrılmış olay listesi, "*" tüm olaylar
+	Description string    `json:"description"`
+	IsActive    bool      `json:"is_active" gorm:"not null"` // default etiketi yok: GORM false değerini atlayıp sütunun varsayılanını (true) yazardı
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// WebhookDelivery webhook gönderim kuyruğundaki bir kaydı temsil eden model
+type WebhookDelivery struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	SubscriptionID uint       `json:"subscription_id" gorm:"not null;index"`
+	Event          string     `json:"event" gorm:"not null;size:100"`
+	Payload        string     `json:"payload" gorm:"type:text"`
+	Status         string     `json:"status" gorm:"not null;default:'pending';size:50;index"` // pending, delivered, failed
+	Attempts       int        `json:"attempts" gorm:"default:0"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" gorm:"index"`
+	ResponseStatus int        `json:"response_status"`
+	LastError      string     `json:"last_error" gorm:"type:text"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// WebhookPayload webhook isteğinin gövdesi
+type WebhookPayload struct {
+	Event      string      `json:"event"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// WebhookSubscriptionRequest webhook aboneliği oluşturmak/güncellemek için struct
+type WebhookSubscriptionRequest struct {
+	URL         string   `json:"url"`
+	Secret      string   `json:"secret"`
+	Events      []string `json:"events"`
+	Description string   `json:"description"`
+	IsActive    *bool    `json:"is_active"`
+}
+
+// WebhookSubscriptionResponse webhook aboneliği yanıtı için struct
+type WebhookSubscriptionResponse struct {
+	Success      bool                 `json:"success"`
+	Message      string               `json:"message"`
+	Subscription *WebhookSubscription `json:"subscription,omitempty"`
+	Secret       string               `json:"secret,omitempty"` // Sadece oluşturma sırasında döner
+}
+
+// WebhookDeliveriesResponse webhook gönderim kayıtları yanıtı için struct
+type WebhookDeliveriesResponse struct {
+	Success    bool              `json:"success"`
+	Message    string            `json:"message"`
+	Deliveries []WebhookDelivery `json:"deliveries"`
+	Page       int               `json:"page"`
+	Limit      int               `json:"limit"`
+	TotalCount int               `json:"total_count"`
+}
+
+// NotificationPreference kullanıcının bir kanal için bildirim tercihi
+type NotificationPreference struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;index"`
+	Channel   string    `json:"channel" gorm:"not null;size:20"`             // email, sms, push, log
+	Target    string    `json:"target" gorm:"size:1024"`                     // E-posta adresi, telefon numarası veya push aboneliği
+	Events    string    `json:"events" gorm:"not null;size:512;default:'*'"` // Virgülle ayrılmış olay listesi, "*" tüm olaylar
+	IsEnabled bool      `json:"is_enabled" gorm:"not null"`                  // default etiketi yok: GORM false değerini atlayıp sütunun varsayılanını (true) yazardı
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NotificationPreferenceInput tek bir kanal tercihi için istek verisi
+type NotificationPreferenceInput struct {
+	Channel   string   `json:"channel" binding:"required"`
+	Target    string   `json:"target"`
+	Events    []string `json:"events"`
+	IsEnabled *bool    `json:"is_enabled"`
+}
+
+// NotificationPreferencesRequest kullanıcının tüm bildirim tercihlerini güncellemek için struct
+type NotificationPreferencesRequest struct {
+	Preferences []NotificationPreferenceInput `json:"preferences" binding:"dive"`
+}
+
+// NotificationPreferencesResponse bildirim tercihleri yanıtı için struct
+type NotificationPreferencesResponse struct {
+	Success     bool                     `json:"success"`
+	Message     string                   `json:"message"`
+	Preferences []NotificationPreference `json:"preferences"`
+}
+
+// VAPIDKey Web Push için sunucu anahtar çiftini temsil eden model (en son kayıt aktiftir)
+type VAPIDKey struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	PublicKey  string    `json:"public_key" gorm:"not null;size:255"`
+	PrivateKey string    `json:"-" gorm:"not null;size:255"` // JSON'da gizli
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName VAPID tablosunun adını belirler; GORM'un isimlendirmesi "v_api_d_keys" üretirdi
+func (VAPIDKey) TableName() string {
+	return "vapid_keys"
+}
+
+// PushSubscription kullanıcının bir cihazdaki Web Push aboneliği
+type PushSubscription struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;index"`
+	Endpoint  string    `json:"endpoint" gorm:"not null;size:512;uniqueIndex"`
+	P256dh    string    `json:"p256dh" gorm:"not null;size:255"`
+	Auth      string    `json:"auth" gorm:"not null;size:255"`
+	UserAgent string    `json:"user_agent" gorm:"size:512"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PushSubscriptionRequest tarayıcının PushSubscription.toJSON() çıktısı
+type PushSubscriptionRequest struct {
+	Endpoint string `json:"endpoint" binding:"required"`
+	Keys     struct {
+		P256dh string `json:"p256dh" binding:"required"`
+		Auth   string `json:"auth" binding:"required"`
+	} `json:"keys"`
+}
+
+// AssignTaskRequest admin tarafından temizlik görevi atamak için struct
+type AssignTaskRequest struct {
+	ToiletID  int  `json:"toilet_id" binding:"required,min=1"`
+	CleanerID uint `json:"cleaner_id" binding:"required,min=1"`
+}
+
+// RatingTokenResponse tuvalet QR token yanıtı için struct
+type RatingTokenResponse struct {
+	Success   bool       `json:"success"`
+	Message   string     `json:"message"`
+	ToiletID  int        `json:"toilet_id,omitempty"`
+	Token     string     `json:"token,omitempty"`
+	URL       string     `json:"url,omitempty"`
+	Version   int        `json:"version,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// ToiletRatingSummary tuvalet başına önceden hesaplanmış puanlama özeti.
+// createRating içinde transaction ile güncellenir, "rebuild-summaries" komutu ile geçmişten yeniden oluşturulabilir.
+type ToiletRatingSummary struct {
+	ToiletID      int        `json:"toilet_id" gorm:"primaryKey;autoIncrement:false"`
+	RatingCount   int64      `json:"rating_count" gorm:"not null;default:0"`
+	RatingSum     int64      `json:"rating_sum" gorm:"not null;default:0"`
+	LastRatingID  uint       `json:"last_rating_id"`
+	LastRating    int        `json:"last_rating"`
+	LastRatedAt   *time.Time `json:"last_rated_at"`
+	LastProblemAt *time.Time `json:"last_problem_at"`
+	ProblemCounts string     `json:"problem_counts" gorm:"type:text"` // JSON olarak problem ID'si -> bildirilme sayısı
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName özet tablosunun adını belirler
+func (ToiletRatingSummary) TableName() string {
+	return "toilet_rating_summary"
+}
+
+// AnalyticsBucket zaman serisindeki tek bir aralığın değerleri
+type AnalyticsBucket struct {
+	Start                 time.Time   `json:"start"`
+	RatingCount           int         `json:"rating_count"`
+	AverageRating         *float64    `json:"average_rating"` // Puanlama yoksa null
+	ProblemCount          int         `json:"problem_count"`
+	ProblemsByType        map[int]int `json:"problems_by_type"`
+	TasksCompleted        int         `json:"tasks_completed"`
+	MedianCleaningMinutes *float64    `json:"median_cleaning_minutes"` // Süresi ölçülen görev yoksa null
+}
+
+// AnalyticsResponse zaman serisi analiz yanıtı için struct
+type AnalyticsResponse struct {
+	Success      bool              `json:"success"`
+	Message      string            `json:"message"`
+	Bucket       string            `json:"bucket,omitempty"`
+	From         *time.Time        `json:"from,omitempty"`
+	To           *time.Time        `json:"to,omitempty"`
+	ProblemTypes map[int]string    `json:"problem_types,omitempty"`
+	Series       []AnalyticsBucket `json:"series,omitempty"`
+}
+
+// HeatmapResponse problem bildirimlerinin gün/saat dağılımı yanıtı için struct.
+// Matrix[0] pazartesi, Matrix[6] pazar; her satırda 24 saatlik sayım bulunur.
+type HeatmapResponse struct {
+	Success     bool       `json:"success"`
+	Message     string     `json:"message"`
+	From        *time.Time `json:"from,omitempty"`
+	To          *time.Time `json:"to,omitempty"`
+	ProblemType int        `json:"problem_type,omitempty"`
+	Total       int        `json:"total"`
+	Max         int        `json:"max"`
+	Weekdays    []string   `json:"weekdays,omitempty"`
+	Matrix      [][]int    `json:"matrix,omitempty"`
+}
+
+// CleanerPerformance temizlikçinin müdahale hızı ve temizlik kalitesi metrikleri
+// Süreler dakika olarak; ölçülebilecek veri yoksa null döner
+type CleanerPerformance struct {
+	CleanerID                 uint     `json:"cleaner_id"`
+	CleanerName               string   `json:"cleaner_name"`
+	TasksCompleted            int      `json:"tasks_completed"`
+	ProblemTasks              int      `json:"problem_tasks"` // Bir problem bildirimine karşılık gelen görevler
+	AverageResponseMinutes    *float64 `json:"average_response_minutes"`
+	MedianResponseMinutes     *float64 `json:"median_response_minutes"`
+	AverageResolveMinutes     *float64 `json:"average_resolve_minutes"`
+	MedianResolveMinutes      *float64 `json:"median_resolve_minutes"`
+	ResolvedWithinSLA         int      `json:"resolved_within_sla"` // Hedef süre içinde çözülen problem görevleri
+	PostCleaningRatingCount   int      `json:"post_cleaning_rating_count"`
+	PostCleaningAverageRating *float64 `json:"post_cleaning_average_rating"`
+}
+
+// CleanerPerformanceResponse temizlikçi performans raporu yanıtı için struct
+type CleanerPerformanceResponse struct {
+	Success     bool                 `json:"success"`
+	Message     string               `json:"message"`
+	From        *time.Time           `json:"from,omitempty"`
+	To          *time.Time           `json:"to,omitempty"`
+	WindowHours int                  `json:"window_hours,omitempty"`
+	Cleaners    []CleanerPerformance `json:"cleaners,omitempty"`
+}
+
+// ManagementReport zamanlanmış yönetim raporu kaydı; PDF dosyası REPORT_DIR altında saklanır
+type ManagementReport struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	Period      string     `json:"period" gorm:"not null;size:20;uniqueIndex:idx_management_report_period"` // weekly, monthly
+	PeriodStart time.Time  `json:"period_start" gorm:"not null;uniqueIndex:idx_management_report_period"`
+	PeriodEnd   time.Time  `json:"period_end" gorm:"not null"`
+	FileName    string     `json:"file_name" gorm:"not null;size:255"`
+	Size        int64      `json:"size"`
+	EmailedAt   *time.Time `json:"emailed_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// ManagementReportRequest elle rapor oluşturma isteği için struct
+type ManagementReportRequest struct {
+	Period      string `json:"period" binding:"required,oneof=weekly monthly"`
+	PeriodStart string `json:"period_start"` // Boşsa son tamamlanan dönem, "2006-01-02" formatında
+	SendEmail   bool   `json:"send_email"`
+}
+
+// ManagementReportsResponse yönetim raporları yanıtı için struct
+type ManagementReportsResponse struct {
+	Success bool               `json:"success"`
+	Message string             `json:"message"`
+	Data    []ManagementReport `json:"data,omitempty"`
+	Report  *ManagementReport  `json:"report,omitempty"`
+}
+
+// SchemaVersion uygulanmış şema migration'larının kaydı
+type SchemaVersion struct {
+	Version   int       `json:"version" gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `json:"name" gorm:"not null;size:255"`
+	AppliedAt time.Time `json:"applied_at" gorm:"not null"`
+}
+
+// TableName şema sürümü tablosunun adını belirler
+func (SchemaVersion) TableName() string {
+	return "schema_version"
+}
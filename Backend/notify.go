@@ -0,0 +1,629 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	webpush "github.com/SherClockHolmes/webpush-go"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Bildirim kanalları
+const (
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+	ChannelPush  = "push"
+	ChannelLog   = "log"
+)
+
+// Bildirim olayları
+const (
+	NotifyBadRating    = "rating.bad"
+	NotifyTaskAssigned = "task.assigned"
+)
+
+// NotificationChannels desteklenen bildirim kanalları
+var NotificationChannels = []string{ChannelEmail, ChannelSMS, ChannelPush, ChannelLog}
+
+// NotificationEvents kullanıcıların tercih edebileceği bildirim olayları
+var NotificationEvents = []string{NotifyBadRating, NotifyTaskAssigned}
+
+// badRatingThreshold bu puan ve altındaki değerlendirmeler kötü sayılır
+const badRatingThreshold = 2
+
+// Notification kullanıcıya gönderilecek bildirimi temsil eder
+type Notification struct {
+	Event   string `json:"event"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+	URL     string `json:"url,omitempty"` // Push bildirime tıklanınca açılacak adres
+
+	Attachments []NotificationAttachment `json:"attachments,omitempty"` // Sadece e-posta kanalında gönderilir
+}
+
+// NotificationAttachment e-postaya eklenecek dosya
+type NotificationAttachment struct {
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Data        []byte `json:"-"`
+}
+
+// Notifier bir bildirim kanalı sürücüsü.
+// target kanala göre e-posta adresi, telefon numarası ya da push aboneliğidir.
+type Notifier interface {
+	Send(target string, n Notification) error
+}
+
+// notifiers kanal adına göre yapılandırılmış sürücüler
+var notifiers = map[string]Notifier{}
+
+// InitNotifiers yapılandırmadaki sürücülere göre bildirim kanallarını hazırlar.
+// Bir kanalın sürücüsü "sink" ise (varsayılan) bildirimler dış servise gitmeden dosyaya/loga yazılır.
+func InitNotifiers() {
+	InitVAPIDKeys()
+
+	cfg := config.Notify
+	sink := &SinkNotifier{Path: cfg.SinkFile}
+
+	notifiers[ChannelLog] = sink
+	notifiers[ChannelEmail] = sink
+	notifiers[ChannelSMS] = sink
+	notifiers[ChannelPush] = sink
+
+	if cfg.EmailDriver == "smtp" {
+		notifiers[ChannelEmail] = &SMTPNotifier{
+			Host:     cfg.SMTP.Host,
+			Port:     cfg.SMTP.Port,
+			Username: cfg.SMTP.Username,
+			Password: cfg.SMTP.Password,
+			From:     cfg.SMTP.From,
+		}
+	}
+
+	if cfg.SMSDriver == "http" {
+		notifiers[ChannelSMS] = &HTTPSMSNotifier{
+			URL:    cfg.SMS.APIURL,
+			Token:  cfg.SMS.APIToken,
+			Sender: cfg.SMS.Sender,
+		}
+	}
+
+	if cfg.PushDriver == "webpush" {
+		notifiers[ChannelPush] = &WebPushNotifier{
+			Subject: cfg.VAPID.Subject,
+		}
+	}
+}
+
+// SinkNotifier bildirimleri JSON satırları olarak dosyaya, dosya yoksa loga yazar.
+// Dış servis olmadan test etmek için kullanılır.
+type SinkNotifier struct {
+	Path string
+	mu   sync.Mutex
+}
+
+// Send bildirimi sink'e yazar
+func (s *SinkNotifier) Send(target string, n Notification) error {
+	line, err := json.Marshal(struct {
+		Time   time.Time    `json:"time"`
+		Target string       `json:"target"`
+		Data   Notification `json:"notification"`
+	}{time.Now(), target, n})
+	if err != nil {
+		return err
+	}
+
+	if s.Path == "" {
+		log.Printf("Bildirim: %s", line)
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// SMTPNotifier bildirimleri e-posta olarak gönderir
+type SMTPNotifier struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// Send e-postayı SMTP sunucusu üzerinden gönderir
+func (s *SMTPNotifier) Send(target string, n Notification) error {
+	// Tercihler kaydedilirken doğrulanır; eski kayıtlar başlık eklemek için kullanılamasın
+	if !isEmailAddress(target) {
+		return fmt.Errorf("geçersiz e-posta adresi: %q", target)
+	}
+
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", s.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", target)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", n.Subject))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+
+	body := n.Body
+	if n.URL != "" {
+		body += "\r\n\r\n" + n.URL
+	}
+
+	if len(n.Attachments) == 0 {
+		msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+		msg.WriteString("\r\n")
+		msg.WriteString(body)
+		return smtp.SendMail(s.Host+":"+s.Port, auth, s.From, []string{target}, msg.Bytes())
+	}
+
+	// Ekli e-postalar multipart/mixed olarak gönderilir
+	mw := multipart.NewWriter(&msg)
+	fmt.Fprintf(&msg, "Content-Type: multipart/mixed; boundary=%s\r\n", mw.Boundary())
+	msg.WriteString("\r\n")
+
+	part, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain; charset=UTF-8"}})
+	if err != nil {
+		return err
+	}
+	part.Write([]byte(body))
+
+	for _, attachment := range n.Attachments {
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {attachment.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Name})},
+		})
+		if err != nil {
+			return err
+		}
+
+		encoded := base64.StdEncoding.EncodeToString(attachment.Data)
+		for len(encoded) > 76 {
+			part.Write([]byte(encoded[:76] + "\r\n"))
+			encoded = encoded[76:]
+		}
+		part.Write([]byte(encoded))
+	}
+
+	if err := mw.Close(); err != nil {
+		return err
+	}
+
+	return smtp.SendMail(s.Host+":"+s.Port, auth, s.From, []string{target}, msg.Bytes())
+}
+
+// HTTPSMSNotifier bildirimleri genel bir HTTP SMS API'sine JSON olarak gönderir
+type HTTPSMSNotifier struct {
+	URL    string
+	Token  string
+	Sender string
+}
+
+// Send SMS isteğini gönderir; 2xx dışındaki yanıtlar hata sayılır
+func (s *HTTPSMSNotifier) Send(target string, n Notification) error {
+	body, err := json.Marshal(map[string]string{
+		"to":      target,
+		"from":    s.Sender,
+		"message": strings.TrimSpace(n.Subject + "\n" + n.Body),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.Token)
+	}
+
+	resp, err := notifyHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("SMS servisi beklenmeyen yanıt döndü: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// WebPushNotifier bildirimleri Web Push (VAPID) ile tarayıcıya gönderir.
+// Anahtarlar InitVAPIDKeys ile hazırlanan aktif anahtar çiftinden okunur.
+type WebPushNotifier struct {
+	Subject string
+}
+
+// Send target olarak verilen JSON push aboneliğine bildirimi gönderir
+func (w *WebPushNotifier) Send(target string, n Notification) error {
+	var sub webpush.Subscription
+	if err := json.Unmarshal([]byte(target), &sub); err != nil {
+		return fmt.Errorf("geçersiz push aboneliği: %w", err)
+	}
+
+	payload, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+
+	publicKey, privateKey := activeVAPIDKeys()
+	resp, err := webpush.SendNotification(payload, &sub, &webpush.Options{
+		HTTPClient:      notifyHTTPClient,
+		Subscriber:      w.Subject,
+		VAPIDPublicKey:  publicKey,
+		VAPIDPrivateKey: privateKey,
+		TTL:             3600,
+		Urgency:         webpush.UrgencyHigh,
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		return errPushSubscriptionGone
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("push servisi beklenmeyen yanıt döndü: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+var notifyHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// subscribes tercihin verilen olayı kapsayıp kapsamadığını kontrol eder
+func (p NotificationPreference) subscribes(event string) bool {
+	for _, e := range strings.Split(p.Events, ",") {
+		e = strings.TrimSpace(e)
+		if e == "*" || e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// localizedNotification bildirimi alıcının dilinde oluşturur
+type localizedNotification func(lang string) Notification
+
+// notifyUsers verilen kullanıcıların tercihlerine göre bildirimi her kullanıcının dilinde arka planda gönderir
+func notifyUsers(userIDs []uint, build localizedNotification) {
+	if len(userIDs) == 0 {
+		return
+	}
+
+	var prefs []NotificationPreference
+	if err := DB.Where("user_id IN ? AND is_enabled = ?", userIDs, true).Find(&prefs).Error; err != nil {
+		log.Printf("Bildirim tercihleri getirilemedi: %v", err)
+		return
+	}
+
+	languages := userLanguages(userIDs)
+	for _, pref := range prefs {
+		n := build(languages[pref.UserID])
+		if !pref.subscribes(n.Event) {
+			continue
+		}
+
+		notifier, ok := notifiers[pref.Channel]
+		if !ok {
+			continue
+		}
+
+		// Push bildirimleri kullanıcının kayıtlı tüm cihazlarına gider
+		if pref.Channel == ChannelPush {
+			for _, sub := range userPushSubscriptions(pref.UserID) {
+				goBackground(func() { sendPushNotification(notifier, sub, n) })
+			}
+			continue
+		}
+
+		goBackground(func() {
+			if err := notifier.Send(pref.Target, n); err != nil {
+				log.Printf("Bildirim gönderilemedi (kullanıcı %d, kanal %s): %v", pref.UserID, pref.Channel, err)
+			}
+		})
+	}
+}
+
+// userLanguages kullanıcıların bildirim dilini döner; dil tercihi olmayanlar varsayılan dili alır
+func userLanguages(userIDs []uint) map[uint]string {
+	var users []User
+	if err := DB.Select("id", "language").Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		log.Printf("Kullanıcı dilleri getirilemedi: %v", err)
+	}
+
+	languages := make(map[uint]string, len(userIDs))
+	for _, id := range userIDs {
+		languages[id] = defaultLanguage
+	}
+	for _, user := range users {
+		if supportedLanguage(user.Language) {
+			languages[user.ID] = user.Language
+		}
+	}
+	return languages
+}
+
+// sendPushNotification bildirimi tek bir cihaza gönderir, geçersiz abonelikleri siler
+func sendPushNotification(notifier Notifier, sub PushSubscription, n Notification) {
+	target, err := json.Marshal(sub.webpushSubscription())
+	if err != nil {
+		return
+	}
+
+	err = notifier.Send(string(target), n)
+	if errors.Is(err, errPushSubscriptionGone) {
+		DB.Delete(&PushSubscription{}, sub.ID)
+		return
+	}
+	if err != nil {
+		log.Printf("Push bildirimi gönderilemedi (kullanıcı %d): %v", sub.UserID, err)
+	}
+}
+
+// notifyActiveCleaners tüm aktif temizlikçilere bildirim gönderir
+func notifyActiveCleaners(build localizedNotification) {
+	var cleanerIDs []uint
+	if err := DB.Model(&User{}).Where("role = ? AND is_active = ?", "temizlikci", true).Pluck("id", &cleanerIDs).Error; err != nil {
+		log.Printf("Temizlikçiler getirilemedi: %v", err)
+		return
+	}
+
+	notifyUsers(cleanerIDs, build)
+}
+
+// EventNotifier handler'ların tetiklediği kullanıcı bildirimleri
+type EventNotifier interface {
+	BadRating(rating Rating)
+	TaskAssigned(task CleaningTask, urgentProblem int) // urgentProblem acil görevi tetikleyen problem ID'si, elle atamada 0
+}
+
+// channelEventNotifier bildirimleri kullanıcıların tercih ettiği kanallardan gönderir
+type channelEventNotifier struct{}
+
+func (channelEventNotifier) BadRating(rating Rating) {
+	notifyBadRating(rating)
+}
+
+func (channelEventNotifier) TaskAssigned(task CleaningTask, urgentProblem int) {
+	notifyTaskAssigned(task, urgentProblem)
+}
+
+// nopEventNotifier bildirim göndermez; veritabanı olmadan çalışan testler için
+type nopEventNotifier struct{}
+
+func (nopEventNotifier) BadRating(Rating) {}
+
+func (nopEventNotifier) TaskAssigned(CleaningTask, int) {}
+
+// notifyBadRating düşük puanlı veya sorun bildirilen değerlendirmeyi temizlikçilere iletir
+func notifyBadRating(rating Rating) {
+	var problemIDs []int
+	json.Unmarshal([]byte(rating.Problems), &problemIDs)
+
+	if rating.Rating > badRatingThreshold && len(problemIDs) == 0 {
+		return
+	}
+
+	toiletName := toiletDisplayName(rating.ToiletID)
+
+	notifyActiveCleaners(func(lang string) Notification {
+		body := notificationText(lang, NoteBadRatingBody, toiletName, rating.Rating)
+		if problemTexts := ratingProblemTexts(rating, lang); len(problemTexts) > 0 {
+			body += notificationText(lang, NoteBadRatingProblems, strings.Join(problemTexts, ", "))
+		}
+		if rating.OtherText != "" {
+			body += " (" + rating.OtherText + ")"
+		}
+
+		return Notification{
+			Event:   NotifyBadRating,
+			Subject: notificationText(lang, NoteBadRatingSubject, toiletName),
+			Body:    body,
+		}
+	})
+}
+
+// notifyTaskAssigned görevi atanan temizlikçiye kendi dilinde bildirim gönderir
+func notifyTaskAssigned(task CleaningTask, urgentProblem int) {
+	toiletName := toiletDisplayName(task.ToiletID)
+
+	notifyUsers([]uint{task.CleanerID}, func(lang string) Notification {
+		body := notificationText(lang, NoteTaskAssignedBody, toiletName)
+		if urgentProblem != 0 {
+			body += notificationText(lang, NoteUrgentProblem, problemLabelOrUnknown(urgentProblem, lang))
+		}
+
+		return Notification{
+			Event:   NotifyTaskAssigned,
+			Subject: notificationText(lang, NoteTaskAssignedSubject, toiletName),
+			Body:    body,
+			URL:     "/cleaner",
+		}
+	})
+}
+
+// toiletDisplayName bildirimlerde kullanılacak tuvalet adını döner
+func toiletDisplayName(toiletID int) string {
+	var toilet Toilet
+	if err := DB.First(&toilet, toiletID).Error; err == nil {
+		return toilet.Name
+	}
+	return fmt.Sprintf("Tuvalet %d", toiletID)
+}
+
+// validateNotificationPreference tercih girdisinin kanal ve olaylarını doğrular; field hata yolundaki önek (ör. preferences[0])
+func validateNotificationPreference(field string, input NotificationPreferenceInput) []FieldError {
+	var errs []FieldError
+
+	knownChannel := false
+	for _, ch := range NotificationChannels {
+		if ch == input.Channel {
+			knownChannel = true
+			break
+		}
+	}
+	if !knownChannel {
+		return append(errs, fieldError(field+".channel", RuleUnknownValue, input.Channel))
+	}
+
+	// Push hedefleri kullanıcının kayıtlı cihazlarından alınır. Hedef e-posta başlığına ve
+	// log satırına yazıldığı için satır sonu içeremez.
+	switch {
+	case input.Target == "" && input.Channel != ChannelLog && input.Channel != ChannelPush:
+		errs = append(errs, fieldError(field+".target", RuleRequired, ""))
+	case strings.ContainsAny(input.Target, "\r\n"):
+		errs = append(errs, fieldError(field+".target", RuleInvalidFormat, ""))
+	case input.Channel == ChannelEmail && !isEmailAddress(input.Target):
+		errs = append(errs, fieldError(field+".target", RuleEmail, ""))
+	case input.Channel == ChannelSMS && !phoneNumberPattern.MatchString(input.Target):
+		errs = append(errs, fieldError(field+".target", RulePhone, ""))
+	}
+
+	for i, event := range input.Events {
+		if event == "*" {
+			continue
+		}
+		known := false
+		for _, e := range NotificationEvents {
+			if e == event {
+				known = true
+				break
+			}
+		}
+		if !known {
+			errs = append(errs, fieldError(fmt.Sprintf("%s.events[%d]", field, i), RuleUnknownValue, event))
+		}
+	}
+
+	return errs
+}
+
+// phoneNumberPattern SMS hedefleri için E.164 formatındaki telefon numarası
+var phoneNumberPattern = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// getNotificationPreferences kullanıcının bildirim tercihlerini getirir (sadece admin erişimi)
+func getNotificationPreferences(c *gin.Context) {
+	idStr := c.Param("id")
+	userID, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		respondError(c, invalidParam("id"))
+		return
+	}
+
+	var prefs []NotificationPreference
+	if err := DB.Where("user_id = ?", userID).Order("id ASC").Find(&prefs).Error; err != nil {
+		respondError(c, internalError(err, "Bildirim tercihleri getirilirken hata oluştu"))
+		return
+	}
+
+	c.JSON(http.StatusOK, NotificationPreferencesResponse{
+		Success:     true,
+		Message:     messageText(c, MsgPreferencesFetched),
+		Preferences: prefs,
+	})
+}
+
+// updateNotificationPreferences kullanıcının bildirim tercihlerini verilen liste ile değiştirir (sadece admin erişimi)
+func updateNotificationPreferences(c *gin.Context) {
+	idStr := c.Param("id")
+	userID, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		respondError(c, invalidParam("id"))
+		return
+	}
+
+	var req NotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, bindError(err))
+		return
+	}
+
+	var fieldErrs []FieldError
+	for i, input := range req.Preferences {
+		fieldErrs = append(fieldErrs, validateNotificationPreference(fmt.Sprintf("preferences[%d]", i), input)...)
+	}
+	if len(fieldErrs) > 0 {
+		respondError(c, validationError(fieldErrs...))
+		return
+	}
+
+	var user User
+	if err := DB.First(&user, uint(userID)).Error; err != nil {
+		respondError(c, lookupError(err, CodeUserNotFound))
+		return
+	}
+
+	prefs := []NotificationPreference{}
+	for _, input := range req.Preferences {
+		pref := NotificationPreference{
+			UserID:    user.ID,
+			Channel:   input.Channel,
+			Target:    input.Target,
+			Events:    "*",
+			IsEnabled: true,
+		}
+		if len(input.Events) > 0 {
+			pref.Events = strings.Join(input.Events, ",")
+		}
+		if input.IsEnabled != nil {
+			pref.IsEnabled = *input.IsEnabled
+		}
+		prefs = append(prefs, pref)
+	}
+
+	err = DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", user.ID).Delete(&NotificationPreference{}).Error; err != nil {
+			return err
+		}
+		if len(prefs) == 0 {
+			return nil
+		}
+		return tx.Create(&prefs).Error
+	})
+	if err != nil {
+		respondError(c, internalError(err, "Bildirim tercihleri güncellenirken hata oluştu"))
+		return
+	}
+
+	c.JSON(http.StatusOK, NotificationPreferencesResponse{
+		Success:     true,
+		Message:     messageText(c, MsgPreferencesUpdated),
+		Preferences: prefs,
+	})
+}
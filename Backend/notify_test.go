@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// useSinkNotifiers tüm kanalları test süresince verilen dosyaya yazan sink sürücüsüyle kurar
+func useSinkNotifiers(t *testing.T, path string) {
+	t.Helper()
+
+	previous, previousConfig := maps.Clone(notifiers), config
+	t.Cleanup(func() { notifiers, config = previous, previousConfig })
+
+	config.Notify.SinkFile = path
+	config.Notify.EmailDriver, config.Notify.SMSDriver, config.Notify.PushDriver = "sink", "sink", "sink"
+	InitNotifiers()
+}
+
+// sinkEntry sink dosyasındaki bir satır
+type sinkEntry struct {
+	Target string       `json:"target"`
+	Data   Notification `json:"notification"`
+}
+
+// readSinkFile arka plan gönderimlerinin bitmesini bekleyip sink dosyasındaki bildirimleri "hedef olay" olarak döner
+func readSinkFile(t *testing.T, path string) []string {
+	t.Helper()
+	backgroundJobs.Wait()
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatalf("Sink dosyası açılamadı: %v", err)
+	}
+	defer f.Close()
+
+	var sent []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry sinkEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("Sink satırı çözülemedi: %v (%s)", err, scanner.Text())
+		}
+		sent = append(sent, entry.Target+" "+entry.Data.Event)
+	}
+	slices.Sort(sent)
+	return sent
+}
+
+// setPreferences kullanıcının bildirim tercihlerini admin endpoint'i üzerinden kaydeder
+func (s *testServer) setPreferences(user User, prefs ...NotificationPreferenceInput) {
+	s.t.Helper()
+	rec := s.request(http.MethodPut, fmt.Sprintf("/api/admin/users/%d/notifications", user.ID), NotificationPreferencesRequest{Preferences: prefs}, s.adminHeaders())
+	expectStatus(s.t, rec, http.StatusOK)
+}
+
+func TestNotificationPreferenceRoutesRequireAdmin(t *testing.T) {
+	s := newTestServer(t)
+	cleaner := s.createUser("ayse", "Ayşe", "temizlikci")
+	s.setPreferences(cleaner, NotificationPreferenceInput{Channel: ChannelEmail, Target: "ayse@example.com"})
+	path := fmt.Sprintf("/api/admin/users/%d/notifications", cleaner.ID)
+	body := NotificationPreferencesRequest{Preferences: []NotificationPreferenceInput{{Channel: ChannelEmail, Target: "saldirgan@example.com"}}}
+
+	for _, method := range []string{http.MethodGet, http.MethodPut} {
+		expectError(t, s, method, path, body, nil, http.StatusUnauthorized, CodeUnauthorized)
+		expectError(t, s, method, path, body, staffHeaders(cleaner), http.StatusForbidden, CodeForbidden)
+	}
+
+	var pref NotificationPreference
+	if err := s.db.Where("user_id = ?", cleaner.ID).First(&pref).Error; err != nil || pref.Target != "ayse@example.com" {
+		t.Fatalf("Yetkisiz istek tercihi değiştirdi: %+v (%v)", pref, err)
+	}
+}
+
+func TestSMTPNotifierRejectsHeaderInjection(t *testing.T) {
+	smtpNotifier := &SMTPNotifier{Host: "127.0.0.1", Port: "1", From: "temizlik@example.com"}
+	err := smtpNotifier.Send("a@example.com\r\nBcc: b@example.com", Notification{Subject: "x", Body: "y"})
+	if err == nil || !strings.Contains(err.Error(), "geçersiz e-posta") {
+		t.Fatalf("Satır sonu içeren hedef reddedilmedi: %v", err)
+	}
+}
+
+func TestSinkNotifierRoutesByPreferences(t *testing.T) {
+	s := newTestServer(t)
+	sinkPath := filepath.Join(t.TempDir(), "notifications.log")
+	useSinkNotifiers(t, sinkPath)
+
+	disabled := false
+	ayse := s.createUser("ayse", "Ayşe", "temizlikci")
+	s.setPreferences(ayse,
+		NotificationPreferenceInput{Channel: ChannelEmail, Target: "ayse@example.com", Events: []string{NotifyBadRating}},
+		NotificationPreferenceInput{Channel: ChannelSMS, Target: "+905551112233", Events: []string{NotifyTaskAssigned}},
+		// Kayıtlı cihazı olmayan push tercihi bildirim üretmez
+		NotificationPreferenceInput{Channel: ChannelPush},
+	)
+
+	mehmet := s.createUser("mehmet", "Mehmet", "temizlikci")
+	s.setPreferences(mehmet,
+		NotificationPreferenceInput{Channel: ChannelEmail, Target: "mehmet@example.com", IsEnabled: &disabled},
+		NotificationPreferenceInput{Channel: ChannelLog, Target: "mehmet-log"},
+	)
+
+	// Pasif temizlikçi ve admin kötü puan bildirimini almaz
+	pasif := s.createUser("pasif", "Pasif", "temizlikci")
+	s.setPreferences(pasif, NotificationPreferenceInput{Channel: ChannelEmail, Target: "pasif@example.com"})
+	if err := s.db.Model(&User{}).Where("id = ?", pasif.ID).Update("is_active", false).Error; err != nil {
+		t.Fatalf("Kullanıcı pasifleştirilemedi: %v", err)
+	}
+	admin := s.createUser("mudur", "Müdür", "admin")
+	s.setPreferences(admin, NotificationPreferenceInput{Channel: ChannelEmail, Target: "admin@example.com"})
+
+	// İyi puan kimseye bildirilmez
+	notifyBadRating(Rating{ToiletID: 1, Rating: 5, Problems: "[]"})
+	if sent := readSinkFile(t, sinkPath); len(sent) != 0 {
+		t.Fatalf("İyi puan için bildirim gönderilmemeli: %v", sent)
+	}
+
+	notifyBadRating(Rating{ToiletID: 1, Rating: 1, Problems: "[1]"})
+	notifyTaskAssigned(CleaningTask{ToiletID: 1, CleanerID: ayse.ID}, 0)
+
+	want := []string{
+		"+905551112233 " + NotifyTaskAssigned,
+		"ayse@example.com " + NotifyBadRating,
+		"mehmet-log " + NotifyBadRating,
+	}
+	if sent := readSinkFile(t, sinkPath); !slices.Equal(sent, want) {
+		t.Fatalf("Gönderilen bildirimler %v, beklenen %v", sent, want)
+	}
+}
+
+func TestNotificationsUseRecipientLanguage(t *testing.T) {
+	s := newTestServer(t)
+	sinkPath := filepath.Join(t.TempDir(), "notifications.log")
+	useSinkNotifiers(t, sinkPath)
+
+	ayse := s.createUser("ayse", "Ayşe", "temizlikci")
+	s.setPreferences(ayse, NotificationPreferenceInput{Channel: ChannelEmail, Target: "ayse@example.com"})
+	john := s.createUser("john", "John", "temizlikci")
+	rec := s.request(http.MethodPut, fmt.Sprintf("/api/admin/users/%d", john.ID), UpdateUserRequest{Language: LangEnglish}, s.adminHeaders())
+	expectStatus(t, rec, http.StatusOK)
+	s.setPreferences(john, NotificationPreferenceInput{Channel: ChannelSMS, Target: "+447700900123"})
+
+	notifyBadRating(Rating{ToiletID: 1, Rating: 1, Problems: "[2]"})
+	notifyTaskAssigned(CleaningTask{ToiletID: 1, CleanerID: john.ID}, 2)
+	notifyTaskAssigned(CleaningTask{ToiletID: 1, CleanerID: ayse.ID}, 2)
+	backgroundJobs.Wait()
+
+	content, err := os.ReadFile(sinkPath)
+	if err != nil {
+		t.Fatalf("Sink dosyası okunamadı: %v", err)
+	}
+	got := map[string]Notification{}
+	for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		var entry sinkEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Sink satırı çözülemedi: %v (%s)", err, line)
+		}
+		got[entry.Target+" "+entry.Data.Event] = entry.Data
+	}
+
+	toiletName := toiletDisplayName(1)
+	want := map[string]Notification{
+		"ayse@example.com " + NotifyBadRating: {
+			Subject: "Temizlik uyarısı: " + toiletName,
+			Body:    toiletName + " için 1/5 puan verildi. Sorunlar: Sabun yok",
+		},
+		"ayse@example.com " + NotifyTaskAssigned: {
+			Subject: "Yeni temizlik görevi: " + toiletName,
+			Body:    toiletName + " için temizlik görevi size atandı. Acil sorun bildirildi: Sabun yok",
+		},
+		"+447700900123 " + NotifyBadRating: {
+			Subject: "Cleaning alert: " + toiletName,
+			Body:    toiletName + " was rated 1/5. Problems: No soap",
+		},
+		"+447700900123 " + NotifyTaskAssigned: {
+			Subject: "New cleaning task: " + toiletName,
+			Body:    "You have been assigned to clean " + toiletName + ". Urgent problem reported: No soap",
+		},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Gönderilen bildirimler %v, beklenen %d bildirim", got, len(want))
+	}
+	for key, w := range want {
+		if n := got[key]; n.Subject != w.Subject || n.Body != w.Body {
+			t.Errorf("%s: %q / %q, beklenen %q / %q", key, n.Subject, n.Body, w.Subject, w.Body)
+		}
+	}
+}
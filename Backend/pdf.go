@@ -0,0 +1,45 @@
+package main
+
+import (
+	"log"
+	"os"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// pdfTurkishFallback standart PDF fontlarında (cp1252) bulunmayan Türkçe harflerin karşılıkları
+var pdfTurkishFallback = strings.NewReplacer(
+	"ğ", "g", "Ğ", "G",
+	"ş", "s", "Ş", "S",
+	"ı", "i", "İ", "I",
+)
+
+// newPDFDocument A4 dikey bir PDF belgesi, kullanılacak font ailesi ve metin çeviricisi döner.
+// Yapılandırmada UTF-8 TrueType font verilirse Türkçe karakterler olduğu gibi basılır;
+// verilmezse Helvetica kullanılır ve desteklenmeyen harfler en yakın karşılığına çevrilir.
+func newPDFDocument() (*fpdf.Fpdf, string, func(string) string) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+
+	if fontPath := config.PDF.FontPath; fontPath != "" {
+		regular, err := os.ReadFile(fontPath)
+		if err == nil {
+			bold := regular
+			if boldPath := config.PDF.FontBoldPath; boldPath != "" {
+				if b, err := os.ReadFile(boldPath); err == nil {
+					bold = b
+				}
+			}
+
+			pdf.AddUTF8FontFromBytes("utf8", "", regular)
+			pdf.AddUTF8FontFromBytes("utf8", "B", bold)
+			return pdf, "utf8", func(s string) string { return s }
+		}
+		log.Printf("PDF fontu okunamadı (%s): %v", fontPath, err)
+	}
+
+	cp1252 := pdf.UnicodeTranslatorFromDescriptor("")
+	return pdf, "Helvetica", func(s string) string {
+		return cp1252(pdfTurkishFallback.Replace(s))
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPostCleaningWindowHours = 4
+	maxPostCleaningWindowHours     = 72
+	// Önceki temizlik bulunamazsa görevden en fazla bu kadar önceki problem bildirimleri dikkate alınır
+	problemLookback = 24 * time.Hour
+)
+
+// resolveSLA problem bildiriminden temizliğin tamamlanmasına kadar hedeflenen en uzun süre (RESOLVE_SLA_MINUTES)
+var resolveSLA = time.Hour
+
+// performanceAccumulator bir temizlikçinin ham ölçümlerini toplar
+type performanceAccumulator struct {
+	report    CleanerPerformance
+	responses []float64
+	resolves  []float64
+	ratingSum int
+}
+
+// meanAndMedian değerlerin ortalamasını ve medyanını döner, değer yoksa nil döner
+func meanAndMedian(values []float64) (*float64, *float64) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	median := percentile(sorted, 0.5)
+
+	return &mean, &median
+}
+
+// firstRatingAfter zamana göre sıralı puanlamalarda t'den sonraki ilk puanlamanın indeksini döner
+func firstRatingAfter(ratings []Rating, t time.Time) int {
+	return sort.Search(len(ratings), func(i int) bool {
+		return ratings[i].CreatedAt.After(t)
+	})
+}
+
+// buildCleanerPerformance filtre aralığında görev tamamlayan temizlikçilerin müdahale ve kalite metriklerini hesaplar.
+// Müdahale süresi: görevden önceki ilk problem bildirimi → görevin başlaması.
+// Çözüm süresi: aynı problem bildirimi → görevin tamamlanması.
+// Temizlik sonrası puan: görev tamamlandıktan sonraki window içinde verilen puanların ortalaması.
+func buildCleanerPerformance(filter analyticsFilter, window time.Duration) ([]CleanerPerformance, error) {
+	tasks, err := filter.completedTasksInRange()
+	if err != nil {
+		return nil, err
+	}
+
+	report := []CleanerPerformance{}
+	if len(tasks) == 0 {
+		return report, nil
+	}
+
+	toiletIDs := []int{}
+	seen := map[int]bool{}
+	for _, task := range tasks {
+		if !seen[task.ToiletID] {
+			seen[task.ToiletID] = true
+			toiletIDs = append(toiletIDs, task.ToiletID)
+		}
+	}
+
+	var ratings []Rating
+	if err := DB.Where("toilet_id IN ? AND created_at >= ? AND created_at < ?",
+		toiletIDs, filter.From.Add(-problemLookback), filter.To.Add(window)).
+		Order("created_at ASC").
+		Find(&ratings).Error; err != nil {
+		return nil, err
+	}
+
+	// Problemin hangi temizlikten sonra bildirildiğini bulmak için tüm temizlikçilerin görevleri gerekir
+	var completions []CleaningTask
+	if err := DB.Select("toilet_id, completed_at").
+		Where("status = ? AND toilet_id IN ? AND completed_at >= ? AND completed_at < ?",
+			"completed", toiletIDs, filter.From.Add(-problemLookback), filter.To).
+		Find(&completions).Error; err != nil {
+		return nil, err
+	}
+
+	ratingsByToilet := map[int][]Rating{}
+	for _, rating := range ratings {
+		ratingsByToilet[rating.ToiletID] = append(ratingsByToilet[rating.ToiletID], rating)
+	}
+
+	completionsByToilet := map[int][]time.Time{}
+	for _, completion := range completions {
+		if completion.CompletedAt != nil {
+			completionsByToilet[completion.ToiletID] = append(completionsByToilet[completion.ToiletID], *completion.CompletedAt)
+		}
+	}
+
+	accumulators := map[uint]*performanceAccumulator{}
+	for _, task := range tasks {
+		acc, ok := accumulators[task.CleanerID]
+		if !ok {
+			acc = &performanceAccumulator{report: CleanerPerformance{
+				CleanerID:   task.CleanerID,
+				CleanerName: task.CleanerName,
+			}}
+			accumulators[task.CleanerID] = acc
+		}
+		acc.report.TasksCompleted++
+
+		toiletRatings := ratingsByToilet[task.ToiletID]
+
+		if task.StartedAt != nil {
+			// Problem penceresi: aynı tuvaletteki bir önceki temizlikten görevin başlangıcına kadar
+			windowStart := task.StartedAt.Add(-problemLookback)
+			for _, completedAt := range completionsByToilet[task.ToiletID] {
+				if completedAt.Before(*task.StartedAt) && completedAt.After(windowStart) {
+					windowStart = completedAt
+				}
+			}
+
+			for _, rating := range toiletRatings[firstRatingAfter(toiletRatings, windowStart):] {
+				if rating.CreatedAt.After(*task.StartedAt) {
+					break
+				}
+				if len(ratingProblemIDs(rating)) == 0 {
+					continue
+				}
+
+				acc.report.ProblemTasks++
+				acc.responses = append(acc.responses, task.StartedAt.Sub(rating.CreatedAt).Minutes())
+				acc.resolves = append(acc.resolves, task.CompletedAt.Sub(rating.CreatedAt).Minutes())
+				if task.CompletedAt.Sub(rating.CreatedAt) <= resolveSLA {
+					acc.report.ResolvedWithinSLA++
+				}
+				break
+			}
+		}
+
+		// Tamamlama anında otomatik oluşturulan 5 puanlık kayıt hariç tutulur
+		windowEnd := task.CompletedAt.Add(window)
+		for _, rating := range toiletRatings[firstRatingAfter(toiletRatings, *task.CompletedAt):] {
+			if rating.CreatedAt.After(windowEnd) {
+				break
+			}
+			acc.report.PostCleaningRatingCount++
+			acc.ratingSum += rating.Rating
+		}
+	}
+
+	for _, acc := range accumulators {
+		acc.report.AverageResponseMinutes, acc.report.MedianResponseMinutes = meanAndMedian(acc.responses)
+		acc.report.AverageResolveMinutes, acc.report.MedianResolveMinutes = meanAndMedian(acc.resolves)
+		if acc.report.PostCleaningRatingCount > 0 {
+			avg := float64(acc.ratingSum) / float64(acc.report.PostCleaningRatingCount)
+			acc.report.PostCleaningAverageRating = &avg
+		}
+		report = append(report, acc.report)
+	}
+
+	sort.Slice(report, func(i, j int) bool {
+		return report[i].CleanerID < report[j].CleanerID
+	})
+
+	return report, nil
+}
+
+// getCleanerPerformance temizlikçi bazında müdahale süresi, çözüm süresi ve temizlik sonrası puan raporunu döner (sadece admin erişimi)
+func getCleanerPerformance(c *gin.Context) {
+	filter, err := parseAnalyticsFilter(c, defaultAnalyticsRange)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	windowHours := defaultPostCleaningWindowHours
+	if value := c.Query("window_hours"); value != "" {
+		hours, err := strconv.Atoi(value)
+		if err != nil || hours < 1 || hours > maxPostCleaningWindowHours {
+			respondError(c, validationError(fieldError("window_hours", RuleRange, "1-"+strconv.Itoa(maxPostCleaningWindowHours))))
+			return
+		}
+		windowHours = hours
+	}
+
+	cleaners, err := buildCleanerPerformance(filter, time.Duration(windowHours)*time.Hour)
+	if err != nil {
+		respondError(c, internalError(err, "Performans raporu oluşturulurken hata oluştu"))
+		return
+	}
+
+	c.JSON(http.StatusOK, CleanerPerformanceResponse{
+		Success:     true,
+		Message:     messageText(c, MsgPerformanceFetched),
+		From:        &filter.From,
+		To:          &filter.To,
+		WindowHours: windowHours,
+		Cleaners:    cleaners,
+	})
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"math"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestBuildCleanerPerformance(t *testing.T) {
+	s := newTestServer(t)
+	loc := setFacilityTimezone(t, "Europe/Istanbul")
+	ayse := s.createUser("ayse", "Ayşe", "temizlikci")
+	mehmet := s.createUser("mehmet", "Mehmet", "temizlikci")
+	zeynep := s.createUser("zeynep", "Zeynep", "temizlikci")
+
+	at := func(hour, minute int) time.Time { return time.Date(2026, 3, 10, hour, minute, 0, 0, loc) }
+
+	// Tuvalet 1: 07:30 problemini Mehmet 08:00'de temizler; 09:00 problemi ondan sonra bildirilir ve Ayşe'ye kalır
+	s.insertRating(1, 2, "[1]", at(7, 30))
+	s.insertRating(1, 1, "[2]", at(9, 0))
+	s.insertRating(1, 5, "[]", at(9, 50)) // Ayşe'nin tamamlama anındaki otomatik puan
+	s.insertRating(1, 4, "[]", at(10, 30))
+	s.insertRating(1, 2, "[]", at(14, 30)) // 4 saatlik pencerenin dışında
+	s.insertTask(mehmet, 1, "completed", timePtr(at(7, 45)), timePtr(at(8, 0)))
+	s.insertTask(ayse, 1, "completed", timePtr(at(9, 20)), timePtr(at(9, 50)))
+
+	// Tuvalet 2: problem 10:00, Ayşe 10:30'da başlar, 11:30'da bitirir (hedef süre aşılır)
+	s.insertRating(2, 2, "[3]", at(10, 0))
+	s.insertRating(2, 3, "[]", at(12, 0))
+	s.insertTask(ayse, 2, "completed", timePtr(at(10, 30)), timePtr(at(11, 30)))
+
+	// Başlangıcı kaydedilmemiş görev: müdahale ölçülemez, sonrasında puan yok
+	s.insertTask(zeynep, 3, "completed", nil, timePtr(at(12, 0)))
+
+	report, err := buildCleanerPerformance(analyticsFilter{From: at(0, 0), To: at(23, 59)}, 4*time.Hour)
+	if err != nil {
+		t.Fatalf("Performans raporu oluşturulamadı: %v", err)
+	}
+
+	type want struct {
+		id                           uint
+		tasks, problemTasks, withSLA int
+		avgResponse, medResponse     float64 // -1: null
+		avgResolve, medResolve       float64
+		postCount                    int
+		postAverage                  float64
+	}
+	wants := []want{
+		{ayse.ID, 2, 2, 1, 25, 25, 70, 70, 2, 3.5},
+		{mehmet.ID, 1, 1, 1, 15, 15, 30, 30, 3, 10.0 / 3},
+		{zeynep.ID, 1, 0, 0, -1, -1, -1, -1, 0, -1},
+	}
+	if len(report) != len(wants) {
+		t.Fatalf("Temizlikçi sayısı %d, beklenen %d", len(report), len(wants))
+	}
+
+	nullable := func(v *float64) float64 {
+		if v == nil {
+			return -1
+		}
+		return *v
+	}
+	for i, w := range wants {
+		got := report[i]
+		values := []struct {
+			name      string
+			got, want float64
+		}{
+			{"ortalama müdahale", nullable(got.AverageResponseMinutes), w.avgResponse},
+			{"medyan müdahale", nullable(got.MedianResponseMinutes), w.medResponse},
+			{"ortalama çözüm", nullable(got.AverageResolveMinutes), w.avgResolve},
+			{"medyan çözüm", nullable(got.MedianResolveMinutes), w.medResolve},
+			{"temizlik sonrası puan", nullable(got.PostCleaningAverageRating), w.postAverage},
+		}
+		if got.CleanerID != w.id || got.TasksCompleted != w.tasks || got.ProblemTasks != w.problemTasks ||
+			got.ResolvedWithinSLA != w.withSLA || got.PostCleaningRatingCount != w.postCount {
+			t.Fatalf("%d. temizlikçi %+v, beklenen %+v", i, got, w)
+		}
+		for _, v := range values {
+			if math.Abs(v.got-v.want) > 1e-9 {
+				t.Fatalf("%d. temizlikçi %s %v, beklenen %v", i, v.name, v.got, v.want)
+			}
+		}
+	}
+
+	// Temizlikçi filtresi
+	report, err = buildCleanerPerformance(analyticsFilter{From: at(0, 0), To: at(23, 59), CleanerID: mehmet.ID}, 4*time.Hour)
+	if err != nil || len(report) != 1 || report[0].CleanerID != mehmet.ID {
+		t.Fatalf("Filtrelenmiş rapor %+v (%v)", report, err)
+	}
+}
+
+func TestCleanerPerformanceWindowValidation(t *testing.T) {
+	s := newTestServer(t)
+
+	for _, value := range []string{"0", "73", "x"} {
+		expectError(t, s, http.MethodGet, "/api/admin/reports/cleaners?window_hours="+value, nil, s.adminHeaders(), http.StatusBadRequest, CodeValidationFailed)
+	}
+
+	rec := s.request(http.MethodGet, "/api/admin/reports/cleaners?window_hours=12", nil, s.adminHeaders())
+	expectStatus(t, rec, http.StatusOK)
+	var resp CleanerPerformanceResponse
+	decode(t, rec, &resp)
+	if resp.WindowHours != 12 || len(resp.Cleaners) != 0 {
+		t.Fatalf("Beklenmeyen yanıt: %+v", resp)
+	}
+}
+
+func TestCleanerPerformanceRequiresAdmin(t *testing.T) {
+	s := newTestServer(t)
+	cleaner := s.createUser("ayse", "Ayşe", "temizlikci")
+	path := "/api/admin/reports/cleaners"
+
+	expectError(t, s, http.MethodGet, path, nil, nil, http.StatusUnauthorized, CodeUnauthorized)
+	expectError(t, s, http.MethodGet, path, nil, staffHeaders(cleaner), http.StatusForbidden, CodeForbidden)
+}
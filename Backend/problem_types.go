@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unknownProblemLabels katalogda olmayan problem ID'leri için gösterilen metin; %d problem ID'si ile doldurulur
+var unknownProblemLabels = ProblemLabels{
+	LangTurkish: "Bilinmeyen (%d)",
+	LangEnglish: "Unknown (%d)",
+}
+
+// Label verilen dildeki metni döner, çeviri yoksa varsayılan dildeki metni döner
+func (l ProblemLabels) Label(lang string) string {
+	if label, ok := l[lang]; ok {
+		return label
+	}
+	return l[defaultLanguage]
+}
+
+// problemLabel problem türünün verilen dildeki metnini döner; bilinmeyen ID'lerde false döner
+func problemLabel(id int, lang string) (string, bool) {
+	labels, ok := ProblemTypes[id]
+	if !ok {
+		return "", false
+	}
+	return labels.Label(lang), true
+}
+
+// problemLabelOrUnknown bilinmeyen ID'leri de "Bilinmeyen (ID)" şeklinde metne çevirir
+func problemLabelOrUnknown(id int, lang string) string {
+	if label, ok := problemLabel(id, lang); ok {
+		return label
+	}
+	return fmt.Sprintf(unknownProblemLabels.Label(lang), id)
+}
+
+// ratingProblemTexts puanlamadaki bilinen problemlerin verilen dildeki metinlerini döner
+func ratingProblemTexts(rating Rating, lang string) []string {
+	texts := []string{}
+	for _, problemID := range ratingProblemIDs(rating) {
+		if label, ok := problemLabel(problemID, lang); ok {
+			texts = append(texts, label)
+		}
+	}
+	return texts
+}
+
+// problemTypeLabels analiz yanıtları için ID → metin eşlemesini verilen dilde döner
+func problemTypeLabels(lang string) map[int]string {
+	labels := make(map[int]string, len(ProblemTypes))
+	for id := range ProblemTypes {
+		labels[id], _ = problemLabel(id, lang)
+	}
+	return labels
+}
+
+// getProblemTypes puanlama sayfasının problem seçeneklerini ziyaretçinin dilinde ID sırasıyla döner
+func getProblemTypes(c *gin.Context) {
+	lang := requestLanguage(c)
+
+	options := make([]ProblemTypeOption, 0, len(ProblemTypes))
+	for id := range ProblemTypes {
+		label, _ := problemLabel(id, lang)
+		options = append(options, ProblemTypeOption{ID: id, Label: label})
+	}
+	sort.Slice(options, func(i, j int) bool {
+		return options[i].ID < options[j].ID
+	})
+
+	c.JSON(http.StatusOK, ProblemTypesResponse{
+		Success:      true,
+		Message:      messageText(c, MsgProblemTypesFetched),
+		Language:     lang,
+		ProblemTypes: options,
+	})
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestEveryProblemTypeIsTranslated(t *testing.T) {
+	for id, labels := range ProblemTypes {
+		for lang := range catalogs {
+			if labels[lang] == "" {
+				t.Errorf("%d numaralı problem türünün %s metni eksik", id, lang)
+			}
+		}
+	}
+	for lang := range catalogs {
+		if unknownProblemLabels[lang] == "" {
+			t.Errorf("Bilinmeyen problem metni %s dilinde eksik", lang)
+		}
+	}
+}
+
+func TestProblemLabelFallback(t *testing.T) {
+	if got := (ProblemLabels{LangTurkish: "Sabun yok"}).Label(LangEnglish); got != "Sabun yok" {
+		t.Fatalf("Çevirisi olmayan metin %q, varsayılan dil beklenirdi", got)
+	}
+	if got := problemLabelOrUnknown(99, LangEnglish); got != "Unknown (99)" {
+		t.Fatalf("Bilinmeyen problem metni %q", got)
+	}
+}
+
+func TestGetProblemTypes(t *testing.T) {
+	s := newTestServer(t)
+
+	rec := s.request(http.MethodGet, "/api/problem-types", nil, map[string]string{"Accept-Language": "en-GB,en;q=0.9"})
+	expectStatus(t, rec, http.StatusOK)
+
+	var resp ProblemTypesResponse
+	decode(t, rec, &resp)
+	if resp.Language != LangEnglish || len(resp.ProblemTypes) != len(ProblemTypes) {
+		t.Fatalf("Beklenmeyen katalog: %+v", resp)
+	}
+	for i, option := range resp.ProblemTypes {
+		if option.ID != i+1 || option.Label != ProblemTypes[option.ID][LangEnglish] {
+			t.Fatalf("%d. seçenek %+v", i, option)
+		}
+	}
+
+	// Herkese açık puanlama sayfası her kaynaktan okuyabilmeli
+	rec = s.request(http.MethodGet, "/api/problem-types", nil, map[string]string{"Origin": "https://ziyaretci.example"})
+	expectStatus(t, rec, http.StatusOK)
+	if rec.Header().Get("Access-Control-Allow-Origin") == "" {
+		t.Fatal("Problem kataloğu herkese açık CORS politikasında olmalı")
+	}
+	decode(t, rec, &resp)
+	if resp.Language != LangTurkish || resp.ProblemTypes[0].Label != ProblemTypes[1][LangTurkish] {
+		t.Fatalf("Varsayılan dilde katalog beklenirdi: %+v", resp)
+	}
+}
+
+func TestRatingProblemTextsFollowAdminLanguage(t *testing.T) {
+	s := newTestServer(t)
+	rec := s.request(http.MethodPost, "/api/rating", RatingRequest{Token: s.ratingToken(1), Rating: 2, Problems: []int{2, 4}}, nil)
+	expectStatus(t, rec, http.StatusCreated)
+
+	rec = s.request(http.MethodPost, "/api/admin/users", CreateUserRequest{
+		Username: "admin-en", Password: "parola", Name: "Admin", Role: "admin", Language: LangEnglish,
+	}, s.adminHeaders())
+	expectStatus(t, rec, http.StatusCreated)
+	var admin UserResponse
+	decode(t, rec, &admin)
+
+	rec = s.request(http.MethodGet, "/api/toilet/1/ratings/paginated", nil, staffHeaders(s.loadUser(admin.User.ID)))
+	expectStatus(t, rec, http.StatusOK)
+	var page PaginatedRatingsResponse
+	decode(t, rec, &page)
+	if len(page.Data) != 1 {
+		t.Fatalf("Beklenmeyen sayfa: %+v", page)
+	}
+	want := []string{"No soap", "Trash bin is full"}
+	if got := page.Data[0].Problems; len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("Problem metinleri %v, beklenen %v", got, want)
+	}
+}
+
+func TestGetProblemTypesFallsBackForUnsupportedLanguages(t *testing.T) {
+	s := newTestServer(t)
+
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"de", LangTurkish},
+		{"fr-FR", LangTurkish},
+		{"ar;q=0.9, ja;q=0.8", LangTurkish},
+		{"*", LangTurkish},
+		{"de-DE,de;q=0.9,en;q=0.5", LangEnglish},
+	}
+
+	for _, tt := range tests {
+		rec := s.request(http.MethodGet, "/api/problem-types", nil, map[string]string{"Accept-Language": tt.header})
+		expectStatus(t, rec, http.StatusOK)
+
+		var resp ProblemTypesResponse
+		decode(t, rec, &resp)
+		if resp.Language != tt.want || rec.Header().Get("Content-Language") != tt.want {
+			t.Errorf("%q: dil %s, beklenen %s", tt.header, resp.Language, tt.want)
+			continue
+		}
+		for _, option := range resp.ProblemTypes {
+			if option.Label != ProblemTypes[option.ID][tt.want] {
+				t.Errorf("%q: %d numaralı problem metni %q, beklenen %q", tt.header, option.ID, option.Label, ProblemTypes[option.ID][tt.want])
+			}
+		}
+	}
+}
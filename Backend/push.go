@@ -0,0 +1,214 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+
+	webpush "github.com/SherClockHolmes/webpush-go"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// errPushSubscriptionGone push servisi aboneliğin artık geçerli olmadığını bildirdiğinde döner
+var errPushSubscriptionGone = errors.New("push aboneliği artık geçerli değil")
+
+var (
+	vapidMu         sync.RWMutex
+	vapidPublicKey  string
+	vapidPrivateKey string
+	vapidFromConfig bool
+)
+
+// InitVAPIDKeys Web Push anahtarlarını hazırlar.
+// Yapılandırmada anahtar çifti verilmişse o kullanılır, yoksa veritabanındaki son anahtar çifti
+// kullanılır; hiç anahtar yoksa yeni bir çift üretilip saklanır.
+func InitVAPIDKeys() {
+	vapidMu.Lock()
+	defer vapidMu.Unlock()
+
+	vapidFromConfig = false
+	if keys := config.Notify.VAPID; keys.PublicKey != "" && keys.PrivateKey != "" {
+		vapidPublicKey, vapidPrivateKey, vapidFromConfig = keys.PublicKey, keys.PrivateKey, true
+		return
+	}
+
+	var key VAPIDKey
+	if err := DB.Order("id DESC").First(&key).Error; err != nil {
+		generated, err := createVAPIDKey(DB)
+		if err != nil {
+			log.Printf("VAPID anahtarları oluşturulamadı: %v", err)
+			return
+		}
+		key = generated
+		log.Println("Yeni VAPID anahtar çifti oluşturuldu")
+	}
+
+	vapidPublicKey, vapidPrivateKey = key.PublicKey, key.PrivateKey
+}
+
+// activeVAPIDKeys kullanımdaki VAPID anahtar çiftini döner
+func activeVAPIDKeys() (publicKey, privateKey string) {
+	vapidMu.RLock()
+	defer vapidMu.RUnlock()
+	return vapidPublicKey, vapidPrivateKey
+}
+
+// createVAPIDKey yeni bir anahtar çifti üretip veritabanına kaydeder
+func createVAPIDKey(db *gorm.DB) (VAPIDKey, error) {
+	privateKey, publicKey, err := webpush.GenerateVAPIDKeys()
+	if err != nil {
+		return VAPIDKey{}, err
+	}
+
+	key := VAPIDKey{PublicKey: publicKey, PrivateKey: privateKey}
+	if err := db.Create(&key).Error; err != nil {
+		return VAPIDKey{}, err
+	}
+	return key, nil
+}
+
+// userPushSubscriptions kullanıcının kayıtlı cihaz aboneliklerini getirir
+func userPushSubscriptions(userID uint) []PushSubscription {
+	var subs []PushSubscription
+	if err := DB.Where("user_id = ?", userID).Find(&subs).Error; err != nil {
+		log.Printf("Push abonelikleri getirilemedi (kullanıcı %d): %v", userID, err)
+	}
+	return subs
+}
+
+// webpushSubscription kayıtlı aboneliği webpush kütüphanesinin formatına çevirir
+func (s PushSubscription) webpushSubscription() *webpush.Subscription {
+	return &webpush.Subscription{
+		Endpoint: s.Endpoint,
+		Keys:     webpush.Keys{P256dh: s.P256dh, Auth: s.Auth},
+	}
+}
+
+// getVAPIDPublicKey tarayıcının abone olurken kullanacağı açık anahtarı döner
+func getVAPIDPublicKey(c *gin.Context) {
+	publicKey, _ := activeVAPIDKeys()
+	if publicKey == "" {
+		respondError(c, apiError(CodePushNotConfigured))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"public_key": publicKey,
+	})
+}
+
+// subscribePush cihazın push aboneliğini kullanıcıya kaydeder
+func subscribePush(c *gin.Context) {
+	var req PushSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, bindError(err))
+		return
+	}
+
+	user := authenticatedUser(c)
+
+	// Aynı cihaz tekrar abone olursa kaydı güncelle; başka kullanıcının cihazı devralınamaz
+	var sub PushSubscription
+	err := DB.Where("endpoint = ?", req.Endpoint).First(&sub).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		respondError(c, internalError(err, "Push aboneliği getirilirken hata oluştu"))
+		return
+	}
+	if err == nil && sub.UserID != user.ID {
+		respondError(c, apiError(CodePushEndpointTaken))
+		return
+	}
+	sub.UserID = user.ID
+	sub.Endpoint = req.Endpoint
+	sub.P256dh = req.Keys.P256dh
+	sub.Auth = req.Keys.Auth
+	sub.UserAgent = c.Request.UserAgent()
+
+	err = DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&sub).Error; err != nil {
+			return err
+		}
+
+		// Kullanıcının push tercihi yoksa varsayılan olarak tüm olaylar için aç
+		var count int64
+		tx.Model(&NotificationPreference{}).Where("user_id = ? AND channel = ?", user.ID, ChannelPush).Count(&count)
+		if count > 0 {
+			return nil
+		}
+		return tx.Create(&NotificationPreference{
+			UserID:    user.ID,
+			Channel:   ChannelPush,
+			Events:    "*",
+			IsEnabled: true,
+		}).Error
+	})
+	if err != nil {
+		respondError(c, internalError(err, "Push aboneliği kaydedilirken hata oluştu"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": messageText(c, MsgPushSubscribed),
+		"data":    sub,
+	})
+}
+
+// unsubscribePush cihazın push aboneliğini siler
+func unsubscribePush(c *gin.Context) {
+	var req struct {
+		Endpoint string `json:"endpoint" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, bindError(err))
+		return
+	}
+
+	user := authenticatedUser(c)
+
+	if err := DB.Where("endpoint = ? AND user_id = ?", req.Endpoint, user.ID).Delete(&PushSubscription{}).Error; err != nil {
+		respondError(c, internalError(err, "Push aboneliği silinirken hata oluştu"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": messageText(c, MsgPushUnsubscribed),
+	})
+}
+
+// rotateVAPIDKeys yeni VAPID anahtar çifti üretir; eski anahtarla yapılan abonelikler geçersiz olduğu için silinir (sadece admin erişimi)
+func rotateVAPIDKeys(c *gin.Context) {
+	vapidMu.Lock()
+	defer vapidMu.Unlock()
+
+	if vapidFromConfig {
+		respondError(c, apiError(CodeVAPIDManagedByEnv))
+		return
+	}
+
+	var key VAPIDKey
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		generated, err := createVAPIDKey(tx)
+		if err != nil {
+			return err
+		}
+		key = generated
+		return tx.Where("1 = 1").Delete(&PushSubscription{}).Error
+	})
+	if err != nil {
+		respondError(c, internalError(err, "VAPID anahtarları yenilenirken hata oluştu"))
+		return
+	}
+
+	vapidPublicKey, vapidPrivateKey = key.PublicKey, key.PrivateKey
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"message":    messageText(c, MsgVAPIDRotated),
+		"public_key": key.PublicKey,
+	})
+}
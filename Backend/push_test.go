@@ -0,0 +1,106 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+// pushSubscriptionBody tarayıcının PushSubscription.toJSON() çıktısına benzeyen istek gövdesi
+func pushSubscriptionBody(endpoint, auth string) map[string]interface{} {
+	return map[string]interface{}{
+		"endpoint": endpoint,
+		"keys":     map[string]string{"p256dh": "p256dh-" + auth, "auth": auth},
+	}
+}
+
+func TestPushSubscriptionRequiresSession(t *testing.T) {
+	s := newTestServer(t)
+	cleaner := s.createUser("ayse", "Ayşe", "temizlikci")
+	body := pushSubscriptionBody("https://push.example/cihaz-1", "a1")
+
+	expectError(t, s, http.MethodPost, "/api/push/subscriptions", body, nil, http.StatusUnauthorized, CodeUnauthorized)
+
+	// Sadece X-User-ID başlığı ile başka biri adına abone olunamaz
+	onlyID := map[string]string{"X-User-ID": strconv.FormatUint(uint64(cleaner.ID), 10)}
+	expectError(t, s, http.MethodPost, "/api/push/subscriptions", body, onlyID, http.StatusUnauthorized, CodeUnauthorized)
+	expectError(t, s, http.MethodDelete, "/api/push/subscriptions", map[string]string{"endpoint": "https://push.example/cihaz-1"}, onlyID, http.StatusUnauthorized, CodeUnauthorized)
+
+	var count int64
+	s.db.Model(&PushSubscription{}).Count(&count)
+	if count != 0 {
+		t.Fatalf("Doğrulanmamış istek %d abonelik oluşturdu", count)
+	}
+}
+
+func TestPushSubscriptionCannotBeTakenOver(t *testing.T) {
+	s := newTestServer(t)
+	ayse := s.createUser("ayse", "Ayşe", "temizlikci")
+	mehmet := s.createUser("mehmet", "Mehmet", "temizlikci")
+	const endpoint = "https://push.example/cihaz-1"
+
+	rec := s.request(http.MethodPost, "/api/push/subscriptions", pushSubscriptionBody(endpoint, "a1"), staffHeaders(ayse))
+	expectStatus(t, rec, http.StatusCreated)
+
+	// Aynı kullanıcı tekrar abone olunca anahtarlar güncellenir
+	rec = s.request(http.MethodPost, "/api/push/subscriptions", pushSubscriptionBody(endpoint, "a2"), staffHeaders(ayse))
+	expectStatus(t, rec, http.StatusCreated)
+
+	expectError(t, s, http.MethodPost, "/api/push/subscriptions", pushSubscriptionBody(endpoint, "m1"), staffHeaders(mehmet), http.StatusConflict, CodePushEndpointTaken)
+
+	// Başka kullanıcının silme isteği cihazı etkilemez
+	rec = s.request(http.MethodDelete, "/api/push/subscriptions", map[string]string{"endpoint": endpoint}, staffHeaders(mehmet))
+	expectStatus(t, rec, http.StatusOK)
+
+	var subs []PushSubscription
+	s.db.Find(&subs)
+	if len(subs) != 1 || subs[0].UserID != ayse.ID || subs[0].Auth != "a2" {
+		t.Fatalf("Abonelik ilk kullanıcıda kalmalı: %+v", subs)
+	}
+
+	var prefs int64
+	s.db.Model(&NotificationPreference{}).Where("user_id = ? AND channel = ?", mehmet.ID, ChannelPush).Count(&prefs)
+	if prefs != 0 {
+		t.Fatal("Reddedilen abonelik push tercihi oluşturmamalı")
+	}
+
+	rec = s.request(http.MethodDelete, "/api/push/subscriptions", map[string]string{"endpoint": endpoint}, staffHeaders(ayse))
+	expectStatus(t, rec, http.StatusOK)
+	s.db.Find(&subs)
+	if len(subs) != 0 {
+		t.Fatalf("Abonelik silinmeliydi: %+v", subs)
+	}
+}
+
+func TestRotateVAPIDKeysIsAdminOnly(t *testing.T) {
+	s := newTestServer(t)
+	previousConfig := config
+	t.Cleanup(func() { config = previousConfig })
+	config.Notify.VAPID = VAPIDConfig{}
+	InitVAPIDKeys()
+
+	cleaner := s.createUser("ayse", "Ayşe", "temizlikci")
+	rec := s.request(http.MethodPost, "/api/push/subscriptions", pushSubscriptionBody("https://push.example/cihaz-1", "a1"), staffHeaders(cleaner))
+	expectStatus(t, rec, http.StatusCreated)
+
+	expectError(t, s, http.MethodPost, "/api/admin/push/vapid/rotate", nil, nil, http.StatusUnauthorized, CodeUnauthorized)
+	expectError(t, s, http.MethodPost, "/api/admin/push/vapid/rotate", nil, staffHeaders(cleaner), http.StatusForbidden, CodeForbidden)
+
+	var count int64
+	s.db.Model(&PushSubscription{}).Count(&count)
+	if count != 1 {
+		t.Fatal("Reddedilen yenileme abonelikleri silmemeli")
+	}
+
+	previous, _ := activeVAPIDKeys()
+	rec = s.request(http.MethodPost, "/api/admin/push/vapid/rotate", nil, s.adminHeaders())
+	expectStatus(t, rec, http.StatusOK)
+
+	if current, _ := activeVAPIDKeys(); current == previous {
+		t.Fatal("VAPID anahtarı değişmeliydi")
+	}
+	s.db.Model(&PushSubscription{}).Count(&count)
+	if count != 0 {
+		t.Fatalf("Eski anahtarla yapılan %d abonelik silinmeliydi", count)
+	}
+}
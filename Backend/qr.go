@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-pdf/fpdf"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+const (
+	defaultPublicRatingURL = "http://localhost:5173/rating"
+	defaultQRSize          = 256
+	maxQRSize              = 2048
+)
+
+// toiletRatingURL tuvaletin kapısına basılacak, imzalı token içeren değerlendirme adresini döner
+func toiletRatingURL(toilet Toilet) string {
+	return strings.TrimRight(config.PublicRatingURL, "/") + "?token=" + url.QueryEscape(toiletRatingToken(toilet))
+}
+
+// renderQRSVG QR kodunu ölçeklenebilir SVG olarak çizer
+func renderQRSVG(code *qrcode.QRCode, size int) string {
+	bitmap := code.Bitmap()
+	modules := len(bitmap)
+
+	var svg strings.Builder
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		size, size, modules, modules)
+	fmt.Fprintf(&svg, `<rect width="%d" height="%d" fill="#ffffff"/>`, modules, modules)
+	svg.WriteString(`<path fill="#000000" d="`)
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&svg, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+	svg.WriteString(`"/></svg>`)
+
+	return svg.String()
+}
+
+// getToiletQRCode tuvaletin değerlendirme adresini gösteren QR kodunu PNG veya SVG olarak döner
+func (h *Handlers) getToiletQRCode(c *gin.Context) {
+	toiletIdStr := c.Param("toiletId")
+
+	toiletID, err := strconv.Atoi(toiletIdStr)
+	if err != nil {
+		respondError(c, invalidParam("toiletId"))
+		return
+	}
+
+	size, err := strconv.Atoi(c.DefaultQuery("size", strconv.Itoa(defaultQRSize)))
+	if err != nil || size < 64 || size > maxQRSize {
+		size = defaultQRSize
+	}
+
+	format := c.DefaultQuery("format", "png")
+	if format != "png" && format != "svg" {
+		respondError(c, validationError(fieldError("format", RuleOneOf, "png svg")))
+		return
+	}
+
+	toilet, err := h.Toilets.FindByID(toiletID)
+	if err != nil {
+		respondError(c, lookupError(err, CodeToiletNotFound))
+		return
+	}
+
+	code, err := qrcode.New(toiletRatingURL(toilet), qrcode.Medium)
+	if err != nil {
+		respondError(c, internalError(err, "QR kod oluşturulamadı"))
+		return
+	}
+
+	filename := fmt.Sprintf("tuvalet-%d-qr.%s", toilet.ID, format)
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s"`, filename))
+
+	if format == "svg" {
+		c.Data(http.StatusOK, "image/svg+xml", []byte(renderQRSVG(code, size)))
+		return
+	}
+
+	png, err := code.PNG(size)
+	if err != nil {
+		respondError(c, internalError(err, "QR kod oluşturulamadı"))
+		return
+	}
+
+	c.Data(http.StatusOK, "image/png", png)
+}
+
+// getQRSheet bir kattaki (veya tüm) aktif tuvaletlerin QR kodlarını yazdırılabilir PDF olarak döner (sadece admin erişimi)
+func (h *Handlers) getQRSheet(c *gin.Context) {
+	location := c.Query("location")
+
+	active, err := h.Toilets.ListActive()
+	if err != nil {
+		respondError(c, internalError(err, "Tuvaletler getirilirken hata oluştu"))
+		return
+	}
+
+	toilets := active
+	if location != "" {
+		toilets = []Toilet{}
+		for _, toilet := range active {
+			if toilet.Location == location {
+				toilets = append(toilets, toilet)
+			}
+		}
+	}
+
+	if len(toilets) == 0 {
+		respondError(c, apiError(CodeNoToiletsOnFloor))
+		return
+	}
+
+	pdf, font, tr := newPDFDocument()
+	pdf.SetTitle(tr("Tuvalet QR Kodları"), false)
+	pdf.SetMargins(10, 10, 10)
+	pdf.SetAutoPageBreak(false, 0)
+
+	// Sayfa başına 2x3 etiket
+	const (
+		columns     = 2
+		rows        = 3
+		labelWidth  = 95.0
+		labelHeight = 92.0
+		qrMM        = 60.0
+	)
+
+	for i, toilet := range toilets {
+		slot := i % (columns * rows)
+		if slot == 0 {
+			pdf.AddPage()
+		}
+
+		x := 10 + float64(slot%columns)*labelWidth
+		y := 10 + float64(slot/columns)*labelHeight
+
+		png, err := qrcode.Encode(toiletRatingURL(toilet), qrcode.Medium, 512)
+		if err != nil {
+			respondError(c, internalError(err, "QR kod oluşturulamadı"))
+			return
+		}
+
+		imageName := fmt.Sprintf("qr-%d", toilet.ID)
+		pdf.RegisterImageOptionsReader(imageName, fpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(png))
+
+		// Kesim çizgisi
+		pdf.SetDrawColor(200, 200, 200)
+		pdf.SetDashPattern([]float64{1, 1}, 0)
+		pdf.Rect(x, y, labelWidth, labelHeight, "D")
+		pdf.SetDashPattern([]float64{}, 0)
+
+		pdf.SetFont(font, "B", 13)
+		pdf.SetXY(x, y+4)
+		pdf.CellFormat(labelWidth, 7, tr(toilet.Name), "", 1, "C", false, 0, "")
+
+		pdf.ImageOptions(imageName, x+(labelWidth-qrMM)/2, y+13, qrMM, qrMM, false, fpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+
+		pdf.SetFont(font, "", 10)
+		pdf.SetXY(x, y+75)
+		pdf.CellFormat(labelWidth, 5, tr("Temizliği değerlendirmek için okutun"), "", 1, "C", false, 0, "")
+		pdf.SetXY(x, y+81)
+		pdf.SetFont(font, "", 8)
+		pdf.CellFormat(labelWidth, 5, tr(toilet.Location), "", 1, "C", false, 0, "")
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		respondError(c, internalError(err, "PDF oluşturulamadı"))
+		return
+	}
+
+	filename := "tuvalet-qr-kodlari.pdf"
+	if location != "" {
+		filename = "tuvalet-qr-kodlari-" + strings.ReplaceAll(location, " ", "-") + ".pdf"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
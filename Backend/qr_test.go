@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"net/http"
+	"strings"
+	"testing"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+func TestRenderQRSVG(t *testing.T) {
+	code, err := qrcode.New("http://localhost:5173/rating?token=abc", qrcode.Medium)
+	if err != nil {
+		t.Fatalf("QR kod oluşturulamadı: %v", err)
+	}
+	bitmap := code.Bitmap()
+	modules := len(bitmap)
+
+	svg := renderQRSVG(code, 300)
+
+	header := fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="300" height="300" viewBox="0 0 %d %d"`, modules, modules)
+	if !strings.HasPrefix(svg, header) || !strings.HasSuffix(svg, `"/></svg>`) {
+		t.Fatalf("Beklenmeyen SVG çerçevesi: %.200s", svg)
+	}
+
+	dark := 0
+	for _, row := range bitmap {
+		for _, d := range row {
+			if d {
+				dark++
+			}
+		}
+	}
+	if got := strings.Count(svg, "h1v1h-1z"); got != dark {
+		t.Fatalf("SVG'de %d modül çizildi, bitmap'te %d koyu modül var", got, dark)
+	}
+
+	// Sol üst köşedeki konum işaretinin ilk modülü koyu olmalı
+	if !bitmap[4][4] || !strings.Contains(svg, "M4 4h1v1h-1z") {
+		t.Fatalf("Konum işareti SVG'de bulunamadı")
+	}
+}
+
+func TestToiletQRCodeSize(t *testing.T) {
+	router, repos := newMemoryRouter(t)
+	admin, err := createAdmin(repos.Users, "yonetici", "parola", "Yönetici")
+	if err != nil {
+		t.Fatalf("Admin oluşturulamadı: %v", err)
+	}
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"", defaultQRSize},
+		{"?size=512", 512},
+		{"?size=64", 64},
+		{fmt.Sprintf("?size=%d", maxQRSize), maxQRSize},
+		// Geçersiz veya sınır dışı boyutlar varsayılana döner
+		{"?size=63", defaultQRSize},
+		{fmt.Sprintf("?size=%d", maxQRSize+1), defaultQRSize},
+		{"?size=-100", defaultQRSize},
+		{"?size=büyük", defaultQRSize},
+	}
+
+	for _, tt := range tests {
+		rec := serve(t, router, http.MethodGet, "/api/toilet/1/qr"+tt.query, nil, staffHeaders(admin))
+		expectStatus(t, rec, http.StatusOK)
+		if ct := rec.Header().Get("Content-Type"); ct != "image/png" {
+			t.Fatalf("%q: Content-Type %q", tt.query, ct)
+		}
+
+		img, err := png.Decode(bytes.NewReader(rec.Body.Bytes()))
+		if err != nil {
+			t.Fatalf("%q: PNG çözülemedi: %v", tt.query, err)
+		}
+		if bounds := img.Bounds(); bounds.Dx() != tt.want || bounds.Dy() != tt.want {
+			t.Errorf("%q: görüntü %dx%d, beklenen %dx%d", tt.query, bounds.Dx(), bounds.Dy(), tt.want, tt.want)
+		}
+	}
+
+	rec := serve(t, router, http.MethodGet, "/api/toilet/1/qr?format=svg&size=5000", nil, staffHeaders(admin))
+	expectStatus(t, rec, http.StatusOK)
+	if !strings.Contains(rec.Body.String(), fmt.Sprintf(`width="%d" height="%d"`, defaultQRSize, defaultQRSize)) {
+		t.Fatalf("SVG boyutu varsayılana dönmedi: %.200s", rec.Body.String())
+	}
+}
+
+func TestQRSheetPagination(t *testing.T) {
+	router, repos := newMemoryRouter(t)
+	admin, err := createAdmin(repos.Users, "yonetici", "parola", "Yönetici")
+	if err != nil {
+		t.Fatalf("Admin oluşturulamadı: %v", err)
+	}
+
+	// newMemoryRouter 1. katta 3 tuvalet ekler; 2. kata 7 tuvalet eklenir
+	for id := 4; id <= 10; id++ {
+		repos.Toilets.(*memoryToiletRepository).AddToilet(Toilet{ID: id, Name: fmt.Sprintf("Tuvalet %d", id), Location: "2. Kat", IsActive: true, TokenVersion: 1})
+	}
+
+	tests := []struct {
+		query  string
+		pages  int
+		images int
+	}{
+		{"?location=1.%20Kat", 1, 3},
+		{"?location=2.%20Kat", 2, 7},
+		{"", 2, 10},
+	}
+
+	for _, tt := range tests {
+		rec := serve(t, router, http.MethodGet, "/api/admin/qr/sheet"+tt.query, nil, staffHeaders(admin))
+		expectStatus(t, rec, http.StatusOK)
+
+		body := rec.Body.String()
+		if pages := strings.Count(body, "<</Type /Page\n"); pages != tt.pages {
+			t.Errorf("%q: %d sayfa, beklenen %d", tt.query, pages, tt.pages)
+		}
+		if images := strings.Count(body, "/Subtype /Image"); images != tt.images {
+			t.Errorf("%q: %d QR kod, beklenen %d", tt.query, images, tt.images)
+		}
+	}
+
+	rec := serve(t, router, http.MethodGet, "/api/admin/qr/sheet?location=3.%20Kat", nil, staffHeaders(admin))
+	expectStatus(t, rec, http.StatusNotFound)
+	var resp ErrorResponse
+	decode(t, rec, &resp)
+	if resp.Code != CodeNoToiletsOnFloor {
+		t.Fatalf("Boş kat için %s beklenirdi: %+v", CodeNoToiletsOnFloor, resp)
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Token doğrulama hataları
+var (
+	errRatingTokenInvalid = errors.New("geçersiz değerlendirme kodu")
+	errRatingTokenRevoked = errors.New("değerlendirme kodu artık geçerli değil")
+)
+
+var ratingTokenSecret []byte
+
+// InitRatingTokens QR tokenlarını imzalamak için kullanılan anahtarı yükler
+func InitRatingTokens() {
+	// Uzunluk LoadConfig içinde doğrulanır
+	ratingTokenSecret = []byte(config.RatingTokenSecret)
+}
+
+// ratingTokenSignature tuvalet ID ve token sürümü için kısaltılmış HMAC imzası üretir
+func ratingTokenSignature(toiletID, version int) string {
+	mac := hmac.New(sha256.New, ratingTokenSecret)
+	mac.Write([]byte(strconv.Itoa(toiletID) + "." + strconv.Itoa(version)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// toiletRatingToken tuvaletin QR koduna gömülecek imzalı tokenı döner.
+// Format: <tuvalet id>.<sürüm>.<imza>
+func toiletRatingToken(toilet Toilet) string {
+	return strconv.Itoa(toilet.ID) + "." + strconv.Itoa(toilet.TokenVersion) + "." + ratingTokenSignature(toilet.ID, toilet.TokenVersion)
+}
+
+// toiletFromRatingToken tokenı doğrular ve ait olduğu aktif tuvaleti döner
+func toiletFromRatingToken(toilets ToiletRepository, token string) (Toilet, error) {
+	var toilet Toilet
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return toilet, errRatingTokenInvalid
+	}
+
+	toiletID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return toilet, errRatingTokenInvalid
+	}
+	version, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return toilet, errRatingTokenInvalid
+	}
+
+	if !hmac.Equal([]byte(parts[2]), []byte(ratingTokenSignature(toiletID, version))) {
+		return toilet, errRatingTokenInvalid
+	}
+
+	toilet, err = toilets.FindActiveByID(toiletID)
+	if err != nil {
+		return toilet, errRatingTokenInvalid
+	}
+
+	// Token yenilenmiş veya iptal edilmişse eski QR kodlar reddedilir
+	if toilet.TokenVersion != version || toilet.TokenRevokedAt != nil {
+		return toilet, errRatingTokenRevoked
+	}
+
+	return toilet, nil
+}
+
+// ratingTokenError doğrulama hatasına karşılık gelen API hatasını döner
+func ratingTokenError(err error) *APIError {
+	if errors.Is(err, errRatingTokenRevoked) {
+		return apiError(CodeRatingTokenRevoked)
+	}
+	return apiError(CodeRatingTokenInvalid)
+}
+
+// resolveRatingToken QR koddaki tokenın hangi tuvalete ait olduğunu döner
+func (h *Handlers) resolveRatingToken(c *gin.Context) {
+	toilet, err := toiletFromRatingToken(h.Toilets, c.Param("token"))
+	if err != nil {
+		respondError(c, ratingTokenError(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    toilet,
+	})
+}
+
+// loadToiletForToken admin token işlemleri için tuvaleti getirir
+func (h *Handlers) loadToiletForToken(c *gin.Context) (Toilet, bool) {
+	var toilet Toilet
+
+	toiletID, err := strconv.Atoi(c.Param("toiletId"))
+	if err != nil {
+		respondError(c, invalidParam("toiletId"))
+		return toilet, false
+	}
+
+	toilet, err = h.Toilets.FindByID(toiletID)
+	if err != nil {
+		respondError(c, lookupError(err, CodeToiletNotFound))
+		return toilet, false
+	}
+
+	return toilet, true
+}
+
+// ratingTokenResponse tuvaletin güncel token bilgisini yanıt olarak hazırlar
+func ratingTokenResponse(toilet Toilet, message string) RatingTokenResponse {
+	return RatingTokenResponse{
+		Success:   true,
+		Message:   message,
+		ToiletID:  toilet.ID,
+		Token:     toiletRatingToken(toilet),
+		URL:       toiletRatingURL(toilet),
+		Version:   toilet.TokenVersion,
+		RevokedAt: toilet.TokenRevokedAt,
+	}
+}
+
+// getToiletRatingToken tuvaletin güncel QR tokenını getirir (sadece admin erişimi)
+func (h *Handlers) getToiletRatingToken(c *gin.Context) {
+	toilet, ok := h.loadToiletForToken(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, ratingTokenResponse(toilet, messageText(c, MsgTokenFetched)))
+}
+
+// rotateToiletRatingToken tuvalete yeni token verir, eski QR kodlar geçersiz olur (sadece admin erişimi)
+func (h *Handlers) rotateToiletRatingToken(c *gin.Context) {
+	toilet, ok := h.loadToiletForToken(c)
+	if !ok {
+		return
+	}
+
+	toilet.TokenVersion++
+	toilet.TokenRevokedAt = nil
+
+	if err := h.Toilets.Save(&toilet); err != nil {
+		respondError(c, internalError(err, "Token yenilenirken hata oluştu"))
+		return
+	}
+
+	c.JSON(http.StatusOK, ratingTokenResponse(toilet, messageText(c, MsgTokenRotated)))
+}
+
+// revokeToiletRatingToken tuvaletin tokenını iptal eder; yenilenene kadar QR ile puanlama yapılamaz (sadece admin erişimi)
+func (h *Handlers) revokeToiletRatingToken(c *gin.Context) {
+	toilet, ok := h.loadToiletForToken(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	toilet.TokenRevokedAt = &now
+
+	if err := h.Toilets.Save(&toilet); err != nil {
+		respondError(c, internalError(err, "Token iptal edilirken hata oluştu"))
+		return
+	}
+
+	// İptal edilen token ve adresi yanıtta dönülmez
+	c.JSON(http.StatusOK, RatingTokenResponse{
+		Success:   true,
+		Message:   messageText(c, MsgTokenRevoked),
+		ToiletID:  toilet.ID,
+		Version:   toilet.TokenVersion,
+		RevokedAt: toilet.TokenRevokedAt,
+	})
+}
@@ -0,0 +1,612 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	reportCheckInterval = time.Hour
+	defaultReportDir    = "reports"
+	reportTopProblems   = 5
+)
+
+var (
+	reportDir        = defaultReportDir
+	reportPeriods    []string
+	reportRecipients []string
+)
+
+// reportPeriodNames rapor dönemlerinin PDF ve e-postada görünen adları
+var reportPeriodNames = map[string]string{
+	"weekly":  "Haftalık",
+	"monthly": "Aylık",
+}
+
+// InitReports zamanlanmış rapor ayarlarını doğrulanmış yapılandırmadan alır.
+// Dönem listesi ["off"] ise zamanlanmış raporlar kapalıdır; elle oluşturma her zaman açıktır.
+func InitReports() {
+	reportDir = config.Reports.Dir
+	if err := os.MkdirAll(reportDir, 0o755); err != nil {
+		log.Printf("Rapor klasörü oluşturulamadı (%s): %v", reportDir, err)
+	}
+
+	reportPeriods = nil
+	if !slices.Equal(config.Reports.Schedule, []string{"off"}) {
+		reportPeriods = slices.Clone(config.Reports.Schedule)
+	}
+	reportRecipients = slices.Clone(config.Reports.EmailTo)
+	resolveSLA = time.Duration(config.Reports.ResolveSLAMinutes) * time.Minute
+}
+
+// reportPeriodBounds t anını içeren dönemin başlangıç ve bitişini döner (haftalar pazartesi başlar)
+func reportPeriodBounds(period string, t time.Time) (time.Time, time.Time) {
+	if period == "monthly" {
+		start := startOfMonth(t)
+		return start, start.AddDate(0, 1, 0)
+	}
+
+	start := startOfWeek(t)
+	return start, start.AddDate(0, 0, 7)
+}
+
+// lastCompletedPeriod now anından önce tamamlanmış son dönemi döner
+func lastCompletedPeriod(period string, now time.Time) (time.Time, time.Time) {
+	currentStart, _ := reportPeriodBounds(period, now)
+	return reportPeriodBounds(period, currentStart.Add(-time.Nanosecond))
+}
+
+// startReportScheduler tamamlanan dönemlerin raporlarını saatlik kontrolle oluşturan arka plan işçisini başlatır;
+// ctx iptal edilince durur
+func startReportScheduler(ctx context.Context) {
+	if len(reportPeriods) == 0 {
+		return
+	}
+
+	goBackground(func() {
+		runScheduledReports()
+
+		ticker := time.NewTicker(reportCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				runScheduledReports()
+			}
+		}
+	})
+}
+
+// runScheduledReports henüz raporu olmayan son tamamlanmış dönemler için rapor oluşturur
+func runScheduledReports() {
+	for _, period := range reportPeriods {
+		start, _ := lastCompletedPeriod(period, facilityNow())
+
+		var count int64
+		if err := DB.Model(&ManagementReport{}).Where("period = ? AND period_start = ?", period, start).Count(&count).Error; err != nil {
+			log.Printf("Rapor kontrolü başarısız (%s): %v", period, err)
+			continue
+		}
+		if count > 0 {
+			continue
+		}
+
+		report, err := generateManagementReport(period, start)
+		if err != nil {
+			log.Printf("Rapor oluşturulamadı (%s, %s): %v", period, start.Format("2006-01-02"), err)
+			continue
+		}
+		log.Printf("Rapor oluşturuldu: %s", report.FileName)
+
+		if len(reportRecipients) > 0 {
+			if err := emailManagementReport(&report); err != nil {
+				log.Printf("Rapor e-postası gönderilemedi (%s): %v", report.FileName, err)
+			}
+		}
+	}
+}
+
+// reportProblemCount raporda gösterilen problem tipi sayımı
+type reportProblemCount struct {
+	Label string
+	Count int
+}
+
+// reportSection raporda genel ya da tek bir binaya ait özet, eğilim, problem ve temizlikçi verileri
+type reportSection struct {
+	Building          string // Genel bölümde boş
+	ToiletCount       int
+	RatingCount       int
+	AverageRating     *float64
+	ProblemCount      int
+	TasksCompleted    int
+	ProblemTasks      int
+	ResolvedWithinSLA int
+	Trend             []AnalyticsBucket
+	TopProblems       []reportProblemCount
+	Cleaners          []CleanerPerformance
+}
+
+// managementReportData PDF'e basılacak rapor verileri
+type managementReportData struct {
+	reportSection
+	Period    string
+	From      time.Time
+	To        time.Time
+	Buildings []reportSection
+}
+
+// collectReportData dönem için tüm tesisin ve her binanın verilerini toplar
+func collectReportData(period string, from, to time.Time) (managementReportData, error) {
+	data := managementReportData{Period: period, From: from, To: to}
+
+	var toilets []Toilet
+	if err := DB.Order("building ASC, id ASC").Find(&toilets).Error; err != nil {
+		return data, err
+	}
+
+	buildingToilets := map[string][]int{}
+	var buildings []string
+	for _, toilet := range toilets {
+		if _, ok := buildingToilets[toilet.Building]; !ok {
+			buildings = append(buildings, toilet.Building)
+		}
+		buildingToilets[toilet.Building] = append(buildingToilets[toilet.Building], toilet.ID)
+	}
+
+	overall, err := collectReportSection(period, analyticsFilter{From: from, To: to})
+	if err != nil {
+		return data, err
+	}
+	overall.ToiletCount = len(toilets)
+	data.reportSection = overall
+
+	for _, building := range buildings {
+		section, err := collectReportSection(period, analyticsFilter{From: from, To: to, ToiletIDs: buildingToilets[building]})
+		if err != nil {
+			return data, err
+		}
+		section.Building = building
+		section.ToiletCount = len(buildingToilets[building])
+		data.Buildings = append(data.Buildings, section)
+	}
+
+	return data, nil
+}
+
+// collectReportSection filtredeki tuvaletler için özet, eğilim, en sık problemler ve temizlikçi sıralamasını toplar
+func collectReportSection(period string, filter analyticsFilter) (reportSection, error) {
+	var section reportSection
+
+	trendBucket := "day"
+	if period == "monthly" {
+		trendBucket = "week"
+	}
+
+	trend, err := buildAnalyticsSeries(filter, trendBucket)
+	if err != nil {
+		return section, err
+	}
+	section.Trend = trend
+
+	ratingSum := 0.0
+	problems := map[int]int{}
+	for _, bucket := range trend {
+		section.RatingCount += bucket.RatingCount
+		section.ProblemCount += bucket.ProblemCount
+		section.TasksCompleted += bucket.TasksCompleted
+		if bucket.AverageRating != nil {
+			ratingSum += *bucket.AverageRating * float64(bucket.RatingCount)
+		}
+		for problemID, count := range bucket.ProblemsByType {
+			problems[problemID] += count
+		}
+	}
+	if section.RatingCount > 0 {
+		avg := ratingSum / float64(section.RatingCount)
+		section.AverageRating = &avg
+	}
+
+	for problemID, count := range problems {
+		label := problemLabelOrUnknown(problemID, defaultLanguage)
+		section.TopProblems = append(section.TopProblems, reportProblemCount{Label: label, Count: count})
+	}
+	sort.Slice(section.TopProblems, func(i, j int) bool {
+		if section.TopProblems[i].Count != section.TopProblems[j].Count {
+			return section.TopProblems[i].Count > section.TopProblems[j].Count
+		}
+		return section.TopProblems[i].Label < section.TopProblems[j].Label
+	})
+	if len(section.TopProblems) > reportTopProblems {
+		section.TopProblems = section.TopProblems[:reportTopProblems]
+	}
+
+	cleaners, err := buildCleanerPerformance(filter, defaultPostCleaningWindowHours*time.Hour)
+	if err != nil {
+		return section, err
+	}
+	for _, cleaner := range cleaners {
+		section.ProblemTasks += cleaner.ProblemTasks
+		section.ResolvedWithinSLA += cleaner.ResolvedWithinSLA
+	}
+
+	// Sıralama: en çok görev, eşitlikte temizlik sonrası en yüksek puan
+	sort.SliceStable(cleaners, func(i, j int) bool {
+		if cleaners[i].TasksCompleted != cleaners[j].TasksCompleted {
+			return cleaners[i].TasksCompleted > cleaners[j].TasksCompleted
+		}
+		return optionalValue(cleaners[i].PostCleaningAverageRating) > optionalValue(cleaners[j].PostCleaningAverageRating)
+	})
+	section.Cleaners = cleaners
+
+	return section, nil
+}
+
+// optionalValue nil olabilen değeri sıralama için sayıya çevirir
+func optionalValue(v *float64) float64 {
+	if v == nil {
+		return -1
+	}
+	return *v
+}
+
+// formatOptional nil olabilen değeri verilen ondalık hassasiyetiyle yazar
+func formatOptional(v *float64, decimals int) string {
+	if v == nil {
+		return "-"
+	}
+	return strconv.FormatFloat(*v, 'f', decimals, 64)
+}
+
+// formatSLA hedef süre içinde çözülen görev oranını yazar
+func formatSLA(within, total int) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%%%.0f (%d/%d)", float64(within)*100/float64(total), within, total)
+}
+
+// renderManagementReport rapor verilerini PDF olarak çizer
+func renderManagementReport(data managementReportData) ([]byte, error) {
+	pdf, font, tr := newPDFDocument()
+	title := reportPeriodNames[data.Period] + " Yönetim Raporu"
+	pdf.SetTitle(tr(title), false)
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AddPage()
+
+	const pageWidth = 180.0
+
+	section := func(text string) {
+		pdf.Ln(6)
+		pdf.SetFont(font, "B", 13)
+		pdf.SetTextColor(25, 118, 210)
+		pdf.CellFormat(pageWidth, 8, tr(text), "B", 1, "L", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+		pdf.Ln(2)
+	}
+
+	// leftColumns baştaki sola yaslı metin sütunlarının sayısıdır, diğer hücreler ortalanır
+	table := func(widths []float64, leftColumns int, header []string, rows [][]string) {
+		pdf.SetFont(font, "B", 9)
+		pdf.SetFillColor(240, 240, 240)
+		for i, h := range header {
+			pdf.CellFormat(widths[i], 7, tr(h), "1", 0, "C", true, 0, "")
+		}
+		pdf.Ln(-1)
+
+		pdf.SetFont(font, "", 9)
+		for _, row := range rows {
+			for i, cell := range row {
+				align := "C"
+				if i < leftColumns {
+					align = "L"
+				}
+				pdf.CellFormat(widths[i], 6, tr(cell), "1", 0, align, false, 0, "")
+			}
+			pdf.Ln(-1)
+		}
+	}
+
+	// Başlık
+	pdf.SetFont(font, "B", 18)
+	pdf.CellFormat(pageWidth, 10, tr(title), "", 1, "L", false, 0, "")
+	pdf.SetFont(font, "", 10)
+	pdf.CellFormat(pageWidth, 6, tr(fmt.Sprintf("Dönem: %s - %s",
+		data.From.Format("02.01.2006"), data.To.Add(-time.Second).Format("02.01.2006"))), "", 1, "L", false, 0, "")
+	pdf.CellFormat(pageWidth, 6, tr("Oluşturulma: "+facilityNow().Format("02.01.2006 15:04")), "", 1, "L", false, 0, "")
+
+	// sectionBody özet, eğilim, problem ve temizlikçi bölümlerini genel rapor ve her bina için aynı düzende çizer
+	sectionBody := func(sec reportSection) {
+		section("Özet")
+		summary := [][2]string{
+			{"Tuvalet sayısı", strconv.Itoa(sec.ToiletCount)},
+			{"Toplam değerlendirme", strconv.Itoa(sec.RatingCount)},
+			{"Ortalama puan", formatOptional(sec.AverageRating, 2)},
+			{"Bildirilen problem", strconv.Itoa(sec.ProblemCount)},
+			{"Tamamlanan temizlik", strconv.Itoa(sec.TasksCompleted)},
+			{fmt.Sprintf("SLA uyumu (hedef %.0f dk)", resolveSLA.Minutes()), formatSLA(sec.ResolvedWithinSLA, sec.ProblemTasks)},
+		}
+		for _, row := range summary {
+			pdf.SetFont(font, "", 10)
+			pdf.CellFormat(70, 6, tr(row[0]), "", 0, "L", false, 0, "")
+			pdf.SetFont(font, "B", 10)
+			pdf.CellFormat(pageWidth-70, 6, tr(row[1]), "", 1, "L", false, 0, "")
+		}
+
+		// Ortalama puan eğilimi
+		section("Ortalama Puan Eğilimi")
+		const labelWidth, barMax = 30.0, 130.0
+		pdf.SetFont(font, "", 9)
+		for _, bucket := range sec.Trend {
+			label := bucket.Start.Format("02.01")
+			if data.Period == "monthly" {
+				label = bucket.Start.Format("02.01") + " haftası"
+			}
+			pdf.CellFormat(labelWidth, 6, tr(label), "", 0, "L", false, 0, "")
+
+			x, y := pdf.GetXY()
+			if bucket.AverageRating != nil {
+				pdf.SetFillColor(25, 118, 210)
+				pdf.Rect(x, y+1, barMax*(*bucket.AverageRating)/5, 4, "F")
+			}
+			pdf.SetX(x + barMax + 2)
+			pdf.CellFormat(pageWidth-labelWidth-barMax-2, 6,
+				tr(fmt.Sprintf("%s (%d)", formatOptional(bucket.AverageRating, 2), bucket.RatingCount)), "", 1, "L", false, 0, "")
+		}
+
+		// En sık problemler
+		section("En Sık Bildirilen Problemler")
+		if len(sec.TopProblems) == 0 {
+			pdf.SetFont(font, "", 10)
+			pdf.CellFormat(pageWidth, 6, tr("Bu dönemde problem bildirilmedi"), "", 1, "L", false, 0, "")
+		} else {
+			rows := make([][]string, 0, len(sec.TopProblems))
+			for _, problem := range sec.TopProblems {
+				rows = append(rows, []string{problem.Label, strconv.Itoa(problem.Count)})
+			}
+			table([]float64{140, 40}, 1, []string{"Problem", "Bildirim"}, rows)
+		}
+
+		// Temizlikçi sıralaması
+		section("Temizlikçi Sıralaması")
+		if len(sec.Cleaners) == 0 {
+			pdf.SetFont(font, "", 10)
+			pdf.CellFormat(pageWidth, 6, tr("Bu dönemde tamamlanan temizlik yok"), "", 1, "L", false, 0, "")
+		} else {
+			rows := make([][]string, 0, len(sec.Cleaners))
+			for i, cleaner := range sec.Cleaners {
+				rows = append(rows, []string{
+					strconv.Itoa(i + 1),
+					cleaner.CleanerName,
+					strconv.Itoa(cleaner.TasksCompleted),
+					formatOptional(cleaner.MedianResponseMinutes, 1),
+					formatOptional(cleaner.PostCleaningAverageRating, 2),
+					formatSLA(cleaner.ResolvedWithinSLA, cleaner.ProblemTasks),
+				})
+			}
+			table([]float64{10, 55, 20, 30, 30, 35}, 2,
+				[]string{"#", "Temizlikçi", "Görev", "Müdahale (dk)", "Sonraki Puan", "SLA Uyumu"}, rows)
+		}
+	}
+
+	sectionBody(data.reportSection)
+
+	// Bina bazında özet
+	section("Bina Bazında Özet")
+	buildingRows := make([][]string, 0, len(data.Buildings))
+	for _, building := range data.Buildings {
+		buildingRows = append(buildingRows, []string{
+			building.Building,
+			strconv.Itoa(building.ToiletCount),
+			strconv.Itoa(building.RatingCount),
+			formatOptional(building.AverageRating, 2),
+			strconv.Itoa(building.ProblemCount),
+			formatSLA(building.ResolvedWithinSLA, building.ProblemTasks),
+		})
+	}
+	table([]float64{50, 20, 30, 25, 25, 30}, 1, []string{"Bina", "Tuvalet", "Değerlendirme", "Ort. Puan", "Problem", "SLA Uyumu"}, buildingRows)
+
+	// Tek bina varsa ayrıntısı genel bölümle aynı olduğu için tekrar basılmaz
+	if len(data.Buildings) > 1 {
+		for _, building := range data.Buildings {
+			pdf.AddPage()
+			pdf.SetFont(font, "B", 16)
+			pdf.CellFormat(pageWidth, 10, tr(building.Building), "", 1, "L", false, 0, "")
+			sectionBody(building)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// managementReportFileName dönem için PDF dosya adını döner
+func managementReportFileName(period string, start time.Time) string {
+	if period == "monthly" {
+		return "aylik-rapor-" + start.Format("2006-01") + ".pdf"
+	}
+	return "haftalik-rapor-" + start.Format("2006-01-02") + ".pdf"
+}
+
+// generateManagementReport dönemin raporunu oluşturup diske yazar ve kaydını günceller
+func generateManagementReport(period string, start time.Time) (ManagementReport, error) {
+	from, to := reportPeriodBounds(period, start)
+
+	var report ManagementReport
+	data, err := collectReportData(period, from, to)
+	if err != nil {
+		return report, err
+	}
+
+	content, err := renderManagementReport(data)
+	if err != nil {
+		return report, err
+	}
+
+	fileName := managementReportFileName(period, from)
+	if err := os.WriteFile(filepath.Join(reportDir, fileName), content, 0o644); err != nil {
+		return report, err
+	}
+
+	// Aynı dönem tekrar oluşturulursa kayıt güncellenir
+	DB.Where("period = ? AND period_start = ?", period, from).First(&report)
+	report.Period = period
+	report.PeriodStart = from
+	report.PeriodEnd = to
+	report.FileName = fileName
+	report.Size = int64(len(content))
+
+	if err := DB.Save(&report).Error; err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// emailManagementReport raporu yapılandırılan rapor alıcılarına e-posta kanalı üzerinden gönderir
+func emailManagementReport(report *ManagementReport) error {
+	if len(reportRecipients) == 0 {
+		return errors.New("rapor alıcısı tanımlı değil (REPORT_EMAIL_TO)")
+	}
+
+	content, err := os.ReadFile(filepath.Join(reportDir, report.FileName))
+	if err != nil {
+		return err
+	}
+
+	notifier, ok := notifiers[ChannelEmail]
+	if !ok {
+		return errors.New("e-posta kanalı yapılandırılmamış")
+	}
+
+	periodText := report.PeriodStart.Format("02.01.2006") + " - " + report.PeriodEnd.Add(-time.Second).Format("02.01.2006")
+	n := Notification{
+		Event:   "report." + report.Period,
+		Subject: reportPeriodNames[report.Period] + " yönetim raporu: " + periodText,
+		Body:    periodText + " dönemine ait yönetim raporu ektedir.",
+		Attachments: []NotificationAttachment{{
+			Name:        report.FileName,
+			ContentType: "application/pdf",
+			Data:        content,
+		}},
+	}
+
+	for _, recipient := range reportRecipients {
+		if err := notifier.Send(recipient, n); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	report.EmailedAt = &now
+	return DB.Model(report).Update("emailed_at", now).Error
+}
+
+// getManagementReports oluşturulmuş yönetim raporlarını listeler (sadece admin erişimi)
+func getManagementReports(c *gin.Context) {
+	query := DB.Order("period_start DESC, id DESC").Limit(100)
+	if period := c.Query("period"); period != "" {
+		query = query.Where("period = ?", period)
+	}
+
+	var reports []ManagementReport
+	if err := query.Find(&reports).Error; err != nil {
+		respondError(c, internalError(err, "Raporlar getirilirken hata oluştu"))
+		return
+	}
+
+	c.JSON(http.StatusOK, ManagementReportsResponse{
+		Success: true,
+		Message: messageText(c, MsgReportsFetched),
+		Data:    reports,
+	})
+}
+
+// downloadManagementReport raporun PDF dosyasını indirir (sadece admin erişimi)
+func downloadManagementReport(c *gin.Context) {
+	reportID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		respondError(c, invalidParam("id"))
+		return
+	}
+
+	var report ManagementReport
+	if err := DB.First(&report, uint(reportID)).Error; err != nil {
+		respondError(c, lookupError(err, CodeReportNotFound))
+		return
+	}
+
+	path := filepath.Join(reportDir, report.FileName)
+	if _, err := os.Stat(path); err != nil {
+		respondError(c, apiError(CodeReportFileMissing))
+		return
+	}
+
+	c.FileAttachment(path, report.FileName)
+}
+
+// createManagementReport verilen (veya son tamamlanan) dönem için raporu hemen oluşturur (sadece admin erişimi)
+func createManagementReport(c *gin.Context) {
+	var req ManagementReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, bindError(err))
+		return
+	}
+
+	start, end := lastCompletedPeriod(req.Period, facilityNow())
+	if req.PeriodStart != "" {
+		parsed, err := parseTimeParam(req.PeriodStart, false)
+		if err != nil {
+			respondError(c, validationError(fieldError("period_start", RuleInvalidFormat, "")))
+			return
+		}
+		start, end = reportPeriodBounds(req.Period, parsed)
+	}
+
+	// Devam eden dönemin raporu eksik kalır ve dönem başına tek rapor tutulduğu için
+	// zamanlanmış rapor da bu dönemi atlar; sadece bitmiş dönemler raporlanır
+	if end.After(time.Now()) {
+		respondError(c, apiError(CodeFutureReportPeriod))
+		return
+	}
+
+	report, err := generateManagementReport(req.Period, start)
+	if err != nil {
+		respondError(c, internalError(err, "Rapor oluşturulurken hata oluştu"))
+		return
+	}
+
+	message := messageText(c, MsgReportCreated)
+	if req.SendEmail {
+		if err := emailManagementReport(&report); err != nil {
+			log.Printf("Rapor e-postası gönderilemedi (%s): %v", report.FileName, err)
+			message = messageText(c, MsgReportEmailFailed)
+		} else {
+			message = messageText(c, MsgReportEmailed)
+		}
+	}
+
+	c.JSON(http.StatusCreated, ManagementReportsResponse{
+		Success: true,
+		Message: message,
+		Report:  &report,
+	})
+}
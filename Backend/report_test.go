@@ -0,0 +1,283 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+)
+
+// useReportSettings rapor klasörünü geçici klasöre alır, dönem ve alıcıları test süresince değiştirir
+func useReportSettings(t *testing.T, periods, recipients []string) string {
+	t.Helper()
+
+	previousDir, previousPeriods, previousRecipients := reportDir, reportPeriods, reportRecipients
+	t.Cleanup(func() {
+		reportDir, reportPeriods, reportRecipients = previousDir, previousPeriods, previousRecipients
+	})
+
+	reportDir = t.TempDir()
+	reportPeriods = periods
+	reportRecipients = recipients
+	return reportDir
+}
+
+// moveToBuilding tuvaletleri verilen binaya taşır
+func (s *testServer) moveToBuilding(building string, toiletIDs ...int) {
+	s.t.Helper()
+
+	if err := s.db.Model(&Toilet{}).Where("id IN ?", toiletIDs).Update("building", building).Error; err != nil {
+		s.t.Fatalf("Tuvaletler binaya taşınamadı: %v", err)
+	}
+}
+
+// pdfPageCount PDF çıktısındaki sayfa nesnelerini sayar
+func pdfPageCount(content []byte) int {
+	return len(regexp.MustCompile(`/Type /Page\b`).FindAll(content, -1))
+}
+
+func TestReportPeriodBounds(t *testing.T) {
+	// Berlin'de yaz saati 29 Mart 2026 pazar günü başlar
+	loc := setFacilityTimezone(t, "Europe/Berlin")
+	day := func(year int, month time.Month, d, hour int) time.Time {
+		return time.Date(year, month, d, hour, 0, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name          string
+		period        string
+		now           time.Time
+		wantFrom, to  time.Time
+		wantDuration  time.Duration
+		checkDuration bool
+	}{
+		{"hafta ortası", "weekly", day(2026, 3, 11, 14), day(2026, 3, 2, 0), day(2026, 3, 9, 0), 0, false},
+		{"pazartesi gece yarısı", "weekly", day(2026, 3, 9, 0), day(2026, 3, 2, 0), day(2026, 3, 9, 0), 0, false},
+		{"yaz saatine geçilen hafta", "weekly", day(2026, 3, 31, 9), day(2026, 3, 23, 0), day(2026, 3, 30, 0), 167 * time.Hour, true},
+		{"ay sonu", "monthly", day(2026, 3, 31, 23), day(2026, 2, 1, 0), day(2026, 3, 1, 0), 0, false},
+		{"yılbaşı", "monthly", day(2026, 1, 1, 0), day(2025, 12, 1, 0), day(2026, 1, 1, 0), 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to := lastCompletedPeriod(tt.period, tt.now)
+			if !from.Equal(tt.wantFrom) || !to.Equal(tt.to) {
+				t.Fatalf("Dönem %s - %s, beklenen %s - %s", from, to, tt.wantFrom, tt.to)
+			}
+			if tt.checkDuration && to.Sub(from) != tt.wantDuration {
+				t.Fatalf("Dönem süresi %s, beklenen %s", to.Sub(from), tt.wantDuration)
+			}
+			// Dönemin herhangi bir anından aynı sınırlar bulunur
+			if f, e := reportPeriodBounds(tt.period, to.Add(-time.Minute)); !f.Equal(from) || !e.Equal(to) {
+				t.Fatalf("Dönem sonundan bulunan sınırlar %s - %s", f, e)
+			}
+		})
+	}
+}
+
+func TestCollectReportDataPerBuilding(t *testing.T) {
+	s := newTestServer(t)
+	loc := setFacilityTimezone(t, "Europe/Istanbul")
+	ayse := s.createUser("ayse", "Ayşe", "temizlikci")
+	mehmet := s.createUser("mehmet", "Mehmet", "temizlikci")
+	s.moveToBuilding("Ek Bina", 5, 6)
+
+	// 2026-03-09 pazartesi
+	at := func(day, hour, minute int) time.Time { return time.Date(2026, 3, day, hour, minute, 0, 0, loc) }
+	s.insertRating(1, 4, "[]", at(10, 9, 0))
+	s.insertRating(2, 2, "[1]", at(11, 10, 0))
+	s.insertRating(5, 1, "[2,3]", at(12, 8, 0))
+	s.insertRating(6, 5, "[]", at(13, 9, 0))
+	s.insertRating(1, 1, "[1]", at(17, 9, 0))                                            // dönem dışında
+	s.insertTask(ayse, 2, "completed", timePtr(at(11, 10, 10)), timePtr(at(11, 10, 30))) // hedef süre içinde
+	s.insertTask(mehmet, 5, "completed", timePtr(at(12, 8, 30)), timePtr(at(12, 9, 30))) // hedef süre aşıldı
+
+	from, to := reportPeriodBounds("weekly", at(11, 0, 0))
+	data, err := collectReportData("weekly", from, to)
+	if err != nil {
+		t.Fatalf("Rapor verileri toplanamadı: %v", err)
+	}
+
+	type want struct {
+		building                             string
+		toilets, ratings, problems, tasks    int
+		average                              float64
+		problemTasks, withinSLA, trendBucket int
+		cleaners                             []string
+		topProblem                           string
+	}
+	wants := []want{
+		{"", 6, 4, 3, 2, 3, 2, 1, 7, []string{"Ayşe", "Mehmet"}, ""}, // problemler eşit sayıda
+		{defaultBuilding, 4, 2, 1, 1, 3, 1, 1, 7, []string{"Ayşe"}, problemLabelOrUnknown(1, defaultLanguage)},
+		{"Ek Bina", 2, 2, 2, 1, 3, 1, 0, 7, []string{"Mehmet"}, ""},
+	}
+
+	sections := append([]reportSection{data.reportSection}, data.Buildings...)
+	if len(sections) != len(wants) {
+		t.Fatalf("%d bölüm, beklenen %d", len(sections), len(wants))
+	}
+	for i, w := range wants {
+		sec := sections[i]
+		if sec.Building != w.building || sec.ToiletCount != w.toilets || sec.RatingCount != w.ratings ||
+			sec.ProblemCount != w.problems || sec.TasksCompleted != w.tasks || sec.ProblemTasks != w.problemTasks ||
+			sec.ResolvedWithinSLA != w.withinSLA || len(sec.Trend) != w.trendBucket {
+			t.Fatalf("%d. bölüm %+v, beklenen %+v", i, sec, w)
+		}
+		if sec.AverageRating == nil || math.Abs(*sec.AverageRating-w.average) > 1e-9 {
+			t.Fatalf("%d. bölüm ortalaması %v, beklenen %v", i, sec.AverageRating, w.average)
+		}
+
+		var names []string
+		for _, cleaner := range sec.Cleaners {
+			names = append(names, cleaner.CleanerName)
+		}
+		if !slices.Equal(names, w.cleaners) {
+			t.Fatalf("%d. bölüm sıralaması %v, beklenen %v", i, names, w.cleaners)
+		}
+		if w.topProblem != "" && (len(sec.TopProblems) == 0 || sec.TopProblems[0].Label != w.topProblem) {
+			t.Fatalf("%d. bölüm problemleri %+v, ilk beklenen %s", i, sec.TopProblems, w.topProblem)
+		}
+	}
+}
+
+func TestRenderManagementReport(t *testing.T) {
+	s := newTestServer(t)
+	loc := setFacilityTimezone(t, "Europe/Istanbul")
+	s.insertRating(1, 3, "[1]", time.Date(2026, 3, 10, 9, 0, 0, 0, loc))
+	from, to := reportPeriodBounds("weekly", time.Date(2026, 3, 10, 0, 0, 0, 0, loc))
+
+	render := func() []byte {
+		t.Helper()
+		data, err := collectReportData("weekly", from, to)
+		if err != nil {
+			t.Fatalf("Rapor verileri toplanamadı: %v", err)
+		}
+		content, err := renderManagementReport(data)
+		if err != nil {
+			t.Fatalf("PDF oluşturulamadı: %v", err)
+		}
+		if !bytes.HasPrefix(content, []byte("%PDF-")) {
+			t.Fatalf("Çıktı PDF değil: %q", content[:min(len(content), 16)])
+		}
+		return content
+	}
+
+	single := pdfPageCount(render())
+	if single == 0 {
+		t.Fatal("PDF sayfa içermiyor")
+	}
+
+	// Her bina kendi sayfasında ayrıntılanır
+	s.moveToBuilding("Ek Bina", 5, 6)
+	s.moveToBuilding("Yeni Bina", 3, 4)
+	if multi := pdfPageCount(render()); multi != single+3 {
+		t.Fatalf("Üç binalı rapor %d sayfa, beklenen %d", multi, single+3)
+	}
+}
+
+func TestRunScheduledReports(t *testing.T) {
+	s := newTestServer(t)
+	setFacilityTimezone(t, "Europe/Istanbul")
+	dir := useReportSettings(t, []string{"weekly", "monthly"}, []string{"yonetim@example.com"})
+	sink := filepath.Join(t.TempDir(), "sink.jsonl")
+	useSinkNotifiers(t, sink)
+
+	runScheduledReports()
+	runScheduledReports() // Raporu olan dönem tekrar oluşturulmaz ve gönderilmez
+
+	var reports []ManagementReport
+	if err := s.db.Order("period ASC").Find(&reports).Error; err != nil {
+		t.Fatalf("Raporlar okunamadı: %v", err)
+	}
+	if len(reports) != 2 {
+		t.Fatalf("%d rapor oluşturuldu, beklenen 2", len(reports))
+	}
+
+	for _, report := range reports {
+		start, end := lastCompletedPeriod(report.Period, facilityNow())
+		if !report.PeriodStart.Equal(start) || !report.PeriodEnd.Equal(end) {
+			t.Fatalf("%s raporu %s - %s, beklenen %s - %s", report.Period, report.PeriodStart, report.PeriodEnd, start, end)
+		}
+		if report.FileName != managementReportFileName(report.Period, start) || report.EmailedAt == nil {
+			t.Fatalf("Beklenmeyen rapor kaydı: %+v", report)
+		}
+
+		content, err := os.ReadFile(filepath.Join(dir, report.FileName))
+		if err != nil || !bytes.HasPrefix(content, []byte("%PDF-")) || int64(len(content)) != report.Size {
+			t.Fatalf("%s dosyası geçersiz (%v)", report.FileName, err)
+		}
+	}
+
+	sent := readSinkFile(t, sink)
+	want := []string{"yonetim@example.com report.monthly", "yonetim@example.com report.weekly"}
+	if !slices.Equal(sent, want) {
+		t.Fatalf("Gönderilen e-postalar %v, beklenen %v", sent, want)
+	}
+}
+
+func TestCreateAndDownloadManagementReport(t *testing.T) {
+	s := newTestServer(t)
+	setFacilityTimezone(t, "Europe/Istanbul")
+	useReportSettings(t, nil, nil)
+
+	rec := s.request(http.MethodPost, "/api/admin/reports/management", ManagementReportRequest{Period: "weekly", PeriodStart: "2026-03-11"}, s.adminHeaders())
+	expectStatus(t, rec, http.StatusCreated)
+	var resp ManagementReportsResponse
+	decode(t, rec, &resp)
+	if resp.Report == nil || resp.Report.FileName != "haftalik-rapor-2026-03-09.pdf" {
+		t.Fatalf("Beklenmeyen rapor: %+v", resp.Report)
+	}
+
+	rec = s.request(http.MethodGet, "/api/admin/reports/management/"+fmt.Sprint(resp.Report.ID)+"/download", nil, s.adminHeaders())
+	expectStatus(t, rec, http.StatusOK)
+	if !bytes.HasPrefix(rec.Body.Bytes(), []byte("%PDF-")) {
+		t.Fatalf("İndirilen dosya PDF değil")
+	}
+
+	expectError(t, s, http.MethodPost, "/api/admin/reports/management", ManagementReportRequest{Period: "weekly", PeriodStart: "2999-01-04"}, s.adminHeaders(), http.StatusBadRequest, CodeFutureReportPeriod)
+	expectError(t, s, http.MethodGet, "/api/admin/reports/management/999/download", nil, s.adminHeaders(), http.StatusNotFound, CodeReportNotFound)
+}
+
+func TestCurrentPeriodReportIsRejected(t *testing.T) {
+	s := newTestServer(t)
+	setFacilityTimezone(t, "Europe/Istanbul")
+	useReportSettings(t, []string{"weekly", "monthly"}, nil)
+
+	// Devam eden dönemin eksik raporu kaydedilmez, dönem bitince zamanlanmış rapor oluşturulabilir
+	today := facilityNow().Format("2006-01-02")
+	for _, period := range []string{"weekly", "monthly"} {
+		expectError(t, s, http.MethodPost, "/api/admin/reports/management", ManagementReportRequest{Period: period, PeriodStart: today}, s.adminHeaders(), http.StatusBadRequest, CodeFutureReportPeriod)
+	}
+
+	var count int64
+	s.db.Model(&ManagementReport{}).Count(&count)
+	if count != 0 {
+		t.Fatalf("Devam eden dönem için %d rapor kaydedildi", count)
+	}
+}
+
+func TestManagementReportRoutesRequireAdmin(t *testing.T) {
+	s := newTestServer(t)
+	useReportSettings(t, nil, nil)
+	cleaner := s.createUser("ayse", "Ayşe", "temizlikci")
+
+	routes := []struct {
+		method, path string
+		body         interface{}
+	}{
+		{http.MethodGet, "/api/admin/reports/management", nil},
+		{http.MethodPost, "/api/admin/reports/management", ManagementReportRequest{Period: "weekly"}},
+		{http.MethodGet, "/api/admin/reports/management/1/download", nil},
+	}
+	for _, route := range routes {
+		t.Run(route.method+" "+route.path, func(t *testing.T) {
+			expectError(t, s, route.method, route.path, route.body, nil, http.StatusUnauthorized, CodeUnauthorized)
+			expectError(t, s, route.method, route.path, route.body, staffHeaders(cleaner), http.StatusForbidden, CodeForbidden)
+		})
+	}
+}
@@ -0,0 +1,388 @@
+package main
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrNotFound aranan kayıt bulunamadığında repository'lerin döndüğü hata
+var ErrNotFound = errors.New("kayıt bulunamadı")
+
+// ErrTaskActive tuvalette tamamlanmamış bir görev varken yeni görev açılmak istendiğinde dönen hata
+var ErrTaskActive = errors.New("tuvalette tamamlanmamış bir görev var")
+
+// activeTaskStatuses tuvalet için henüz tamamlanmamış görev durumları
+var activeTaskStatuses = []string{"assigned", "in_progress"}
+
+// UserRepository kullanıcı kayıtlarına erişim
+type UserRepository interface {
+	List() ([]User, error)
+	FindByID(id uint) (User, error)
+	FindActiveByID(id uint) (User, error)
+	FindActiveByUsername(username string) (User, error)
+	// UsernameTaken kullanıcı adının excludeID dışındaki bir kullanıcıda olup olmadığını döner
+	UsernameTaken(username string, excludeID uint) (bool, error)
+	FindActiveCleaner(id uint) (User, error)
+	// LeastBusyCleaner devam eden görevi en az olan aktif temizlikçiyi döner
+	LeastBusyCleaner() (User, error)
+	Create(user *User) error
+	Save(user *User) error
+	// Delete kullanıcıyı bildirim tercihleri ve push abonelikleriyle birlikte siler
+	Delete(user User) error
+}
+
+// ToiletRepository tuvalet kayıtlarına erişim
+type ToiletRepository interface {
+	// ListActive aktif tuvaletleri ID sırasıyla döner
+	ListActive() ([]Toilet, error)
+	FindByID(id int) (Toilet, error)
+	FindActiveByID(id int) (Toilet, error)
+	Save(toilet *Toilet) error
+}
+
+// RatingRepository puanlama kayıtlarına ve tuvalet başına puanlama özetlerine erişim
+type RatingRepository interface {
+	List() ([]Rating, error)
+	FindByID(id uint) (Rating, error)
+	FindByIDs(ids []uint) ([]Rating, error)
+	ListByToilet(toiletID int) ([]Rating, error)
+	// PageByToilet tuvaletin puanlamalarını en yeniden eskiye sayfalar ve toplam sayıyı döner
+	PageByToilet(toiletID, limit, offset int) ([]Rating, int64, error)
+	Summaries(toiletIDs []int) ([]ToiletRatingSummary, error)
+	// Create puanlamayı kaydeder ve tuvaletin özetini aynı işlemde günceller
+	Create(rating *Rating) error
+}
+
+// TaskFilter temizlik görevi listeleme filtresi, sıfır değerler filtrelenmez
+type TaskFilter struct {
+	Status   string
+	ToiletID int
+}
+
+// TaskRepository temizlik görevlerine erişim.
+// Yazma işlemleri ilgili webhook olayını da kuyruğa ekler.
+type TaskRepository interface {
+	List(filter TaskFilter) ([]CleaningTask, error)
+	FindByID(id uint) (CleaningTask, error)
+	// FindActiveByToilet tuvaletin tamamlanmamış görevini döner, yoksa ErrNotFound
+	FindActiveByToilet(toiletID int) (CleaningTask, error)
+	// ListActiveByToilets tuvaletlerin tamamlanmamış görevlerini ID sırasıyla döner
+	ListActiveByToilets(toiletIDs []int) ([]CleaningTask, error)
+	// Create tuvalette tamamlanmamış görev yoksa görevi kaydeder, varsa ErrTaskActive döner;
+	// kontrol ve kayıt aynı işlemde yapıldığından eşzamanlı istekler iki aktif görev açamaz
+	Create(task *CleaningTask) error
+	Begin(task *CleaningTask) error
+	// Complete görevi tamamlar, temizlik sonrası puanlamayı kaydeder ve özeti aynı işlemde günceller
+	Complete(task *CleaningTask, rating *Rating) error
+}
+
+// StatsRepository admin paneli özetleri için kullanıcı, tuvalet, puanlama ve görevler üzerinde toplu sayımlar
+type StatsRepository interface {
+	System() (*SystemStats, error)
+	// Cleaners temizlikçi istatistiklerini filtre kapsamında hesaplar; boş filtre tüm zamanları kapsar
+	Cleaners(filter analyticsFilter) ([]CleanerStats, error)
+}
+
+// Repositories routes.go'daki handler'ların kullandığı repository'ler; bu handler'lar DB'ye doğrudan erişmez.
+// Kapsam kullanıcı, tuvalet, puanlama ve görev verisidir. Ayrı dosyalardaki analiz, ısı haritası,
+// performans, dışa aktarma, yönetim raporu, webhook, push ve bildirim handler'ları henüz global DB'yi
+// kullanır; bunların repository'lere taşınması ayrı bir iş olarak bekliyor.
+type Repositories struct {
+	Users   UserRepository
+	Toilets ToiletRepository
+	Ratings RatingRepository
+	Tasks   TaskRepository
+	Stats   StatsRepository
+}
+
+// NewGormRepositories veritabanı üzerinde çalışan repository'leri oluşturur
+func NewGormRepositories(db *gorm.DB) Repositories {
+	return Repositories{
+		Users:   &gormUserRepository{db: db},
+		Toilets: &gormToiletRepository{db: db},
+		Ratings: &gormRatingRepository{db: db},
+		Tasks:   &gormTaskRepository{db: db},
+		Stats:   &gormStatsRepository{db: db},
+	}
+}
+
+// notFound GORM'un kayıt bulunamadı hatasını ErrNotFound'a çevirir
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+type gormUserRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormUserRepository) List() ([]User, error) {
+	var users []User
+	err := r.db.Find(&users).Error
+	return users, err
+}
+
+func (r *gormUserRepository) FindByID(id uint) (User, error) {
+	var user User
+	err := r.db.First(&user, id).Error
+	return user, notFound(err)
+}
+
+func (r *gormUserRepository) FindActiveByID(id uint) (User, error) {
+	var user User
+	err := r.db.Where("id = ? AND is_active = ?", id, true).First(&user).Error
+	return user, notFound(err)
+}
+
+func (r *gormUserRepository) FindActiveByUsername(username string) (User, error) {
+	var user User
+	err := r.db.Where("username = ? AND is_active = ?", username, true).First(&user).Error
+	return user, notFound(err)
+}
+
+func (r *gormUserRepository) UsernameTaken(username string, excludeID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&User{}).Where("username = ? AND id != ?", username, excludeID).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *gormUserRepository) FindActiveCleaner(id uint) (User, error) {
+	var user User
+	err := r.db.Where("id = ? AND role = ? AND is_active = ?", id, "temizlikci", true).First(&user).Error
+	return user, notFound(err)
+}
+
+func (r *gormUserRepository) LeastBusyCleaner() (User, error) {
+	var user User
+	err := r.db.Model(&User{}).
+		Select("users.*").
+		Joins("LEFT JOIN cleaning_tasks ON cleaning_tasks.cleaner_id = users.id AND cleaning_tasks.status IN ?", activeTaskStatuses).
+		Where("users.role = ? AND users.is_active = ?", "temizlikci", true).
+		Group("users.id").
+		Order("COUNT(cleaning_tasks.id) ASC, users.id ASC").
+		Limit(1).
+		Find(&user).Error
+	if err == nil && user.ID == 0 {
+		err = ErrNotFound
+	}
+	return user, err
+}
+
+func (r *gormUserRepository) Create(user *User) error {
+	return r.db.Create(user).Error
+}
+
+func (r *gormUserRepository) Save(user *User) error {
+	return r.db.Save(user).Error
+}
+
+func (r *gormUserRepository) Delete(user User) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", user.ID).Delete(&NotificationPreference{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&PushSubscription{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&user).Error
+	})
+}
+
+type gormToiletRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormToiletRepository) ListActive() ([]Toilet, error) {
+	var toilets []Toilet
+	err := r.db.Where("is_active = ?", true).Order("id ASC").Find(&toilets).Error
+	return toilets, err
+}
+
+func (r *gormToiletRepository) FindByID(id int) (Toilet, error) {
+	var toilet Toilet
+	err := r.db.First(&toilet, id).Error
+	return toilet, notFound(err)
+}
+
+func (r *gormToiletRepository) FindActiveByID(id int) (Toilet, error) {
+	var toilet Toilet
+	err := r.db.Where("id = ? AND is_active = ?", id, true).First(&toilet).Error
+	return toilet, notFound(err)
+}
+
+func (r *gormToiletRepository) Save(toilet *Toilet) error {
+	return r.db.Save(toilet).Error
+}
+
+type gormRatingRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormRatingRepository) List() ([]Rating, error) {
+	var ratings []Rating
+	err := r.db.Find(&ratings).Error
+	return ratings, err
+}
+
+func (r *gormRatingRepository) FindByID(id uint) (Rating, error) {
+	var rating Rating
+	err := r.db.First(&rating, id).Error
+	return rating, notFound(err)
+}
+
+func (r *gormRatingRepository) FindByIDs(ids []uint) ([]Rating, error) {
+	var ratings []Rating
+	if len(ids) == 0 {
+		return ratings, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&ratings).Error
+	return ratings, err
+}
+
+func (r *gormRatingRepository) ListByToilet(toiletID int) ([]Rating, error) {
+	var ratings []Rating
+	err := r.db.Where("toilet_id = ?", toiletID).Find(&ratings).Error
+	return ratings, err
+}
+
+func (r *gormRatingRepository) PageByToilet(toiletID, limit, offset int) ([]Rating, int64, error) {
+	var ratings []Rating
+	var totalCount int64
+
+	if err := r.db.Model(&Rating{}).Where("toilet_id = ?", toiletID).Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := r.db.Where("toilet_id = ?", toiletID).
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&ratings).Error
+	return ratings, totalCount, err
+}
+
+func (r *gormRatingRepository) Summaries(toiletIDs []int) ([]ToiletRatingSummary, error) {
+	var summaries []ToiletRatingSummary
+	err := r.db.Where("toilet_id IN ?", toiletIDs).Find(&summaries).Error
+	return summaries, err
+}
+
+func (r *gormRatingRepository) Create(rating *Rating) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(rating).Error; err != nil {
+			return err
+		}
+		if err := applyRatingToSummary(tx, *rating); err != nil {
+			return err
+		}
+		return enqueueWebhookEvent(tx, EventRatingCreated, ratingEventData(*rating))
+	})
+}
+
+type gormTaskRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormTaskRepository) List(filter TaskFilter) ([]CleaningTask, error) {
+	query := r.db.Model(&CleaningTask{})
+
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	if filter.ToiletID != 0 {
+		query = query.Where("toilet_id = ?", filter.ToiletID)
+	}
+
+	var tasks []CleaningTask
+	err := query.Order("created_at DESC").Find(&tasks).Error
+	return tasks, err
+}
+
+func (r *gormTaskRepository) FindByID(id uint) (CleaningTask, error) {
+	var task CleaningTask
+	err := r.db.First(&task, id).Error
+	return task, notFound(err)
+}
+
+func (r *gormTaskRepository) FindActiveByToilet(toiletID int) (CleaningTask, error) {
+	var task CleaningTask
+	err := r.db.Where("toilet_id = ? AND status IN ?", toiletID, activeTaskStatuses).First(&task).Error
+	return task, notFound(err)
+}
+
+func (r *gormTaskRepository) ListActiveByToilets(toiletIDs []int) ([]CleaningTask, error) {
+	var tasks []CleaningTask
+	err := r.db.Where("toilet_id IN ? AND status IN ?", toiletIDs, activeTaskStatuses).
+		Order("id ASC").
+		Find(&tasks).Error
+	return tasks, err
+}
+
+func (r *gormTaskRepository) Create(task *CleaningTask) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Tuvalet satırı kilitlenir; aynı tuvalet için açılan diğer görevler kontrolü bu işlem bitince yapar
+		var toilet Toilet
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&toilet, task.ToiletID).Error; err != nil {
+			return notFound(err)
+		}
+
+		var active int64
+		if err := tx.Model(&CleaningTask{}).Where("toilet_id = ? AND status IN ?", task.ToiletID, activeTaskStatuses).Count(&active).Error; err != nil {
+			return err
+		}
+		if active > 0 {
+			return ErrTaskActive
+		}
+
+		if err := tx.Create(task).Error; err != nil {
+			return err
+		}
+		return enqueueWebhookEvent(tx, EventTaskAssigned, *task)
+	})
+}
+
+func (r *gormTaskRepository) Begin(task *CleaningTask) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(task).Error; err != nil {
+			return err
+		}
+		return enqueueWebhookEvent(tx, EventTaskStarted, *task)
+	})
+}
+
+func (r *gormTaskRepository) Complete(task *CleaningTask, rating *Rating) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(task).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(rating).Error; err != nil {
+			return err
+		}
+		if err := applyRatingToSummary(tx, *rating); err != nil {
+			return err
+		}
+
+		// Webhook gönderimlerini aynı transaction içinde kuyruğa ekle
+		return enqueueWebhookEvent(tx, EventTaskCompleted, map[string]interface{}{
+			"task":   *task,
+			"rating": *rating,
+		})
+	})
+}
+
+type gormStatsRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormStatsRepository) System() (*SystemStats, error) {
+	return computeSystemStats(r.db)
+}
+
+func (r *gormStatsRepository) Cleaners(filter analyticsFilter) ([]CleanerStats, error) {
+	return computeCleanerStats(r.db, filter)
+}
@@ -0,0 +1,567 @@
+package main
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// memoryStore bellek içi repository'lerin ortak verisi; testlerde veritabanı olmadan handler çalıştırmak için.
+// Webhook olayları kuyruğa eklenmez.
+type memoryStore struct {
+	mu      sync.Mutex
+	users   map[uint]User
+	toilets map[int]Toilet
+	ratings map[uint]Rating
+	tasks   map[uint]CleaningTask
+	nextID  uint
+}
+
+// NewMemoryRepositories boş bir bellek içi veri deposu üzerinde repository'leri oluşturur
+func NewMemoryRepositories() Repositories {
+	store := &memoryStore{
+		users:   map[uint]User{},
+		toilets: map[int]Toilet{},
+		ratings: map[uint]Rating{},
+		tasks:   map[uint]CleaningTask{},
+	}
+	return Repositories{
+		Users:   &memoryUserRepository{store},
+		Toilets: &memoryToiletRepository{store},
+		Ratings: &memoryRatingRepository{store},
+		Tasks:   &memoryTaskRepository{store},
+		Stats:   &memoryStatsRepository{store},
+	}
+}
+
+// id yeni kayıt için artan bir ID üretir
+func (s *memoryStore) id() uint {
+	s.nextID++
+	return s.nextID
+}
+
+// touch kaydın zaman damgalarını veritabanındaki gibi doldurur
+func touch(createdAt, updatedAt *time.Time) {
+	now := time.Now()
+	if createdAt.IsZero() {
+		*createdAt = now
+	}
+	*updatedAt = now
+}
+
+type memoryUserRepository struct {
+	s *memoryStore
+}
+
+func (r *memoryUserRepository) List() ([]User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	users := make([]User, 0, len(r.s.users))
+	for _, user := range r.s.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+func (r *memoryUserRepository) FindByID(id uint) (User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	user, ok := r.s.users[id]
+	if !ok {
+		return User{}, ErrNotFound
+	}
+	return user, nil
+}
+
+func (r *memoryUserRepository) FindActiveByID(id uint) (User, error) {
+	user, err := r.FindByID(id)
+	if err == nil && !user.IsActive {
+		return User{}, ErrNotFound
+	}
+	return user, err
+}
+
+func (r *memoryUserRepository) FindActiveByUsername(username string) (User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, user := range r.s.users {
+		if user.Username == username && user.IsActive {
+			return user, nil
+		}
+	}
+	return User{}, ErrNotFound
+}
+
+func (r *memoryUserRepository) UsernameTaken(username string, excludeID uint) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, user := range r.s.users {
+		if user.Username == username && user.ID != excludeID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memoryUserRepository) FindActiveCleaner(id uint) (User, error) {
+	user, err := r.FindActiveByID(id)
+	if err == nil && user.Role != "temizlikci" {
+		return User{}, ErrNotFound
+	}
+	return user, err
+}
+
+func (r *memoryUserRepository) LeastBusyCleaner() (User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	activeTasks := map[uint]int{}
+	for _, task := range r.s.tasks {
+		if task.Status == "assigned" || task.Status == "in_progress" {
+			activeTasks[task.CleanerID]++
+		}
+	}
+
+	var best User
+	for _, user := range r.s.users {
+		if user.Role != "temizlikci" || !user.IsActive {
+			continue
+		}
+		if best.ID == 0 || activeTasks[user.ID] < activeTasks[best.ID] ||
+			(activeTasks[user.ID] == activeTasks[best.ID] && user.ID < best.ID) {
+			best = user
+		}
+	}
+	if best.ID == 0 {
+		return User{}, ErrNotFound
+	}
+	return best, nil
+}
+
+func (r *memoryUserRepository) Create(user *User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if user.ID == 0 {
+		user.ID = r.s.id()
+	}
+	touch(&user.CreatedAt, &user.UpdatedAt)
+	r.s.users[user.ID] = *user
+	return nil
+}
+
+func (r *memoryUserRepository) Save(user *User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	touch(&user.CreatedAt, &user.UpdatedAt)
+	r.s.users[user.ID] = *user
+	return nil
+}
+
+func (r *memoryUserRepository) Delete(user User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	delete(r.s.users, user.ID)
+	return nil
+}
+
+type memoryToiletRepository struct {
+	s *memoryStore
+}
+
+// AddToilet testlerde başlangıç tuvaletlerini eklemek için kullanılır
+func (r *memoryToiletRepository) AddToilet(toilet Toilet) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	touch(&toilet.CreatedAt, &toilet.UpdatedAt)
+	r.s.toilets[toilet.ID] = toilet
+}
+
+func (r *memoryToiletRepository) ListActive() ([]Toilet, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	toilets := []Toilet{}
+	for _, toilet := range r.s.toilets {
+		if toilet.IsActive {
+			toilets = append(toilets, toilet)
+		}
+	}
+	sort.Slice(toilets, func(i, j int) bool { return toilets[i].ID < toilets[j].ID })
+	return toilets, nil
+}
+
+func (r *memoryToiletRepository) FindByID(id int) (Toilet, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	toilet, ok := r.s.toilets[id]
+	if !ok {
+		return Toilet{}, ErrNotFound
+	}
+	return toilet, nil
+}
+
+func (r *memoryToiletRepository) FindActiveByID(id int) (Toilet, error) {
+	toilet, err := r.FindByID(id)
+	if err == nil && !toilet.IsActive {
+		return Toilet{}, ErrNotFound
+	}
+	return toilet, err
+}
+
+func (r *memoryToiletRepository) Save(toilet *Toilet) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.toilets[toilet.ID]; !ok {
+		return ErrNotFound
+	}
+	touch(&toilet.CreatedAt, &toilet.UpdatedAt)
+	r.s.toilets[toilet.ID] = *toilet
+	return nil
+}
+
+type memoryRatingRepository struct {
+	s *memoryStore
+}
+
+// sortedRatings koşula uyan puanlamaları ID sırasıyla döner, kilit çağıran tarafından tutulmalı
+func (s *memoryStore) sortedRatings(match func(Rating) bool) []Rating {
+	ratings := []Rating{}
+	for _, rating := range s.ratings {
+		if match(rating) {
+			ratings = append(ratings, rating)
+		}
+	}
+	sort.Slice(ratings, func(i, j int) bool { return ratings[i].ID < ratings[j].ID })
+	return ratings
+}
+
+func (r *memoryRatingRepository) List() ([]Rating, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return r.s.sortedRatings(func(Rating) bool { return true }), nil
+}
+
+func (r *memoryRatingRepository) FindByID(id uint) (Rating, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	rating, ok := r.s.ratings[id]
+	if !ok {
+		return Rating{}, ErrNotFound
+	}
+	return rating, nil
+}
+
+func (r *memoryRatingRepository) FindByIDs(ids []uint) ([]Rating, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	wanted := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	return r.s.sortedRatings(func(rating Rating) bool { return wanted[rating.ID] }), nil
+}
+
+func (r *memoryRatingRepository) ListByToilet(toiletID int) ([]Rating, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return r.s.sortedRatings(func(rating Rating) bool { return rating.ToiletID == toiletID }), nil
+}
+
+func (r *memoryRatingRepository) PageByToilet(toiletID, limit, offset int) ([]Rating, int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	ratings := r.s.sortedRatings(func(rating Rating) bool { return rating.ToiletID == toiletID })
+	sort.SliceStable(ratings, func(i, j int) bool { return ratings[i].CreatedAt.After(ratings[j].CreatedAt) })
+
+	total := int64(len(ratings))
+	if offset >= len(ratings) {
+		return []Rating{}, total, nil
+	}
+	end := offset + limit
+	if end > len(ratings) {
+		end = len(ratings)
+	}
+	return ratings[offset:end], total, nil
+}
+
+func (r *memoryRatingRepository) Summaries(toiletIDs []int) ([]ToiletRatingSummary, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	wanted := make(map[int]bool, len(toiletIDs))
+	for _, id := range toiletIDs {
+		wanted[id] = true
+	}
+
+	// Özetler saklanmaz, her seferinde puanlamalardan hesaplanır
+	byToilet := map[int]*ToiletRatingSummary{}
+	for _, rating := range r.s.sortedRatings(func(rating Rating) bool { return wanted[rating.ToiletID] }) {
+		summary, ok := byToilet[rating.ToiletID]
+		if !ok {
+			summary = &ToiletRatingSummary{ToiletID: rating.ToiletID}
+			byToilet[rating.ToiletID] = summary
+		}
+		summary.addRating(rating)
+	}
+
+	summaries := make([]ToiletRatingSummary, 0, len(byToilet))
+	for _, summary := range byToilet {
+		summaries = append(summaries, *summary)
+	}
+	return summaries, nil
+}
+
+func (r *memoryRatingRepository) Create(rating *Rating) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.createRating(rating)
+	return nil
+}
+
+// createRating puanlamayı ID vererek ekler, kilit çağıran tarafından tutulmalı
+func (s *memoryStore) createRating(rating *Rating) {
+	rating.ID = s.id()
+	touch(&rating.CreatedAt, &rating.UpdatedAt)
+	s.ratings[rating.ID] = *rating
+}
+
+type memoryTaskRepository struct {
+	s *memoryStore
+}
+
+func (r *memoryTaskRepository) List(filter TaskFilter) ([]CleaningTask, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	tasks := []CleaningTask{}
+	for _, task := range r.s.tasks {
+		if filter.Status != "" && task.Status != filter.Status {
+			continue
+		}
+		if filter.ToiletID != 0 && task.ToiletID != filter.ToiletID {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID > tasks[j].ID })
+	return tasks, nil
+}
+
+func (r *memoryTaskRepository) FindByID(id uint) (CleaningTask, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	task, ok := r.s.tasks[id]
+	if !ok {
+		return CleaningTask{}, ErrNotFound
+	}
+	return task, nil
+}
+
+func (r *memoryTaskRepository) FindActiveByToilet(toiletID int) (CleaningTask, error) {
+	tasks, err := r.ListActiveByToilets([]int{toiletID})
+	if err != nil || len(tasks) == 0 {
+		return CleaningTask{}, ErrNotFound
+	}
+	return tasks[0], nil
+}
+
+func (r *memoryTaskRepository) ListActiveByToilets(toiletIDs []int) ([]CleaningTask, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	wanted := make(map[int]bool, len(toiletIDs))
+	for _, id := range toiletIDs {
+		wanted[id] = true
+	}
+
+	tasks := []CleaningTask{}
+	for _, task := range r.s.tasks {
+		if wanted[task.ToiletID] && (task.Status == "assigned" || task.Status == "in_progress") {
+			tasks = append(tasks, task)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+	return tasks, nil
+}
+
+func (r *memoryTaskRepository) Create(task *CleaningTask) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.toilets[task.ToiletID]; !ok {
+		return ErrNotFound
+	}
+	for _, existing := range r.s.tasks {
+		if existing.ToiletID == task.ToiletID && (existing.Status == "assigned" || existing.Status == "in_progress") {
+			return ErrTaskActive
+		}
+	}
+
+	task.ID = r.s.id()
+	touch(&task.CreatedAt, &task.UpdatedAt)
+	r.s.tasks[task.ID] = *task
+	return nil
+}
+
+func (r *memoryTaskRepository) Begin(task *CleaningTask) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	touch(&task.CreatedAt, &task.UpdatedAt)
+	r.s.tasks[task.ID] = *task
+	return nil
+}
+
+func (r *memoryTaskRepository) Complete(task *CleaningTask, rating *Rating) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	touch(&task.CreatedAt, &task.UpdatedAt)
+	r.s.tasks[task.ID] = *task
+	r.s.createRating(rating)
+	return nil
+}
+
+type memoryStatsRepository struct {
+	s *memoryStore
+}
+
+func (r *memoryStatsRepository) System() (*SystemStats, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stats := &SystemStats{}
+	for _, toilet := range r.s.toilets {
+		stats.TotalToilets++
+		if toilet.IsActive {
+			stats.ActiveToilets++
+		}
+	}
+	for _, user := range r.s.users {
+		if user.Role == "temizlikci" {
+			stats.TotalCleaners++
+			if user.IsActive {
+				stats.ActiveCleaners++
+			}
+		}
+	}
+
+	problemsSince := time.Now().Add(-24 * time.Hour)
+	withProblems := map[int]bool{}
+	sum := 0
+	for _, rating := range r.s.ratings {
+		stats.TotalRatings++
+		sum += rating.Rating
+		if rating.CreatedAt.After(problemsSince) && len(ratingProblemIDs(rating)) > 0 {
+			withProblems[rating.ToiletID] = true
+		}
+	}
+	stats.ToiletsWithProblems = len(withProblems)
+	if stats.TotalRatings > 0 {
+		stats.AverageRating = float64(sum) / float64(stats.TotalRatings)
+	}
+
+	today := startOfDay(time.Now())
+	for _, task := range r.s.tasks {
+		switch {
+		case task.Status == "completed" && task.CompletedAt != nil && !task.CompletedAt.Before(today):
+			stats.CompletedTasksToday++
+		case task.Status == "assigned" || task.Status == "in_progress":
+			stats.OngoingTasks++
+		}
+	}
+	return stats, nil
+}
+
+func (r *memoryStatsRepository) Cleaners(filter analyticsFilter) ([]CleanerStats, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	cleanerStats := []CleanerStats{}
+	index := map[uint]int{}
+	for _, user := range r.s.users {
+		if user.Role == "temizlikci" && (filter.CleanerID == 0 || user.ID == filter.CleanerID) {
+			cleanerStats = append(cleanerStats, CleanerStats{CleanerID: user.ID, CleanerName: user.Name, IsActive: user.IsActive})
+		}
+	}
+	sort.Slice(cleanerStats, func(i, j int) bool { return cleanerStats[i].CleanerID < cleanerStats[j].CleanerID })
+	for i, stats := range cleanerStats {
+		index[stats.CleanerID] = i
+	}
+
+	scoped := func(toiletID int) bool {
+		if filter.ToiletIDs == nil {
+			return true
+		}
+		for _, id := range filter.ToiletIDs {
+			if id == toiletID {
+				return true
+			}
+		}
+		return false
+	}
+	inRange := func(t *time.Time) bool {
+		if t == nil {
+			return filter.From.IsZero() && filter.To.IsZero()
+		}
+		return (filter.From.IsZero() || !t.Before(filter.From)) && (filter.To.IsZero() || t.Before(filter.To))
+	}
+
+	now := time.Now()
+	lastWeek := now.Add(-7 * 24 * time.Hour)
+	lastMonth := now.Add(-30 * 24 * time.Hour)
+	durations := map[uint][]float64{}
+	for _, task := range r.s.tasks {
+		i, ok := index[task.CleanerID]
+		if !ok || !scoped(task.ToiletID) {
+			continue
+		}
+		stats := &cleanerStats[i]
+
+		if task.Status == "assigned" || task.Status == "in_progress" {
+			stats.OngoingTasks++
+		}
+		if task.Status != "completed" || !inRange(task.CompletedAt) {
+			continue
+		}
+
+		stats.TotalCompletedTasks++
+		if task.CompletedAt != nil && !task.CompletedAt.Before(lastWeek) {
+			stats.LastWeekTasks++
+		}
+		if task.CompletedAt != nil && !task.CompletedAt.Before(lastMonth) {
+			stats.LastMonthTasks++
+		}
+
+		if minutes, ok := cleaningMinutes(task); ok {
+			if isCleaningOutlier(minutes) {
+				stats.ExcludedOutliers++
+			} else {
+				durations[task.CleanerID] = append(durations[task.CleanerID], minutes)
+			}
+		}
+	}
+
+	for i := range cleanerStats {
+		cleanerStats[i].applyCleaningDurations(durations[cleanerStats[i].CleanerID])
+	}
+	return cleanerStats, nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newMemoryRouter veritabanı olmadan, bellek içi repository'lerle router kurar
+func newMemoryRouter(t *testing.T) (*gin.Engine, Repositories) {
+	t.Helper()
+
+	repos := NewMemoryRepositories()
+	for id := 1; id <= 3; id++ {
+		repos.Toilets.(*memoryToiletRepository).AddToilet(Toilet{ID: id, Name: fmt.Sprintf("Tuvalet %d", id), Location: "1. Kat", IsActive: true, TokenVersion: 1})
+	}
+
+	router := gin.New()
+	SetupRoutes(router, NewHandlers(repos, nopEventNotifier{}))
+	return router, repos
+}
+
+func TestHandlersWithMemoryRepositories(t *testing.T) {
+	router, repos := newMemoryRouter(t)
+
+	admin, err := createAdmin(repos.Users, "yonetici", "parola", "Yönetici")
+	if err != nil {
+		t.Fatalf("Admin oluşturulamadı: %v", err)
+	}
+
+	rec := serve(t, router, http.MethodPost, "/api/admin/users", CreateUserRequest{Username: "bellek", Password: "parola", Name: "Bellek"}, staffHeaders(admin))
+	expectStatus(t, rec, http.StatusCreated)
+	var created UserResponse
+	decode(t, rec, &created)
+	cleaner, _ := repos.Users.FindActiveByID(created.User.ID)
+
+	rec = serve(t, router, http.MethodPost, "/api/login", LoginRequest{Username: "bellek", Password: "parola"}, nil)
+	expectStatus(t, rec, http.StatusOK)
+
+	toilet, _ := repos.Toilets.FindActiveByID(2)
+	rec = serve(t, router, http.MethodPost, "/api/rating", RatingRequest{Token: toiletRatingToken(toilet), Rating: 2, Problems: []int{4}}, nil)
+	expectStatus(t, rec, http.StatusCreated)
+
+	rec = serve(t, router, http.MethodPost, "/api/cleaning/start", CleaningTaskRequest{ToiletID: 2}, staffHeaders(cleaner))
+	expectStatus(t, rec, http.StatusCreated)
+	var task CleaningTaskResponse
+	decode(t, rec, &task)
+
+	rec = serve(t, router, http.MethodPost, "/api/cleaning/start", CleaningTaskRequest{ToiletID: 2}, staffHeaders(cleaner))
+	expectStatus(t, rec, http.StatusConflict)
+
+	rec = serve(t, router, http.MethodGet, "/api/toilets/status", nil, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var statuses struct {
+		Data []ToiletStatus `json:"data"`
+	}
+	decode(t, rec, &statuses)
+	if len(statuses.Data) != 3 {
+		t.Fatalf("Tuvalet sayısı %d, beklenen 3", len(statuses.Data))
+	}
+	status := statuses.Data[1]
+	if !status.HasProblems || status.TotalRatings != 1 || status.CleaningTask == nil || status.CleaningTask.ID != task.Task.ID {
+		t.Fatalf("Beklenmeyen tuvalet durumu: %+v", status)
+	}
+
+	rec = serve(t, router, http.MethodPut, fmt.Sprintf("/api/cleaning/complete/%d", task.Task.ID), nil, staffHeaders(cleaner))
+	expectStatus(t, rec, http.StatusOK)
+
+	ratings, _ := repos.Ratings.ListByToilet(2)
+	if len(ratings) != 2 || ratings[1].Rating != 5 {
+		t.Fatalf("Temizlik sonrası puanlama oluşmadı: %+v", ratings)
+	}
+	if _, err := repos.Tasks.FindActiveByToilet(2); err != ErrNotFound {
+		t.Fatalf("Tamamlanan görev hâlâ aktif: %v", err)
+	}
+}
+
+func TestAdminHandlersWithMemoryRepositories(t *testing.T) {
+	router, repos := newMemoryRouter(t)
+
+	admin := User{Username: "yonetici", Password: hashPassword("parola"), Name: "Yönetici", Role: "admin", IsActive: true}
+	cleaner := User{Username: "bellek", Password: hashPassword("parola"), Name: "Bellek", Role: "temizlikci", IsActive: true}
+	for _, user := range []*User{&admin, &cleaner} {
+		if err := repos.Users.Create(user); err != nil {
+			t.Fatalf("Kullanıcı eklenemedi: %v", err)
+		}
+	}
+
+	now := time.Now()
+	for _, minutes := range []int{10, 20, 30} {
+		started := now.Add(-time.Duration(minutes+60) * time.Minute)
+		task := CleaningTask{ToiletID: 1, CleanerID: cleaner.ID, CleanerName: cleaner.Name, Status: "completed",
+			StartedAt: timePtr(started), CompletedAt: timePtr(started.Add(time.Duration(minutes) * time.Minute))}
+		if err := repos.Tasks.Create(&task); err != nil {
+			t.Fatalf("Görev eklenemedi: %v", err)
+		}
+	}
+	if err := repos.Tasks.Create(&CleaningTask{ToiletID: 2, CleanerID: cleaner.ID, CleanerName: cleaner.Name, Status: "assigned"}); err != nil {
+		t.Fatalf("Görev eklenemedi: %v", err)
+	}
+
+	rec := serve(t, router, http.MethodGet, "/api/admin/stats", nil, staffHeaders(admin))
+	expectStatus(t, rec, http.StatusOK)
+	var stats StatsResponse
+	decode(t, rec, &stats)
+	system := stats.SystemStats
+	if system == nil || system.TotalToilets != 3 || system.TotalCleaners != 1 || system.OngoingTasks != 1 {
+		t.Fatalf("Beklenmeyen sistem istatistikleri: %+v", system)
+	}
+	if len(stats.CleanerStats) != 1 {
+		t.Fatalf("Temizlikçi istatistikleri %+v", stats.CleanerStats)
+	}
+	got := stats.CleanerStats[0]
+	if got.TotalCompletedTasks != 3 || got.LastWeekTasks != 3 || got.OngoingTasks != 1 || got.MedianCleaningTime == nil || *got.MedianCleaningTime != 20 {
+		t.Fatalf("Beklenmeyen temizlikçi istatistikleri: %+v", got)
+	}
+
+	// Token yenilenince eski QR kod reddedilir
+	toilet, _ := repos.Toilets.FindByID(2)
+	oldToken := toiletRatingToken(toilet)
+	rec = serve(t, router, http.MethodPost, "/api/admin/toilets/2/token/rotate", nil, staffHeaders(admin))
+	expectStatus(t, rec, http.StatusOK)
+	var token RatingTokenResponse
+	decode(t, rec, &token)
+	if token.Version != 2 {
+		t.Fatalf("Token sürümü %d, beklenen 2", token.Version)
+	}
+	rec = serve(t, router, http.MethodGet, "/api/rating-token/"+oldToken, nil, nil)
+	expectStatus(t, rec, http.StatusGone)
+
+	rec = serve(t, router, http.MethodGet, "/api/toilet/2/qr?format=svg", nil, staffHeaders(admin))
+	expectStatus(t, rec, http.StatusOK)
+	rec = serve(t, router, http.MethodGet, "/api/admin/qr/sheet?location=1.%20Kat", nil, staffHeaders(admin))
+	expectStatus(t, rec, http.StatusOK)
+}
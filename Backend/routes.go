@@ -1,64 +1,122 @@
 package main
 
 import (
+	"crypto/hmac"
 	"crypto/sha256"
+	"crypto/subtle"
 	"database/sql"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
+// Handlers kullanıcı, tuvalet, puanlama ve görev endpoint'lerini repository'ler üzerinden çalıştırır;
+// testlerde bellek içi repository'lerle veritabanı olmadan kurulabilir
+type Handlers struct {
+	Repositories
+	notifier EventNotifier
+}
+
+// NewHandlers repository'ler ve bildirim gönderici ile handler'ları oluşturur
+func NewHandlers(repos Repositories, notifier EventNotifier) *Handlers {
+	return &Handlers{Repositories: repos, notifier: notifier}
+}
+
 // SetupRoutes API route'larını ayarlar
-func SetupRoutes(router *gin.Engine) {
-	// CORS middleware ekle
-	router.Use(func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
-		c.Header("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-User-ID, X-User-Name")
-
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
+func SetupRoutes(router *gin.Engine, h *Handlers) {
+	// Orkestrasyon kontrolleri (liveness/readiness)
+	router.GET("/healthz", healthz)
+	router.GET("/readyz", readyz)
 
-		c.Next()
-	})
+	// API routes; yanıt mesajları kullanıcının veya tarayıcının dilinde döner
+	api := router.Group("/api", h.resolveLanguage)
+
+	// Herkese açık puanlama sayfasının kullandığı route'lar - CORS_PUBLIC_ORIGINS
+	public := newCORSGroup(api, publicCORSPolicy())
+	{
+		public.POST("/rating", h.createRating)
+		public.GET("/rating-token/:token", h.resolveRatingToken)
+		public.GET("/toilets", h.getToilets)
+		public.GET("/problem-types", getProblemTypes)
+	}
 
-	// API routes
-	api := router.Group("/api")
+	// Personel ve yönetim route'ları - sadece CORS_ALLOWED_ORIGINS
+	staff := newCORSGroup(api, staffCORSPolicy())
 	{
 		// Auth routes
-		api.POST("/login", login)
+		staff.POST("/login", h.login)
 
 		// Rating routes
-		api.POST("/rating", createRating)
-		api.GET("/ratings", getRatings)
-		api.GET("/rating/:id", getRating)
-		api.GET("/toilet/:toiletId/ratings", getToiletRatings)
+		staff.GET("/ratings", h.getRatings)
+		staff.GET("/rating/:id", h.getRating)
+		staff.GET("/toilet/:toiletId/ratings", h.getToiletRatings)
 
 		// Toilet routes
-		api.GET("/toilets", getToilets)
-		api.GET("/toilets/status", getToiletsStatus)
-		api.GET("/toilet/:toiletId/ratings/paginated", getToiletRatingsPaginated)
+		staff.GET("/toilets/status", h.getToiletsStatus)
+		staff.GET("/toilet/:toiletId/ratings/paginated", h.getToiletRatingsPaginated)
+		staff.GET("/toilet/:toiletId/qr", h.requireAdmin, h.getToiletQRCode)
 
 		// Cleaning task routes
-		api.POST("/cleaning/start", startCleaningTask)
-		api.PUT("/cleaning/begin/:id", beginCleaningTask)
-		api.PUT("/cleaning/complete/:id", completeCleaningTask)
-		api.GET("/cleaning/tasks", getCleaningTasks)
-
-		// Admin routes - User management
-		api.GET("/admin/users", getUsers)
-		api.POST("/admin/users", createUser)
-		api.PUT("/admin/users/:id", updateUser)
-		api.DELETE("/admin/users/:id", deleteUser)
+		staff.POST("/cleaning/start", h.startCleaningTask)
+		staff.PUT("/cleaning/begin/:id", h.beginCleaningTask)
+		staff.PUT("/cleaning/complete/:id", h.completeCleaningTask)
+		staff.GET("/cleaning/tasks", h.getCleaningTasks)
+
+		// Push notification routes
+		staff.GET("/push/vapid-public-key", getVAPIDPublicKey)
+		staff.POST("/push/subscriptions", h.requireStaff, subscribePush)
+		staff.DELETE("/push/subscriptions", h.requireStaff, unsubscribePush)
+
+		// Admin routes - User management; admin rolü verilebildiği için sadece admin
+		staff.GET("/admin/users", h.requireAdmin, h.getUsers)
+		staff.POST("/admin/users", h.requireAdmin, h.createUser)
+		staff.PUT("/admin/users/:id", h.requireAdmin, h.updateUser)
+		staff.DELETE("/admin/users/:id", h.requireAdmin, h.deleteUser)
+		staff.GET("/admin/users/:id/notifications", h.requireAdmin, getNotificationPreferences)
+		staff.PUT("/admin/users/:id/notifications", h.requireAdmin, updateNotificationPreferences)
+
+		// Admin routes - Cleaning tasks and push
+		staff.POST("/admin/cleaning/assign", h.requireAdmin, h.assignCleaningTask)
+		staff.POST("/admin/push/vapid/rotate", h.requireAdmin, rotateVAPIDKeys)
 
 		// Admin routes - Statistics
-		api.GET("/admin/stats", getAdminStats)
+		staff.GET("/admin/stats", h.requireAdmin, h.getAdminStats)
+		staff.GET("/admin/analytics", h.requireAdmin, getAnalytics)
+		staff.GET("/admin/analytics/heatmap", h.requireAdmin, getProblemHeatmap)
+		staff.GET("/admin/reports/cleaners", h.requireAdmin, getCleanerPerformance)
+		staff.GET("/admin/reports/management", h.requireAdmin, getManagementReports)
+		staff.POST("/admin/reports/management", h.requireAdmin, createManagementReport)
+		staff.GET("/admin/reports/management/:id/download", h.requireAdmin, downloadManagementReport)
+
+		// Admin routes - Export
+		staff.GET("/admin/export/ratings", h.requireAdmin, exportRatings)
+		staff.GET("/admin/export/tasks", h.requireAdmin, exportTasks)
+		staff.GET("/admin/export/cleaner-stats", h.requireAdmin, h.exportCleanerStats)
+
+		// Admin routes - QR codes; token'ı bilen herkes puan verebildiği için sadece admin
+		staff.GET("/admin/qr/sheet", h.requireAdmin, h.getQRSheet)
+		staff.GET("/admin/toilets/:toiletId/token", h.requireAdmin, h.getToiletRatingToken)
+		staff.POST("/admin/toilets/:toiletId/token/rotate", h.requireAdmin, h.rotateToiletRatingToken)
+		staff.POST("/admin/toilets/:toiletId/token/revoke", h.requireAdmin, h.revokeToiletRatingToken)
+
+		// Admin routes - Webhooks; abonelikler sunucunun dışarıya istek atmasını sağladığı için sadece admin
+		staff.GET("/admin/webhooks", h.requireAdmin, getWebhooks)
+		staff.POST("/admin/webhooks", h.requireAdmin, createWebhook)
+		staff.PUT("/admin/webhooks/:id", h.requireAdmin, updateWebhook)
+		staff.DELETE("/admin/webhooks/:id", h.requireAdmin, deleteWebhook)
+		staff.GET("/admin/webhooks/:id/deliveries", h.requireAdmin, getWebhookDeliveries)
+		staff.POST("/admin/webhooks/:id/test", h.requireAdmin, testWebhook)
+		staff.POST("/admin/webhooks/deliveries/:deliveryId/retry", h.requireAdmin, retryWebhookDelivery)
 	}
 }
 
@@ -68,68 +126,143 @@ func hashPassword(password string) string {
 	return hex.EncodeToString(hash[:])
 }
 
+var sessionSecret []byte
+
+// InitSessions oturum tokenlarını imzalamak için kullanılan anahtarı yükler
+func InitSessions() {
+	// Uzunluk LoadConfig içinde doğrulanır
+	sessionSecret = []byte(config.SessionSecret)
+}
+
+// userToken kullanıcı için SESSION_SECRET ile imzalanmış oturum tokenı üretir;
+// şifre hash'i imzaya dahil olduğundan şifre değişince eski oturumlar geçersiz olur
+func userToken(user User) string {
+	mac := hmac.New(sha256.New, sessionSecret)
+	mac.Write([]byte(strconv.FormatUint(uint64(user.ID), 10) + "." + user.Username + "." + user.Password))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// staffUserFromRequest Authorization ve X-User-ID başlıklarından giriş yapmış personeli doğrular
+func (h *Handlers) staffUserFromRequest(c *gin.Context) (User, bool) {
+	var user User
+
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	userID, err := strconv.ParseUint(c.GetHeader("X-User-ID"), 10, 32)
+	if token == "" || err != nil {
+		return user, false
+	}
+
+	user, err = h.Users.FindActiveByID(uint(userID))
+	if err != nil {
+		return user, false
+	}
+
+	return user, subtle.ConstantTimeCompare([]byte(token), []byte(userToken(user))) == 1
+}
+
+// staffUserKey doğrulanmış personelin gin context'indeki anahtarı
+const staffUserKey = "staff_user"
+
+// requireStaff giriş yapmamış personelin isteklerini reddeder; doğrulanan kullanıcı authenticatedUser ile alınır
+func (h *Handlers) requireStaff(c *gin.Context) {
+	user, ok := h.staffUserFromRequest(c)
+	if !ok {
+		respondError(c, apiError(CodeUnauthorized))
+		return
+	}
+	c.Set(staffUserKey, user)
+	c.Next()
+}
+
+// requireAdmin giriş yapmamış veya admin rolünde olmayan personelin isteklerini reddeder
+func (h *Handlers) requireAdmin(c *gin.Context) {
+	user, ok := h.staffUserFromRequest(c)
+	if !ok {
+		respondError(c, apiError(CodeUnauthorized))
+		return
+	}
+	if user.Role != "admin" {
+		respondError(c, apiError(CodeForbidden))
+		return
+	}
+	c.Set(staffUserKey, user)
+	c.Next()
+}
+
+// authenticatedUser requireStaff veya requireAdmin ile doğrulanmış kullanıcıyı döner
+func authenticatedUser(c *gin.Context) User {
+	return c.MustGet(staffUserKey).(User)
+}
+
 // login kullanıcı girişi yapar
-func login(c *gin.Context) {
+func (h *Handlers) login(c *gin.Context) {
 	var req LoginRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, LoginResponse{
-			Success: false,
-			Message: "Geçersiz veri formatı: " + err.Error(),
-		})
+		respondError(c, bindError(err))
 		return
 	}
 
 	// Kullanıcıyı veritabanında ara
-	var user User
-	if err := DB.Where("username = ? AND is_active = ?", req.Username, true).First(&user).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, LoginResponse{
-			Success: false,
-			Message: "Kullanıcı adı veya şifre hatalı",
-		})
+	user, err := h.Users.FindActiveByUsername(req.Username)
+	if err != nil {
+		respondError(c, apiError(CodeInvalidCredentials))
 		return
 	}
 
 	// Şifreyi kontrol et (basit SHA256 hash)
 	hashedPassword := hashPassword(req.Password)
 	if user.Password != hashedPassword {
-		c.JSON(http.StatusUnauthorized, LoginResponse{
-			Success: false,
-			Message: "Kullanıcı adı veya şifre hatalı",
-		})
+		respondError(c, apiError(CodeInvalidCredentials))
 		return
 	}
 
-	// Basit token oluştur (production'da JWT kullanın)
-	token := hashPassword(user.Username + "salt123")
+	// İmzalı oturum tokenı oluştur
+	token := userToken(user)
+
+	// Giriş yanıtı da kullanıcının kayıtlı dilinde döner
+	if supportedLanguage(user.Language) {
+		setRequestLanguage(c, user.Language)
+	}
 
 	c.JSON(http.StatusOK, LoginResponse{
 		Success: true,
-		Message: "Giriş başarılı",
+		Message: messageText(c, MsgLoginSucceeded),
 		Token:   token,
 		User:    &user,
 	})
 }
 
 // createRating yeni bir puanlama oluşturur
-func createRating(c *gin.Context) {
+func (h *Handlers) createRating(c *gin.Context) {
 	var req RatingRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, RatingResponse{
-			Success: false,
-			Message: "Geçersiz veri formatı: " + err.Error(),
-		})
+		respondError(c, bindError(err))
+		return
+	}
+
+	// Ziyaretçiler QR koddaki imzalı token ile puanlar; doğrudan tuvalet ID'si
+	// sadece giriş yapmış personelden kabul edilir
+	if req.Token != "" {
+		toilet, err := toiletFromRatingToken(h.Toilets, req.Token)
+		if err != nil {
+			respondError(c, ratingTokenError(err))
+			return
+		}
+		req.ToiletID = toilet.ID
+	} else if req.ToiletID == 0 {
+		respondError(c, apiError(CodeRatingTokenRequired))
+		return
+	} else if _, ok := h.staffUserFromRequest(c); !ok {
+		respondError(c, apiError(CodeRatingScanRequired))
 		return
 	}
 
 	// Problems slice'ını JSON string'e çevir
 	problemsJSON, err := json.Marshal(req.Problems)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, RatingResponse{
-			Success: false,
-			Message: "Sorun verileri işlenirken hata oluştu",
-		})
+		respondError(c, internalError(err, "Sorun verileri işlenirken hata oluştu"))
 		return
 	}
 
@@ -141,31 +274,28 @@ func createRating(c *gin.Context) {
 		OtherText: req.OtherText,
 	}
 
-	// Veritabanına kaydet
-	if err := DB.Create(&rating).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, RatingResponse{
-			Success: false,
-			Message: "Veritabanı kayıt hatası: " + err.Error(),
-		})
+	// Puanlamayı ve tuvalet özetini aynı transaction içinde kaydet
+	if err := h.Ratings.Create(&rating); err != nil {
+		respondError(c, internalError(err, "Veritabanı kayıt hatası"))
 		return
 	}
 
+	// Kötü değerlendirmeleri temizlikçilere bildir, acil sorunlar için görev aç
+	goBackground(func() { h.notifier.BadRating(rating) })
+	goBackground(func() { h.autoAssignUrgentTask(rating) })
+
 	c.JSON(http.StatusCreated, RatingResponse{
 		Success: true,
-		Message: "Puanlama başarıyla kaydedildi",
+		Message: messageText(c, MsgRatingCreated),
 		ID:      rating.ID,
 	})
 }
 
 // getRatings tüm puanlamaları getirir
-func getRatings(c *gin.Context) {
-	var ratings []Rating
-
-	if err := DB.Find(&ratings).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"message": "Veriler getirilirken hata oluştu: " + err.Error(),
-		})
+func (h *Handlers) getRatings(c *gin.Context) {
+	ratings, err := h.Ratings.List()
+	if err != nil {
+		respondError(c, internalError(err, "Veriler getirilirken hata oluştu"))
 		return
 	}
 
@@ -176,24 +306,18 @@ func getRatings(c *gin.Context) {
 }
 
 // getRating belirli bir puanlamayı getirir
-func getRating(c *gin.Context) {
+func (h *Handlers) getRating(c *gin.Context) {
 	id := c.Param("id")
 
 	ratingID, err := strconv.ParseUint(id, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"message": "Geçersiz ID formatı",
-		})
+		respondError(c, invalidParam("id"))
 		return
 	}
 
-	var rating Rating
-	if err := DB.First(&rating, uint(ratingID)).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"message": "Puanlama bulunamadı",
-		})
+	rating, err := h.Ratings.FindByID(uint(ratingID))
+	if err != nil {
+		respondError(c, lookupError(err, CodeRatingNotFound))
 		return
 	}
 
@@ -204,24 +328,18 @@ func getRating(c *gin.Context) {
 }
 
 // getToiletRatings belirli bir tuvalete ait tüm puanlamaları getirir
-func getToiletRatings(c *gin.Context) {
+func (h *Handlers) getToiletRatings(c *gin.Context) {
 	toiletIdStr := c.Param("toiletId")
 
 	toiletID, err := strconv.Atoi(toiletIdStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"message": "Geçersiz tuvalet ID formatı",
-		})
+		respondError(c, invalidParam("toiletId"))
 		return
 	}
 
-	var ratings []Rating
-	if err := DB.Where("toilet_id = ?", toiletID).Find(&ratings).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"message": "Veriler getirilirken hata oluştu: " + err.Error(),
-		})
+	ratings, err := h.Ratings.ListByToilet(toiletID)
+	if err != nil {
+		respondError(c, internalError(err, "Veriler getirilirken hata oluştu"))
 		return
 	}
 
@@ -234,14 +352,10 @@ func getToiletRatings(c *gin.Context) {
 }
 
 // getToilets tüm aktif tuvaletleri getirir
-func getToilets(c *gin.Context) {
-	var toilets []Toilet
-
-	if err := DB.Where("is_active = ?", true).Find(&toilets).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"message": "Veriler getirilirken hata oluştu: " + err.Error(),
-		})
+func (h *Handlers) getToilets(c *gin.Context) {
+	toilets, err := h.Toilets.ListActive()
+	if err != nil {
+		respondError(c, internalError(err, "Veriler getirilirken hata oluştu"))
 		return
 	}
 
@@ -252,114 +366,120 @@ func getToilets(c *gin.Context) {
 }
 
 // getToiletsStatus tüm tuvaletlerin durumunu getirir
-func getToiletsStatus(c *gin.Context) {
-	var toilets []Toilet
-
+func (h *Handlers) getToiletsStatus(c *gin.Context) {
 	// Aktif tuvaletleri getir
-	if err := DB.Where("is_active = ?", true).Find(&toilets).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"message": "Tuvaletler getirilirken hata oluştu: " + err.Error(),
-		})
+	toilets, err := h.Toilets.ListActive()
+	if err != nil {
+		respondError(c, internalError(err, "Tuvaletler getirilirken hata oluştu"))
 		return
 	}
 
-	var toiletStatuses []ToiletStatus
+	toiletStatuses, err := h.buildToiletStatuses(toilets)
+	if err != nil {
+		respondError(c, internalError(err, "Tuvalet durumları hesaplanırken hata oluştu"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    toiletStatuses,
+	})
+}
 
+// buildToiletStatuses tuvalet durumlarını tuvalet sayısından bağımsız olarak sabit sayıda sorguyla hesaplar
+func (h *Handlers) buildToiletStatuses(toilets []Toilet) ([]ToiletStatus, error) {
+	toiletStatuses := []ToiletStatus{}
+	if len(toilets) == 0 {
+		return toiletStatuses, nil
+	}
+
+	toiletIDs := make([]int, 0, len(toilets))
 	for _, toilet := range toilets {
-		var lastRating Rating
-		var hasLastRating bool
+		toiletIDs = append(toiletIDs, toilet.ID)
+	}
 
-		// Son puanlamayı getir
-		if err := DB.Where("toilet_id = ?", toilet.ID).Order("created_at DESC").First(&lastRating).Error; err == nil {
-			hasLastRating = true
-		}
+	// Önceden hesaplanmış puanlama özetleri (ortalama, toplam, son puanlama)
+	summaries, err := h.Ratings.Summaries(toiletIDs)
+	if err != nil {
+		return nil, err
+	}
 
-		// Ortalama puanı hesapla
-		var avgRating struct {
-			Average float64
-			Count   int64
+	summaryByToilet := make(map[int]ToiletRatingSummary, len(summaries))
+	lastRatingIDs := make([]uint, 0, len(summaries))
+	for _, summary := range summaries {
+		summaryByToilet[summary.ToiletID] = summary
+		if summary.LastRatingID != 0 {
+			lastRatingIDs = append(lastRatingIDs, summary.LastRatingID)
 		}
-		DB.Raw("SELECT AVG(rating) as average, COUNT(*) as count FROM ratings WHERE toilet_id = ?", toilet.ID).Scan(&avgRating)
+	}
 
-		// Problem sayısını hesapla
-		var problemCount int
-		hasProblems := false
-		var lastChecked *time.Time
+	// Her tuvaletin son puanlaması (birincil anahtar ile tek sorgu)
+	lastRatings, err := h.Ratings.FindByIDs(lastRatingIDs)
+	if err != nil {
+		return nil, err
+	}
 
-		if hasLastRating {
-			lastChecked = &lastRating.CreatedAt
+	lastRatingByToilet := make(map[int]Rating, len(lastRatings))
+	for _, rating := range lastRatings {
+		lastRatingByToilet[rating.ToiletID] = rating
+	}
 
-			// Problems JSON string'ini parse et
-			var problems []int
-			if err := json.Unmarshal([]byte(lastRating.Problems), &problems); err == nil {
-				problemCount = len(problems)
-				hasProblems = problemCount > 0
-			}
-		}
+	// Aktif temizlik görevleri (tuvalet başına en eski görev geçerli)
+	activeTasks, err := h.Tasks.ListActiveByToilets(toiletIDs)
+	if err != nil {
+		return nil, err
+	}
 
-		// Aktif temizlik görevini kontrol et
-		var cleaningTask CleaningTask
-		hasActiveTask := false
-		if err := DB.Where("toilet_id = ? AND status IN ?", toilet.ID, []string{"assigned", "in_progress"}).First(&cleaningTask).Error; err == nil {
-			hasActiveTask = true
+	activeTaskByToilet := make(map[int]CleaningTask, len(activeTasks))
+	for _, task := range activeTasks {
+		if _, exists := activeTaskByToilet[task.ToiletID]; !exists {
+			activeTaskByToilet[task.ToiletID] = task
 		}
+	}
 
-		status := ToiletStatus{
-			Toilet:        toilet,
-			HasProblems:   hasProblems,
-			ProblemCount:  problemCount,
-			LastChecked:   lastChecked,
-			AverageRating: avgRating.Average,
-			TotalRatings:  int(avgRating.Count),
+	for _, toilet := range toilets {
+		status := ToiletStatus{Toilet: toilet}
+
+		if summary, ok := summaryByToilet[toilet.ID]; ok {
+			status.AverageRating = summary.average()
+			status.TotalRatings = int(summary.RatingCount)
 		}
 
-		if hasLastRating {
+		if lastRating, ok := lastRatingByToilet[toilet.ID]; ok {
 			status.LastRating = &lastRating
+			status.LastChecked = &lastRating.CreatedAt
+
+			// Problems JSON string'ini parse et
+			var problems []int
+			if err := json.Unmarshal([]byte(lastRating.Problems), &problems); err == nil {
+				status.ProblemCount = len(problems)
+				status.HasProblems = status.ProblemCount > 0
+			}
 		}
 
-		if hasActiveTask {
-			status.CleaningTask = &cleaningTask
+		if task, ok := activeTaskByToilet[toilet.ID]; ok {
+			status.CleaningTask = &task
 		}
 
 		toiletStatuses = append(toiletStatuses, status)
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    toiletStatuses,
-	})
+	return toiletStatuses, nil
 }
 
 // startCleaningTask temizlik görevini başlatır
-func startCleaningTask(c *gin.Context) {
+func (h *Handlers) startCleaningTask(c *gin.Context) {
 	var req CleaningTaskRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, CleaningTaskResponse{
-			Success: false,
-			Message: "Geçersiz veri formatı: " + err.Error(),
-		})
-		return
-	}
-
-	// Bu tuvalet için aktif bir temizlik görevi var mı kontrol et
-	var existingTask CleaningTask
-	if err := DB.Where("toilet_id = ? AND status IN ?", req.ToiletID, []string{"assigned", "in_progress"}).First(&existingTask).Error; err == nil {
-		c.JSON(http.StatusConflict, CleaningTaskResponse{
-			Success: false,
-			Message: "Bu tuvalet için zaten aktif bir temizlik görevi var",
-		})
+		respondError(c, bindError(err))
 		return
 	}
 
 	// Authorization header'ından kullanıcı bilgisini al
 	authHeader := c.GetHeader("Authorization")
 	if authHeader == "" {
-		c.JSON(http.StatusUnauthorized, CleaningTaskResponse{
-			Success: false,
-			Message: "Yetkilendirme başlığı eksik",
-		})
+		respondError(c, apiError(CodeUnauthorized))
 		return
 	}
 
@@ -391,39 +511,31 @@ func startCleaningTask(c *gin.Context) {
 		CompletedAt: nil,
 	}
 
-	if err := DB.Create(&task).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, CleaningTaskResponse{
-			Success: false,
-			Message: "Temizlik görevi oluşturulurken hata oluştu: " + err.Error(),
-		})
+	// Tuvalette aktif görev varsa kayıt reddedilir
+	if err := h.Tasks.Create(&task); err != nil {
+		respondError(c, taskCreateError(err))
 		return
 	}
 
 	c.JSON(http.StatusCreated, CleaningTaskResponse{
 		Success: true,
-		Message: "Temizlik görevi başarıyla oluşturuldu",
+		Message: messageText(c, MsgTaskCreated),
 		Task:    &task,
 	})
 }
 
 // beginCleaningTask temizlik görevini başlat durumuna getirir
-func beginCleaningTask(c *gin.Context) {
+func (h *Handlers) beginCleaningTask(c *gin.Context) {
 	idStr := c.Param("id")
 	taskID, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, CleaningTaskResponse{
-			Success: false,
-			Message: "Geçersiz görev ID formatı",
-		})
+		respondError(c, invalidParam("id"))
 		return
 	}
 
-	var task CleaningTask
-	if err := DB.First(&task, uint(taskID)).Error; err != nil {
-		c.JSON(http.StatusNotFound, CleaningTaskResponse{
-			Success: false,
-			Message: "Temizlik görevi bulunamadı",
-		})
+	task, err := h.Tasks.FindByID(uint(taskID))
+	if err != nil {
+		respondError(c, lookupError(err, CodeTaskNotFound))
 		return
 	}
 
@@ -432,50 +544,33 @@ func beginCleaningTask(c *gin.Context) {
 	task.Status = "in_progress"
 	task.StartedAt = &now
 
-	if err := DB.Save(&task).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, CleaningTaskResponse{
-			Success: false,
-			Message: "Temizlik görevi güncellenirken hata oluştu: " + err.Error(),
-		})
+	if err := h.Tasks.Begin(&task); err != nil {
+		respondError(c, internalError(err, "Temizlik görevi güncellenirken hata oluştu"))
 		return
 	}
 
 	c.JSON(http.StatusOK, CleaningTaskResponse{
 		Success: true,
-		Message: "Temizlik görevi başlatıldı",
+		Message: messageText(c, MsgTaskStarted),
 		Task:    &task,
 	})
 }
 
 // completeCleaningTask temizlik görevini tamamlar
-func completeCleaningTask(c *gin.Context) {
+func (h *Handlers) completeCleaningTask(c *gin.Context) {
 	idStr := c.Param("id")
 	taskID, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, CleaningTaskResponse{
-			Success: false,
-			Message: "Geçersiz görev ID formatı",
-		})
+		respondError(c, invalidParam("id"))
 		return
 	}
 
-	var task CleaningTask
-	if err := DB.First(&task, uint(taskID)).Error; err != nil {
-		c.JSON(http.StatusNotFound, CleaningTaskResponse{
-			Success: false,
-			Message: "Temizlik görevi bulunamadı",
-		})
+	task, err := h.Tasks.FindByID(uint(taskID))
+	if err != nil {
+		respondError(c, lookupError(err, CodeTaskNotFound))
 		return
 	}
 
-	// Transaction ile işlemi gerçekleştir
-	tx := DB.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-		}
-	}()
-
 	// Görevin durumunu güncelle
 	now := time.Now()
 	task.Status = "completed"
@@ -485,15 +580,6 @@ func completeCleaningTask(c *gin.Context) {
 		task.StartedAt = &now
 	}
 
-	if err := tx.Save(&task).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, CleaningTaskResponse{
-			Success: false,
-			Message: "Temizlik görevi güncellenirken hata oluştu: " + err.Error(),
-		})
-		return
-	}
-
 	// Temizlik tamamlandığında otomatik olarak temiz bir rating oluştur
 	// Böylece tuvalet "temiz" olarak gözükecek
 	cleanRating := Rating{
@@ -505,52 +591,35 @@ func completeCleaningTask(c *gin.Context) {
 		UpdatedAt: now,
 	}
 
-	if err := tx.Create(&cleanRating).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, CleaningTaskResponse{
-			Success: false,
-			Message: "Temizlik sonrası değerlendirme oluşturulurken hata oluştu: " + err.Error(),
-		})
-		return
-	}
-
-	// Transaction'ı tamamla
-	if err := tx.Commit().Error; err != nil {
-		c.JSON(http.StatusInternalServerError, CleaningTaskResponse{
-			Success: false,
-			Message: "İşlem tamamlanırken hata oluştu: " + err.Error(),
-		})
+	// Görev, puanlama ve özet tek transaction içinde kaydedilir
+	if err := h.Tasks.Complete(&task, &cleanRating); err != nil {
+		respondError(c, internalError(err, "Temizlik görevi tamamlanırken hata oluştu"))
 		return
 	}
 
 	c.JSON(http.StatusOK, CleaningTaskResponse{
 		Success: true,
-		Message: "Temizlik görevi başarıyla tamamlandı",
+		Message: messageText(c, MsgTaskCompleted),
 		Task:    &task,
 	})
 }
 
 // getCleaningTasks temizlik görevlerini getirir
-func getCleaningTasks(c *gin.Context) {
-	status := c.Query("status")
-	toiletID := c.Query("toilet_id")
-
-	query := DB.Model(&CleaningTask{})
-
-	if status != "" {
-		query = query.Where("status = ?", status)
-	}
+func (h *Handlers) getCleaningTasks(c *gin.Context) {
+	filter := TaskFilter{Status: c.Query("status")}
 
-	if toiletID != "" {
-		query = query.Where("toilet_id = ?", toiletID)
+	if toiletIDStr := c.Query("toilet_id"); toiletIDStr != "" {
+		toiletID, err := strconv.Atoi(toiletIDStr)
+		if err != nil {
+			respondError(c, invalidParam("toilet_id"))
+			return
+		}
+		filter.ToiletID = toiletID
 	}
 
-	var tasks []CleaningTask
-	if err := query.Order("created_at DESC").Find(&tasks).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"message": "Temizlik görevleri getirilirken hata oluştu: " + err.Error(),
-		})
+	tasks, err := h.Tasks.List(filter)
+	if err != nil {
+		respondError(c, internalError(err, "Temizlik görevleri getirilirken hata oluştu"))
 		return
 	}
 
@@ -560,43 +629,132 @@ func getCleaningTasks(c *gin.Context) {
 	})
 }
 
+// urgentProblemIDs bildirildiğinde otomatik temizlik görevi açılan sorunlar
+var urgentProblemIDs = []int{5} // Klozet kirli
+
+// autoAssignUrgentTask acil sorun bildirilen tuvalet için en az görevi olan temizlikçiye görev açar
+func (h *Handlers) autoAssignUrgentTask(rating Rating) {
+	var problemIDs []int
+	if err := json.Unmarshal([]byte(rating.Problems), &problemIDs); err != nil {
+		return
+	}
+
+	urgentProblem := 0
+	for _, problemID := range problemIDs {
+		for _, urgentID := range urgentProblemIDs {
+			if problemID == urgentID {
+				urgentProblem = problemID
+			}
+		}
+	}
+	if urgentProblem == 0 {
+		return
+	}
+
+	// Devam eden görevi en az olan aktif temizlikçiyi bul
+	cleaner, err := h.Users.LeastBusyCleaner()
+	if err != nil {
+		log.Printf("Acil görev için temizlikçi bulunamadı (tuvalet %d): %v", rating.ToiletID, err)
+		return
+	}
+
+	task := CleaningTask{
+		ToiletID:    rating.ToiletID,
+		CleanerID:   cleaner.ID,
+		CleanerName: cleaner.Name,
+		Status:      "assigned",
+	}
+	// Bu tuvalet için zaten aktif bir görev varsa yenisi açılmaz
+	if err := h.Tasks.Create(&task); errors.Is(err, ErrTaskActive) {
+		return
+	} else if err != nil {
+		log.Printf("Acil temizlik görevi oluşturulamadı (tuvalet %d): %v", rating.ToiletID, err)
+		return
+	}
+
+	h.notifier.TaskAssigned(task, urgentProblem)
+}
+
+// taskCreateError görev kaydı hatasına karşılık gelen API hatasını döner
+func taskCreateError(err error) *APIError {
+	switch {
+	case errors.Is(err, ErrTaskActive):
+		return apiError(CodeTaskAlreadyActive)
+	case errors.Is(err, ErrNotFound):
+		return apiError(CodeToiletNotFound)
+	}
+	return internalError(err, "Temizlik görevi oluşturulurken hata oluştu")
+}
+
+// assignCleaningTask admin tarafından belirli bir temizlikçiye görev atar (sadece admin erişimi)
+func (h *Handlers) assignCleaningTask(c *gin.Context) {
+	var req AssignTaskRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, bindError(err))
+		return
+	}
+
+	cleaner, err := h.Users.FindActiveCleaner(req.CleanerID)
+	if err != nil {
+		respondError(c, lookupError(err, CodeCleanerNotFound))
+		return
+	}
+
+	task := CleaningTask{
+		ToiletID:    req.ToiletID,
+		CleanerID:   cleaner.ID,
+		CleanerName: cleaner.Name,
+		Status:      "assigned",
+	}
+
+	// Tuvalette aktif görev varsa kayıt reddedilir
+	if err := h.Tasks.Create(&task); err != nil {
+		respondError(c, taskCreateError(err))
+		return
+	}
+
+	goBackground(func() { h.notifier.TaskAssigned(task, 0) })
+
+	c.JSON(http.StatusCreated, CleaningTaskResponse{
+		Success: true,
+		Message: messageText(c, MsgTaskAssigned),
+		Task:    &task,
+	})
+}
+
 // getUsers tüm kullanıcıları getirir (sadece admin erişimi)
-func getUsers(c *gin.Context) {
-	var users []User
-	if err := DB.Find(&users).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, UsersResponse{
-			Success: false,
-			Message: "Kullanıcılar getirilirken hata oluştu: " + err.Error(),
-		})
+func (h *Handlers) getUsers(c *gin.Context) {
+	users, err := h.Users.List()
+	if err != nil {
+		respondError(c, internalError(err, "Kullanıcılar getirilirken hata oluştu"))
 		return
 	}
 
 	c.JSON(http.StatusOK, UsersResponse{
 		Success: true,
-		Message: "Kullanıcılar başarıyla getirildi",
+		Message: messageText(c, MsgUsersFetched),
 		Users:   users,
 	})
 }
 
 // createUser yeni kullanıcı oluşturur (sadece admin erişimi)
-func createUser(c *gin.Context) {
+func (h *Handlers) createUser(c *gin.Context) {
 	var req CreateUserRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, UserResponse{
-			Success: false,
-			Message: "Geçersiz veri formatı: " + err.Error(),
-		})
+		respondError(c, bindError(err))
 		return
 	}
 
 	// Kullanıcı adı kontrolü
-	var existingUser User
-	if err := DB.Where("username = ?", req.Username).First(&existingUser).Error; err == nil {
-		c.JSON(http.StatusConflict, UserResponse{
-			Success: false,
-			Message: "Bu kullanıcı adı zaten kullanılıyor",
-		})
+	taken, err := h.Users.UsernameTaken(req.Username, 0)
+	if err != nil {
+		respondError(c, internalError(err, "Kullanıcı adı kontrol edilirken hata oluştu"))
+		return
+	}
+	if taken {
+		respondError(c, apiError(CodeUsernameTaken))
 		return
 	}
 
@@ -615,61 +773,73 @@ func createUser(c *gin.Context) {
 		Name:     req.Name,
 		Role:     req.Role,
 		IsActive: true,
+		Language: req.Language,
 	}
 
-	if err := DB.Create(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, UserResponse{
-			Success: false,
-			Message: "Kullanıcı oluşturulurken hata oluştu: " + err.Error(),
-		})
+	if err := h.Users.Create(&user); err != nil {
+		respondError(c, internalError(err, "Kullanıcı oluşturulurken hata oluştu"))
 		return
 	}
 
 	c.JSON(http.StatusCreated, UserResponse{
 		Success: true,
-		Message: "Kullanıcı başarıyla oluşturuldu",
+		Message: messageText(c, MsgUserCreated),
 		User:    &user,
 	})
 }
 
+// createAdmin ilk admin hesabını oluşturur; kullanıcı yönetimi admin girişi gerektirdiği için
+// sunucu dışından "create-admin" komutuyla çağrılır
+func createAdmin(users UserRepository, username, password, name string) (User, error) {
+	user := User{
+		Username: username,
+		Password: hashPassword(password),
+		Name:     name,
+		Role:     "admin",
+		IsActive: true,
+	}
+
+	taken, err := users.UsernameTaken(username, 0)
+	if err != nil {
+		return user, err
+	}
+	if taken {
+		return user, fmt.Errorf("%q kullanıcı adı zaten kullanılıyor", username)
+	}
+
+	return user, users.Create(&user)
+}
+
 // updateUser kullanıcı bilgilerini günceller (sadece admin erişimi)
-func updateUser(c *gin.Context) {
+func (h *Handlers) updateUser(c *gin.Context) {
 	idStr := c.Param("id")
 	userID, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, UserResponse{
-			Success: false,
-			Message: "Geçersiz kullanıcı ID formatı",
-		})
+		respondError(c, invalidParam("id"))
 		return
 	}
 
 	var req UpdateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, UserResponse{
-			Success: false,
-			Message: "Geçersiz veri formatı: " + err.Error(),
-		})
+		respondError(c, bindError(err))
 		return
 	}
 
-	var user User
-	if err := DB.First(&user, uint(userID)).Error; err != nil {
-		c.JSON(http.StatusNotFound, UserResponse{
-			Success: false,
-			Message: "Kullanıcı bulunamadı",
-		})
+	user, err := h.Users.FindByID(uint(userID))
+	if err != nil {
+		respondError(c, lookupError(err, CodeUserNotFound))
 		return
 	}
 
 	// Kullanıcı adı değişikliği kontrolü
 	if req.Username != "" && req.Username != user.Username {
-		var existingUser User
-		if err := DB.Where("username = ? AND id != ?", req.Username, userID).First(&existingUser).Error; err == nil {
-			c.JSON(http.StatusConflict, UserResponse{
-				Success: false,
-				Message: "Bu kullanıcı adı zaten kullanılıyor",
-			})
+		taken, err := h.Users.UsernameTaken(req.Username, user.ID)
+		if err != nil {
+			respondError(c, internalError(err, "Kullanıcı adı kontrol edilirken hata oluştu"))
+			return
+		}
+		if taken {
+			respondError(c, apiError(CodeUsernameTaken))
 			return
 		}
 		user.Username = req.Username
@@ -685,209 +855,281 @@ func updateUser(c *gin.Context) {
 	if req.Password != "" {
 		user.Password = hashPassword(req.Password)
 	}
+	if req.Language != "" {
+		user.Language = req.Language
+	}
 	if req.IsActive != nil {
 		user.IsActive = *req.IsActive
 	}
 
-	if err := DB.Save(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, UserResponse{
-			Success: false,
-			Message: "Kullanıcı güncellenirken hata oluştu: " + err.Error(),
-		})
+	if err := h.Users.Save(&user); err != nil {
+		respondError(c, internalError(err, "Kullanıcı güncellenirken hata oluştu"))
 		return
 	}
 
 	c.JSON(http.StatusOK, UserResponse{
 		Success: true,
-		Message: "Kullanıcı başarıyla güncellendi",
+		Message: messageText(c, MsgUserUpdated),
 		User:    &user,
 	})
 }
 
 // deleteUser kullanıcıyı siler (sadece admin erişimi)
-func deleteUser(c *gin.Context) {
+func (h *Handlers) deleteUser(c *gin.Context) {
 	idStr := c.Param("id")
 	userID, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, UserResponse{
-			Success: false,
-			Message: "Geçersiz kullanıcı ID formatı",
-		})
+		respondError(c, invalidParam("id"))
 		return
 	}
 
-	var user User
-	if err := DB.First(&user, uint(userID)).Error; err != nil {
-		c.JSON(http.StatusNotFound, UserResponse{
-			Success: false,
-			Message: "Kullanıcı bulunamadı",
-		})
+	user, err := h.Users.FindByID(uint(userID))
+	if err != nil {
+		respondError(c, lookupError(err, CodeUserNotFound))
 		return
 	}
 
-	// Kullanıcıyı sil
-	if err := DB.Delete(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, UserResponse{
-			Success: false,
-			Message: "Kullanıcı silinirken hata oluştu: " + err.Error(),
-		})
+	// Kullanıcıyı bildirim tercihleriyle birlikte sil
+	if err := h.Users.Delete(user); err != nil {
+		respondError(c, internalError(err, "Kullanıcı silinirken hata oluştu"))
 		return
 	}
 
 	c.JSON(http.StatusOK, UserResponse{
 		Success: true,
-		Message: "Kullanıcı başarıyla silindi",
+		Message: messageText(c, MsgUserDeleted),
 	})
 }
 
 // getAdminStats admin paneli için istatistikleri getirir
-func getAdminStats(c *gin.Context) {
-	// Sistem geneli istatistikler
-	var systemStats SystemStats
+func (h *Handlers) getAdminStats(c *gin.Context) {
+	systemStats, err := h.Stats.System()
+	if err != nil {
+		respondError(c, internalError(err, "Sistem istatistikleri hesaplanırken hata oluştu"))
+		return
+	}
 
-	// Toplam tuvalet sayısı
-	DB.Model(&Toilet{}).Count(&systemStats.TotalToilets)
+	cleanerStats, err := h.Stats.Cleaners(analyticsFilter{})
+	if err != nil {
+		respondError(c, internalError(err, "Temizlikçi istatistikleri hesaplanırken hata oluştu"))
+		return
+	}
 
-	// Aktif tuvalet sayısı
-	DB.Model(&Toilet{}).Where("is_active = ?", true).Count(&systemStats.ActiveToilets)
+	c.JSON(http.StatusOK, StatsResponse{
+		Success:      true,
+		Message:      messageText(c, MsgStatsFetched),
+		SystemStats:  systemStats,
+		CleanerStats: cleanerStats,
+	})
+}
 
-	// Problem olan tuvalet sayısı (son 24 saat içinde problem bildirilen)
-	var problemToilets []int
-	DB.Model(&Rating{}).
-		Select("DISTINCT toilet_id").
-		Where("problems IS NOT NULL AND problems != '' AND problems != '[]' AND created_at > ?", time.Now().Add(-24*time.Hour)).
-		Pluck("toilet_id", &problemToilets)
-	systemStats.ToiletsWithProblems = len(problemToilets)
+// computeSystemStats sistem geneli istatistikleri tek sorguda hesaplar
+func computeSystemStats(db *gorm.DB) (*SystemStats, error) {
+	var row struct {
+		TotalToilets        int64
+		ActiveToilets       int64
+		ToiletsWithProblems int64
+		TotalCleaners       int64
+		ActiveCleaners      int64
+		TotalRatings        sql.NullInt64
+		AverageRating       sql.NullFloat64
+		CompletedTasksToday int64
+		OngoingTasks        int64
+	}
 
-	// Toplam temizlikçi sayısı
-	DB.Model(&User{}).Where("role = ?", "temizlikci").Count(&systemStats.TotalCleaners)
+	// Problem olan tuvaletler: son 24 saat içinde problem bildirilenler
+	problemsSince := time.Now().Add(-24 * time.Hour)
+	today := startOfDay(time.Now())
+
+	err := db.Raw(`
+		SELECT
+			(SELECT COUNT(*) FROM toilets) AS total_toilets,
+			(SELECT COUNT(*) FROM toilets WHERE is_active = ?) AS active_toilets,
+			(SELECT COUNT(DISTINCT toilet_id) FROM ratings
+				WHERE problems IS NOT NULL AND problems != '' AND problems != '[]' AND created_at > ?) AS toilets_with_problems,
+			(SELECT COUNT(*) FROM users WHERE role = ?) AS total_cleaners,
+			(SELECT COUNT(*) FROM users WHERE role = ? AND is_active = ?) AS active_cleaners,
+			(SELECT SUM(rating_count) FROM toilet_rating_summary) AS total_ratings,
+			(SELECT 1.0 * SUM(rating_sum) / NULLIF(SUM(rating_count), 0) FROM toilet_rating_summary) AS average_rating,
+			(SELECT COUNT(*) FROM cleaning_tasks WHERE status = ? AND completed_at >= ?) AS completed_tasks_today,
+			(SELECT COUNT(*) FROM cleaning_tasks WHERE status IN ?) AS ongoing_tasks`,
+		true,
+		problemsSince,
+		"temizlikci",
+		"temizlikci", true,
+		"completed", today,
+		[]string{"assigned", "in_progress"},
+	).Scan(&row).Error
+	if err != nil {
+		return nil, err
+	}
 
-	// Aktif temizlikçi sayısı
-	DB.Model(&User{}).Where("role = ? AND is_active = ?", "temizlikci", true).Count(&systemStats.ActiveCleaners)
+	return &SystemStats{
+		TotalToilets:        row.TotalToilets,
+		ActiveToilets:       row.ActiveToilets,
+		ToiletsWithProblems: int(row.ToiletsWithProblems),
+		TotalCleaners:       row.TotalCleaners,
+		ActiveCleaners:      row.ActiveCleaners,
+		TotalRatings:        row.TotalRatings.Int64,
+		AverageRating:       row.AverageRating.Float64,
+		CompletedTasksToday: row.CompletedTasksToday,
+		OngoingTasks:        row.OngoingTasks,
+	}, nil
+}
 
-	// Toplam değerlendirme sayısı
-	DB.Model(&Rating{}).Count(&systemStats.TotalRatings)
+// computeCleanerStats temizlikçilerin istatistiklerini temizlikçi sayısından bağımsız olarak
+// iki sorguda hesaplar: temizlikçi listesi ve cleaner_id'ye göre gruplanmış görev özetleri.
+// Filtrenin boş tarihleri sınır koymaz; tamamlanan görevler aralıkla, tüm görevler tuvalet
+// ve temizlikçi filtresiyle sınırlanır. Devam eden görevler tarihten bağımsız güncel durumdur.
+func computeCleanerStats(db *gorm.DB, filter analyticsFilter) ([]CleanerStats, error) {
+	cleanerQuery := db.Where("role = ?", "temizlikci")
+	if filter.CleanerID != 0 {
+		cleanerQuery = cleanerQuery.Where("id = ?", filter.CleanerID)
+	}
 
-	// Ortalama puan
-	var avgRating sql.NullFloat64
-	DB.Model(&Rating{}).Select("AVG(rating)").Scan(&avgRating)
-	if avgRating.Valid {
-		systemStats.AverageRating = avgRating.Float64
+	var cleaners []User
+	if err := cleanerQuery.Order("id ASC").Find(&cleaners).Error; err != nil {
+		return nil, err
 	}
 
-	// Bugün tamamlanan görev sayısı
-	today := time.Now().Truncate(24 * time.Hour)
-	DB.Model(&CleaningTask{}).
-		Where("status = ? AND completed_at >= ?", "completed", today).
-		Count(&systemStats.CompletedTasksToday)
+	if len(cleaners) == 0 {
+		return []CleanerStats{}, nil
+	}
 
-	// Devam eden görev sayısı
-	DB.Model(&CleaningTask{}).
-		Where("status IN (?)", []string{"assigned", "in_progress"}).
-		Count(&systemStats.OngoingTasks)
+	cleanerIDs := make([]uint, 0, len(cleaners))
+	for _, cleaner := range cleaners {
+		cleanerIDs = append(cleanerIDs, cleaner.ID)
+	}
 
-	// Temizlikçi istatistikleri
-	var cleanerStats []CleanerStats
+	var rows []struct {
+		CleanerID           uint
+		TotalCompletedTasks int64
+		LastWeekTasks       int64
+		LastMonthTasks      int64
+		OngoingTasks        int64
+	}
 
-	// Tüm temizlikçileri al
-	var cleaners []User
-	DB.Where("role = ?", "temizlikci").Find(&cleaners)
+	// Son hafta ve son ay, alan adlarının söylediği gibi son 7 ve 30 günlük kayan pencerelerdir;
+	// mutlak süre oldukları için saat diliminden ve yaz saati geçişlerinden etkilenmezler
+	now := time.Now()
+	lastWeek := now.Add(-7 * 24 * time.Hour)
+	lastMonth := now.Add(-30 * 24 * time.Hour)
+
+	// Tamamlanma koşulu filtre aralığını içerir; aralık başı son hafta ve ay pencerelerini de daraltır
+	completed := "status = 'completed'"
+	var rangeArgs []interface{}
+	if !filter.From.IsZero() {
+		completed += " AND completed_at >= ?"
+		rangeArgs = append(rangeArgs, filter.From)
+	}
+	if !filter.To.IsZero() {
+		completed += " AND completed_at < ?"
+		rangeArgs = append(rangeArgs, filter.To)
+	}
 
-	for _, cleaner := range cleaners {
-		var stats CleanerStats
-		stats.CleanerID = cleaner.ID
-		stats.CleanerName = cleaner.Name
-		stats.IsActive = cleaner.IsActive
-
-		// Tamamlanan görev sayısı
-		DB.Model(&CleaningTask{}).
-			Where("cleaner_id = ? AND status = ?", cleaner.ID, "completed").
-			Count(&stats.TotalCompletedTasks)
-
-		// Ortalama temizlik süresi (dakika)
-		var avgTime sql.NullFloat64
-		DB.Model(&CleaningTask{}).
-			Select("AVG(TIMESTAMPDIFF(MINUTE, started_at, completed_at))").
-			Where("cleaner_id = ? AND status = ? AND started_at IS NOT NULL AND completed_at IS NOT NULL", cleaner.ID, "completed").
-			Scan(&avgTime)
-		if avgTime.Valid {
-			stats.AverageCleaningTime = avgTime.Float64
-		} else {
-			stats.AverageCleaningTime = -1 // Hiç temizlik yapılmadığını belirtmek için
-		}
+	args := append([]interface{}{}, rangeArgs...)
+	args = append(args, rangeArgs...)
+	args = append(args, lastWeek)
+	args = append(args, rangeArgs...)
+	args = append(args, lastMonth)
+
+	err := filter.scopeToilets(db.Model(&CleaningTask{})).
+		Select(`cleaner_id,
+			SUM(CASE WHEN `+completed+` THEN 1 ELSE 0 END) AS total_completed_tasks,
+			SUM(CASE WHEN `+completed+` AND completed_at >= ? THEN 1 ELSE 0 END) AS last_week_tasks,
+			SUM(CASE WHEN `+completed+` AND completed_at >= ? THEN 1 ELSE 0 END) AS last_month_tasks,
+			SUM(CASE WHEN status IN ('assigned', 'in_progress') THEN 1 ELSE 0 END) AS ongoing_tasks`,
+			args...).
+		Where("cleaner_id IN ?", cleanerIDs).
+		Group("cleaner_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
 
-		// En hızlı temizlik süresi
-		var fastestTime sql.NullFloat64
-		DB.Model(&CleaningTask{}).
-			Select("MIN(TIMESTAMPDIFF(MINUTE, started_at, completed_at))").
-			Where("cleaner_id = ? AND status = ? AND started_at IS NOT NULL AND completed_at IS NOT NULL", cleaner.ID, "completed").
-			Scan(&fastestTime)
-		if fastestTime.Valid {
-			stats.FastestCleaningTime = fastestTime.Float64
-		} else {
-			stats.FastestCleaningTime = -1 // Hiç temizlik yapılmadığını belirtmek için
-		}
+	rowByCleaner := make(map[uint]int, len(rows))
+	for i, row := range rows {
+		rowByCleaner[row.CleanerID] = i
+	}
 
-		// En yavaş temizlik süresi
-		var slowestTime sql.NullFloat64
-		DB.Model(&CleaningTask{}).
-			Select("MAX(TIMESTAMPDIFF(MINUTE, started_at, completed_at))").
-			Where("cleaner_id = ? AND status = ? AND started_at IS NOT NULL AND completed_at IS NOT NULL", cleaner.ID, "completed").
-			Scan(&slowestTime)
-		if slowestTime.Valid {
-			stats.SlowestCleaningTime = slowestTime.Float64
-		} else {
-			stats.SlowestCleaningTime = -1 // Hiç temizlik yapılmadığını belirtmek için
+	// Süreler saniye hassasiyetinde Go tarafında hesaplanır; TIMESTAMPDIFF(MINUTE) tam dakikaya yuvarlıyordu
+	var timedTasks []CleaningTask
+	err = filter.scopeToilets(db.Select("cleaner_id, started_at, completed_at")).
+		Where("cleaner_id IN ? AND started_at IS NOT NULL AND completed_at IS NOT NULL", cleanerIDs).
+		Where(completed, rangeArgs...).
+		Find(&timedTasks).Error
+	if err != nil {
+		return nil, err
+	}
+
+	durations := make(map[uint][]float64, len(cleaners))
+	outliers := make(map[uint]int64, len(cleaners))
+	for _, task := range timedTasks {
+		minutes, ok := cleaningMinutes(task)
+		if !ok {
+			continue
 		}
+		if isCleaningOutlier(minutes) {
+			outliers[task.CleanerID]++
+			continue
+		}
+		durations[task.CleanerID] = append(durations[task.CleanerID], minutes)
+	}
 
-		// Toplam temizlik süresi
-		var totalTime sql.NullFloat64
-		DB.Model(&CleaningTask{}).
-			Select("SUM(TIMESTAMPDIFF(MINUTE, started_at, completed_at))").
-			Where("cleaner_id = ? AND status = ? AND started_at IS NOT NULL AND completed_at IS NOT NULL", cleaner.ID, "completed").
-			Scan(&totalTime)
-		if totalTime.Valid {
-			stats.TotalCleaningTime = totalTime.Float64
+	cleanerStats := make([]CleanerStats, 0, len(cleaners))
+	for _, cleaner := range cleaners {
+		stats := CleanerStats{
+			CleanerID:        cleaner.ID,
+			CleanerName:      cleaner.Name,
+			IsActive:         cleaner.IsActive,
+			ExcludedOutliers: outliers[cleaner.ID],
 		}
 
-		// Son hafta görev sayısı
-		lastWeek := time.Now().Add(-7 * 24 * time.Hour)
-		DB.Model(&CleaningTask{}).
-			Where("cleaner_id = ? AND status = ? AND completed_at >= ?", cleaner.ID, "completed", lastWeek).
-			Count(&stats.LastWeekTasks)
+		if i, ok := rowByCleaner[cleaner.ID]; ok {
+			row := rows[i]
+			stats.TotalCompletedTasks = row.TotalCompletedTasks
+			stats.LastWeekTasks = row.LastWeekTasks
+			stats.LastMonthTasks = row.LastMonthTasks
+			stats.OngoingTasks = row.OngoingTasks
+		}
 
-		// Son ay görev sayısı
-		lastMonth := time.Now().Add(-30 * 24 * time.Hour)
-		DB.Model(&CleaningTask{}).
-			Where("cleaner_id = ? AND status = ? AND completed_at >= ?", cleaner.ID, "completed", lastMonth).
-			Count(&stats.LastMonthTasks)
+		stats.applyCleaningDurations(durations[cleaner.ID])
+		cleanerStats = append(cleanerStats, stats)
+	}
 
-		// Devam eden görev sayısı
-		DB.Model(&CleaningTask{}).
-			Where("cleaner_id = ? AND status IN (?)", cleaner.ID, []string{"assigned", "in_progress"}).
-			Count(&stats.OngoingTasks)
+	return cleanerStats, nil
+}
 
-		cleanerStats = append(cleanerStats, stats)
+// applyCleaningDurations aykırı değerleri ayıklanmış temizlik sürelerinden toplam, ortalama ve yüzdelikleri doldurur
+func (stats *CleanerStats) applyCleaningDurations(values []float64) {
+	if len(values) == 0 {
+		return
 	}
+	sort.Float64s(values)
 
-	c.JSON(http.StatusOK, StatsResponse{
-		Success:      true,
-		Message:      "İstatistikler başarıyla getirildi",
-		SystemStats:  &systemStats,
-		CleanerStats: cleanerStats,
-	})
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	average := total / float64(len(values))
+	median := percentile(values, 0.5)
+	p90 := percentile(values, 0.9)
+
+	stats.TotalCleaningTime = total
+	stats.AverageCleaningTime = &average
+	stats.MedianCleaningTime = &median
+	stats.P90CleaningTime = &p90
+	stats.FastestCleaningTime = &values[0]
+	stats.SlowestCleaningTime = &values[len(values)-1]
 }
 
 // getToiletRatingsPaginated belirli bir tuvalete ait puanlamaları sayfalama ile getirir
-func getToiletRatingsPaginated(c *gin.Context) {
+func (h *Handlers) getToiletRatingsPaginated(c *gin.Context) {
 	toiletIdStr := c.Param("toiletId")
 
 	toiletID, err := strconv.Atoi(toiletIdStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"message": "Geçersiz tuvalet ID formatı",
-		})
+		respondError(c, invalidParam("toiletId"))
 		return
 	}
 
@@ -907,45 +1149,22 @@ func getToiletRatingsPaginated(c *gin.Context) {
 
 	offset := (page - 1) * limit
 
-	var ratings []Rating
-	var totalCount int64
-
-	// Toplam sayıyı al
-	DB.Model(&Rating{}).Where("toilet_id = ?", toiletID).Count(&totalCount)
-
-	// Sayfalı veriyi al (en yeniden eskiye doğru)
-	if err := DB.Where("toilet_id = ?", toiletID).
-		Order("created_at DESC").
-		Limit(limit).
-		Offset(offset).
-		Find(&ratings).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"message": "Veriler getirilirken hata oluştu: " + err.Error(),
-		})
+	// Sayfalı veriyi ve toplam sayıyı al (en yeniden eskiye doğru)
+	ratings, totalCount, err := h.Ratings.PageByToilet(toiletID, limit, offset)
+	if err != nil {
+		respondError(c, internalError(err, "Veriler getirilirken hata oluştu"))
 		return
 	}
 
-	// Detayları doldur
+	// Detayları doldur; problem metinleri isteği yapanın dilinde
+	lang := requestLanguage(c)
 	var ratingDetails []RatingDetail
 	for _, rating := range ratings {
-		detail := RatingDetail{
+		ratingDetails = append(ratingDetails, RatingDetail{
 			Rating:    rating,
-			Problems:  []string{},
+			Problems:  ratingProblemTexts(rating, lang),
 			CreatedAt: rating.CreatedAt,
-		}
-
-		// Problems JSON string'ini parse et
-		var problemIDs []int
-		if err := json.Unmarshal([]byte(rating.Problems), &problemIDs); err == nil {
-			for _, problemID := range problemIDs {
-				if problemText, exists := ProblemTypes[problemID]; exists {
-					detail.Problems = append(detail.Problems, problemText)
-				}
-			}
-		}
-
-		ratingDetails = append(ratingDetails, detail)
+		})
 	}
 
 	// Toplam sayfa sayısını hesapla
@@ -953,7 +1172,7 @@ func getToiletRatingsPaginated(c *gin.Context) {
 
 	response := PaginatedRatingsResponse{
 		Success:     true,
-		Message:     "Değerlendirmeler başarıyla getirildi",
+		Message:     messageText(c, MsgRatingsFetched),
 		Data:        ratingDetails,
 		ToiletID:    toiletID,
 		Page:        page,
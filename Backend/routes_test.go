@@ -0,0 +1,788 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestLogin(t *testing.T) {
+	s := newTestServer(t)
+	user := s.createUser("ayse", "Ayşe Yılmaz", "temizlikci")
+
+	t.Run("başarılı", func(t *testing.T) {
+		rec := s.request(http.MethodPost, "/api/login", LoginRequest{Username: "ayse", Password: "parola-ayse"}, nil)
+		expectStatus(t, rec, http.StatusOK)
+
+		var resp LoginResponse
+		decode(t, rec, &resp)
+		if !resp.Success || resp.Token != userToken(user) || resp.User == nil || resp.User.ID != user.ID {
+			t.Fatalf("Beklenmeyen giriş yanıtı: %+v", resp)
+		}
+	})
+
+	t.Run("hatalı şifre", func(t *testing.T) {
+		rec := s.request(http.MethodPost, "/api/login", LoginRequest{Username: "ayse", Password: "yanlis"}, nil)
+		expectStatus(t, rec, http.StatusUnauthorized)
+	})
+
+	t.Run("bilinmeyen kullanıcı", func(t *testing.T) {
+		rec := s.request(http.MethodPost, "/api/login", LoginRequest{Username: "yok", Password: "parola"}, nil)
+		expectStatus(t, rec, http.StatusUnauthorized)
+	})
+
+	t.Run("eksik alan", func(t *testing.T) {
+		rec := s.request(http.MethodPost, "/api/login", map[string]string{"username": "ayse"}, nil)
+		expectStatus(t, rec, http.StatusBadRequest)
+	})
+
+	t.Run("pasif kullanıcı", func(t *testing.T) {
+		inactive := false
+		rec := s.request(http.MethodPut, fmt.Sprintf("/api/admin/users/%d", user.ID), UpdateUserRequest{IsActive: &inactive}, s.adminHeaders())
+		expectStatus(t, rec, http.StatusOK)
+
+		rec = s.request(http.MethodPost, "/api/login", LoginRequest{Username: "ayse", Password: "parola-ayse"}, nil)
+		expectStatus(t, rec, http.StatusUnauthorized)
+	})
+}
+
+func TestSessionTokens(t *testing.T) {
+	s := newTestServer(t)
+	admin := s.createUser("mudur", "Müdür", "admin")
+	path := "/api/admin/toilets/1/token"
+
+	rec := s.request(http.MethodGet, path, nil, staffHeaders(admin))
+	expectStatus(t, rec, http.StatusOK)
+
+	// Kaynak kodda yazan sabit tuzla üretilen eski formattaki token kabul edilmez
+	headers := staffHeaders(admin)
+	headers["Authorization"] = "Bearer " + hashPassword(admin.Username+"salt123")
+	expectError(t, s, http.MethodGet, path, nil, headers, http.StatusUnauthorized, CodeUnauthorized)
+
+	// Başka anahtarla imzalanmış token kabul edilmez
+	previous := sessionSecret
+	sessionSecret = []byte("baska-oturum-anahtari")
+	headers = staffHeaders(admin)
+	sessionSecret = previous
+	expectError(t, s, http.MethodGet, path, nil, headers, http.StatusUnauthorized, CodeUnauthorized)
+
+	// Şifre değişince eski oturum geçersiz olur
+	oldHeaders := staffHeaders(admin)
+	if err := s.db.Model(&User{}).Where("id = ?", admin.ID).Update("password", hashPassword("yeni-parola")).Error; err != nil {
+		t.Fatalf("Şifre güncellenemedi: %v", err)
+	}
+	expectError(t, s, http.MethodGet, path, nil, oldHeaders, http.StatusUnauthorized, CodeUnauthorized)
+
+	rec = s.request(http.MethodPost, "/api/login", LoginRequest{Username: "mudur", Password: "yeni-parola"}, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var resp LoginResponse
+	decode(t, rec, &resp)
+	headers = staffHeaders(admin)
+	headers["Authorization"] = "Bearer " + resp.Token
+	rec = s.request(http.MethodGet, path, nil, headers)
+	expectStatus(t, rec, http.StatusOK)
+}
+
+func TestCreateRating(t *testing.T) {
+	s := newTestServer(t)
+	staff := s.createUser("mehmet", "Mehmet Demir", "temizlikci")
+
+	t.Run("QR token ile", func(t *testing.T) {
+		rec := s.request(http.MethodPost, "/api/rating", RatingRequest{Token: s.ratingToken(1), Rating: 4, Problems: []int{1, 2}}, nil)
+		expectStatus(t, rec, http.StatusCreated)
+
+		var resp RatingResponse
+		decode(t, rec, &resp)
+
+		var rating Rating
+		if err := s.db.First(&rating, resp.ID).Error; err != nil {
+			t.Fatalf("Puanlama kaydedilmedi: %v", err)
+		}
+		if rating.ToiletID != 1 || rating.Rating != 4 || rating.Problems != "[1,2]" {
+			t.Fatalf("Beklenmeyen puanlama: %+v", rating)
+		}
+
+		var summary ToiletRatingSummary
+		if err := s.db.First(&summary, "toilet_id = ?", 1).Error; err != nil {
+			t.Fatalf("Özet güncellenmedi: %v", err)
+		}
+		if summary.RatingCount != 1 || summary.RatingSum != 4 || summary.LastRatingID != rating.ID {
+			t.Fatalf("Beklenmeyen özet: %+v", summary)
+		}
+	})
+
+	t.Run("personel toilet_id ile", func(t *testing.T) {
+		rec := s.request(http.MethodPost, "/api/rating", RatingRequest{ToiletID: 2, Rating: 5}, staffHeaders(staff))
+		expectStatus(t, rec, http.StatusCreated)
+	})
+
+	t.Run("girişsiz toilet_id", func(t *testing.T) {
+		rec := s.request(http.MethodPost, "/api/rating", RatingRequest{ToiletID: 2, Rating: 5}, nil)
+		expectStatus(t, rec, http.StatusUnauthorized)
+	})
+
+	t.Run("sahte personel tokenı", func(t *testing.T) {
+		headers := staffHeaders(staff)
+		headers["Authorization"] = "Bearer sahte"
+		rec := s.request(http.MethodPost, "/api/rating", RatingRequest{ToiletID: 2, Rating: 5}, headers)
+		expectStatus(t, rec, http.StatusUnauthorized)
+	})
+
+	t.Run("kod eksik", func(t *testing.T) {
+		rec := s.request(http.MethodPost, "/api/rating", RatingRequest{Rating: 3}, nil)
+		expectStatus(t, rec, http.StatusBadRequest)
+	})
+
+	t.Run("geçersiz puan", func(t *testing.T) {
+		rec := s.request(http.MethodPost, "/api/rating", RatingRequest{Token: s.ratingToken(1), Rating: 7}, nil)
+		expectStatus(t, rec, http.StatusBadRequest)
+	})
+
+	t.Run("geçersiz JSON", func(t *testing.T) {
+		rec := s.request(http.MethodPost, "/api/rating", "{", nil)
+		expectStatus(t, rec, http.StatusBadRequest)
+	})
+
+	t.Run("geçersiz token", func(t *testing.T) {
+		rec := s.request(http.MethodPost, "/api/rating", RatingRequest{Token: "1.1.sahte", Rating: 3}, nil)
+		expectStatus(t, rec, http.StatusNotFound)
+	})
+
+	t.Run("iptal edilmiş token", func(t *testing.T) {
+		token := s.ratingToken(3)
+		rec := s.request(http.MethodPost, "/api/admin/toilets/3/token/revoke", nil, s.adminHeaders())
+		expectStatus(t, rec, http.StatusOK)
+
+		var resp RatingTokenResponse
+		decode(t, rec, &resp)
+		if resp.Token != "" || resp.URL != "" || resp.RevokedAt == nil {
+			t.Fatalf("İptal yanıtı token içermemeli: %+v", resp)
+		}
+
+		rec = s.request(http.MethodPost, "/api/rating", RatingRequest{Token: token, Rating: 3}, nil)
+		expectStatus(t, rec, http.StatusGone)
+	})
+
+	t.Run("yenilenmiş token", func(t *testing.T) {
+		oldToken := s.ratingToken(4)
+		rec := s.request(http.MethodPost, "/api/admin/toilets/4/token/rotate", nil, s.adminHeaders())
+		expectStatus(t, rec, http.StatusOK)
+
+		rec = s.request(http.MethodPost, "/api/rating", RatingRequest{Token: oldToken, Rating: 3}, nil)
+		expectStatus(t, rec, http.StatusGone)
+
+		rec = s.request(http.MethodPost, "/api/rating", RatingRequest{Token: s.ratingToken(4), Rating: 3}, nil)
+		expectStatus(t, rec, http.StatusCreated)
+	})
+}
+
+func TestRatingTokenRoutesRequireAdmin(t *testing.T) {
+	s := newTestServer(t)
+	cleaner := s.createUser("temizlikci", "Temizlikçi", "temizlikci")
+	token := s.ratingToken(1)
+
+	routes := []struct{ method, path string }{
+		{http.MethodGet, "/api/admin/qr/sheet"},
+		{http.MethodGet, "/api/toilet/1/qr"},
+		{http.MethodGet, "/api/admin/toilets/1/token"},
+		{http.MethodPost, "/api/admin/toilets/1/token/rotate"},
+		{http.MethodPost, "/api/admin/toilets/1/token/revoke"},
+	}
+	for _, route := range routes {
+		t.Run(route.method+" "+route.path, func(t *testing.T) {
+			expectError(t, s, route.method, route.path, nil, nil, http.StatusUnauthorized, CodeUnauthorized)
+			expectError(t, s, route.method, route.path, nil, staffHeaders(cleaner), http.StatusForbidden, CodeForbidden)
+		})
+	}
+
+	// Reddedilen istekler tokenı değiştirmemeli
+	if s.ratingToken(1) != token {
+		t.Fatal("Yetkisiz istek tokenı değiştirdi")
+	}
+
+	rec := s.request(http.MethodGet, "/api/admin/toilets/1/token", nil, s.adminHeaders())
+	expectStatus(t, rec, http.StatusOK)
+	var resp RatingTokenResponse
+	decode(t, rec, &resp)
+	if resp.Token != token {
+		t.Fatalf("Token %q, beklenen %q", resp.Token, token)
+	}
+
+	rec = s.request(http.MethodGet, "/api/admin/qr/sheet", nil, s.adminHeaders())
+	expectStatus(t, rec, http.StatusOK)
+	if ct := rec.Header().Get("Content-Type"); ct != "application/pdf" {
+		t.Fatalf("QR sayfası Content-Type %q", ct)
+	}
+}
+
+func TestGetRatings(t *testing.T) {
+	s := newTestServer(t)
+	for i := 1; i <= 12; i++ {
+		rec := s.request(http.MethodPost, "/api/rating", RatingRequest{Token: s.ratingToken(1), Rating: i%5 + 1, Problems: []int{1}}, nil)
+		expectStatus(t, rec, http.StatusCreated)
+	}
+
+	rec := s.request(http.MethodGet, "/api/rating/1", nil, nil)
+	expectStatus(t, rec, http.StatusOK)
+
+	rec = s.request(http.MethodGet, "/api/rating/abc", nil, nil)
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	rec = s.request(http.MethodGet, "/api/rating/999", nil, nil)
+	expectStatus(t, rec, http.StatusNotFound)
+
+	rec = s.request(http.MethodGet, "/api/toilet/x/ratings", nil, nil)
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	rec = s.request(http.MethodGet, "/api/toilet/1/ratings", nil, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var list struct {
+		Count int `json:"count"`
+	}
+	decode(t, rec, &list)
+	if list.Count != 12 {
+		t.Fatalf("Puanlama sayısı %d, beklenen 12", list.Count)
+	}
+
+	rec = s.request(http.MethodGet, "/api/toilet/1/ratings/paginated?page=2&limit=5", nil, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var page PaginatedRatingsResponse
+	decode(t, rec, &page)
+	if len(page.Data) != 5 || page.TotalCount != 12 || page.TotalPages != 3 || !page.HasNext || !page.HasPrevious {
+		t.Fatalf("Beklenmeyen sayfa: %+v", page)
+	}
+	if len(page.Data[0].Problems) != 1 || page.Data[0].Problems[0] != ProblemTypes[1].Label(LangTurkish) {
+		t.Fatalf("Problem metinleri eksik: %+v", page.Data[0])
+	}
+}
+
+func TestCleaningTaskLifecycle(t *testing.T) {
+	s := newTestServer(t)
+	cleaner := s.createUser("fatma", "Fatma Kaya", "temizlikci")
+	headers := staffHeaders(cleaner)
+
+	// Görev oluşturma
+	rec := s.request(http.MethodPost, "/api/cleaning/start", CleaningTaskRequest{ToiletID: 1}, nil)
+	expectStatus(t, rec, http.StatusUnauthorized)
+
+	rec = s.request(http.MethodPost, "/api/cleaning/start", CleaningTaskRequest{ToiletID: 1}, headers)
+	expectStatus(t, rec, http.StatusCreated)
+	var created CleaningTaskResponse
+	decode(t, rec, &created)
+	task := created.Task
+	if task.Status != "assigned" || task.CleanerID != cleaner.ID || task.CleanerName != cleaner.Name {
+		t.Fatalf("Beklenmeyen görev: %+v", task)
+	}
+
+	rec = s.request(http.MethodPost, "/api/cleaning/start", CleaningTaskRequest{ToiletID: 1}, headers)
+	expectStatus(t, rec, http.StatusConflict)
+
+	rec = s.request(http.MethodPost, "/api/cleaning/start", map[string]int{"toilet_id": 0}, headers)
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	// Başlatma
+	rec = s.request(http.MethodPut, "/api/cleaning/begin/abc", nil, headers)
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	rec = s.request(http.MethodPut, "/api/cleaning/begin/999", nil, headers)
+	expectStatus(t, rec, http.StatusNotFound)
+
+	rec = s.request(http.MethodPut, fmt.Sprintf("/api/cleaning/begin/%d", task.ID), nil, headers)
+	expectStatus(t, rec, http.StatusOK)
+	var begun CleaningTaskResponse
+	decode(t, rec, &begun)
+	if begun.Task.Status != "in_progress" || begun.Task.StartedAt == nil {
+		t.Fatalf("Görev başlatılmadı: %+v", begun.Task)
+	}
+
+	// Aktif görev tuvalet durumunda görünür
+	statuses := s.toiletStatuses()
+	if statuses[1].CleaningTask == nil || statuses[1].CleaningTask.ID != task.ID {
+		t.Fatalf("Aktif görev tuvalet durumunda yok: %+v", statuses[1])
+	}
+
+	// Tamamlama
+	rec = s.request(http.MethodPut, "/api/cleaning/complete/abc", nil, headers)
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	rec = s.request(http.MethodPut, "/api/cleaning/complete/999", nil, headers)
+	expectStatus(t, rec, http.StatusNotFound)
+
+	rec = s.request(http.MethodPut, fmt.Sprintf("/api/cleaning/complete/%d", task.ID), nil, headers)
+	expectStatus(t, rec, http.StatusOK)
+	var completed CleaningTaskResponse
+	decode(t, rec, &completed)
+	if completed.Task.Status != "completed" || completed.Task.CompletedAt == nil {
+		t.Fatalf("Görev tamamlanmadı: %+v", completed.Task)
+	}
+
+	// Tamamlanan görev temiz bir puanlama oluşturur ve aktif görev kalkar
+	statuses = s.toiletStatuses()
+	if statuses[1].CleaningTask != nil {
+		t.Fatalf("Tamamlanan görev hâlâ aktif görünüyor: %+v", statuses[1].CleaningTask)
+	}
+	if statuses[1].LastRating == nil || statuses[1].LastRating.Rating != 5 || statuses[1].HasProblems {
+		t.Fatalf("Temizlik sonrası puanlama oluşmadı: %+v", statuses[1])
+	}
+
+	// Aynı tuvalete yeni görev açılabilir
+	rec = s.request(http.MethodPost, "/api/cleaning/start", CleaningTaskRequest{ToiletID: 1}, headers)
+	expectStatus(t, rec, http.StatusCreated)
+
+	// Listeleme filtreleri
+	rec = s.request(http.MethodGet, "/api/cleaning/tasks?status=completed&toilet_id=1", nil, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var list struct {
+		Data []CleaningTask `json:"data"`
+	}
+	decode(t, rec, &list)
+	if len(list.Data) != 1 || list.Data[0].ID != task.ID {
+		t.Fatalf("Beklenmeyen görev listesi: %+v", list.Data)
+	}
+
+	rec = s.request(http.MethodGet, "/api/cleaning/tasks?toilet_id=x", nil, nil)
+	expectStatus(t, rec, http.StatusBadRequest)
+}
+
+func TestAssignCleaningTask(t *testing.T) {
+	s := newTestServer(t)
+	cleaner := s.createUser("zeynep", "Zeynep Arslan", "temizlikci")
+	admin := s.createUser("mudur", "Müdür", "admin")
+	headers := staffHeaders(admin)
+
+	expectError(t, s, http.MethodPost, "/api/admin/cleaning/assign", AssignTaskRequest{ToiletID: 2, CleanerID: cleaner.ID}, nil, http.StatusUnauthorized, CodeUnauthorized)
+	expectError(t, s, http.MethodPost, "/api/admin/cleaning/assign", AssignTaskRequest{ToiletID: 2, CleanerID: cleaner.ID}, staffHeaders(cleaner), http.StatusForbidden, CodeForbidden)
+
+	rec := s.request(http.MethodPost, "/api/admin/cleaning/assign", AssignTaskRequest{ToiletID: 2, CleanerID: admin.ID}, headers)
+	expectStatus(t, rec, http.StatusNotFound)
+
+	expectError(t, s, http.MethodPost, "/api/admin/cleaning/assign", AssignTaskRequest{ToiletID: 999, CleanerID: cleaner.ID}, headers, http.StatusNotFound, CodeToiletNotFound)
+
+	rec = s.request(http.MethodPost, "/api/admin/cleaning/assign", AssignTaskRequest{ToiletID: 2, CleanerID: cleaner.ID}, headers)
+	expectStatus(t, rec, http.StatusCreated)
+	var resp CleaningTaskResponse
+	decode(t, rec, &resp)
+	if resp.Task.CleanerID != cleaner.ID || resp.Task.Status != "assigned" {
+		t.Fatalf("Beklenmeyen görev: %+v", resp.Task)
+	}
+
+	rec = s.request(http.MethodPost, "/api/admin/cleaning/assign", AssignTaskRequest{ToiletID: 2, CleanerID: cleaner.ID}, headers)
+	expectStatus(t, rec, http.StatusConflict)
+
+	rec = s.request(http.MethodPost, "/api/admin/cleaning/assign", "{}", headers)
+	expectStatus(t, rec, http.StatusBadRequest)
+}
+
+func TestConcurrentTaskCreationOpensOneActiveTask(t *testing.T) {
+	s := newTestServer(t)
+	cleaner := s.createUser("zeynep", "Zeynep Arslan", "temizlikci")
+	tasks := NewGormRepositories(s.db).Tasks
+
+	// Aynı tuvalet için eşzamanlı açılan görevlerden sadece biri kaydedilir
+	const workers = 8
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- tasks.Create(&CleaningTask{ToiletID: 1, CleanerID: cleaner.ID, CleanerName: cleaner.Name, Status: "assigned"})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	created := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			created++
+		case !errors.Is(err, ErrTaskActive):
+			t.Fatalf("Beklenmeyen hata: %v", err)
+		}
+	}
+
+	var active int64
+	s.db.Model(&CleaningTask{}).Where("toilet_id = ? AND status IN ?", 1, activeTaskStatuses).Count(&active)
+	if created != 1 || active != 1 {
+		t.Fatalf("%d görev kaydedildi, %d aktif görev var; beklenen 1", created, active)
+	}
+}
+
+func TestConcurrentUrgentRatingsAssignOneTask(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser("zeynep", "Zeynep Arslan", "temizlikci")
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.request(http.MethodPost, "/api/rating", RatingRequest{Token: s.ratingToken(1), Rating: 1, Problems: urgentProblemIDs}, nil)
+		}()
+	}
+	wg.Wait()
+	backgroundJobs.Wait()
+
+	var count int64
+	s.db.Model(&CleaningTask{}).Where("toilet_id = ?", 1).Count(&count)
+	if count != 1 {
+		t.Fatalf("Eşzamanlı acil puanlamalar %d görev açtı, beklenen 1", count)
+	}
+}
+
+func TestUrgentRatingAssignsLeastBusyCleaner(t *testing.T) {
+	s := newTestServer(t)
+	busy := s.createUser("mesgul", "Meşgul", "temizlikci")
+	free := s.createUser("bos", "Boş", "temizlikci")
+	s.insertTask(busy, 2, "in_progress", timePtr(time.Now()), nil)
+
+	rec := s.request(http.MethodPost, "/api/rating", RatingRequest{Token: s.ratingToken(1), Rating: 1, Problems: urgentProblemIDs}, nil)
+	expectStatus(t, rec, http.StatusCreated)
+
+	// Görev arka planda açılır
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		var task CleaningTask
+		err := s.db.Where("toilet_id = ?", 1).First(&task).Error
+		if err == nil {
+			if task.CleanerID != free.ID || task.Status != "assigned" {
+				t.Fatalf("Görev en az meşgul temizlikçiye atanmadı: %+v", task)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Acil sorun için görev açılmadı: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestUserCRUD(t *testing.T) {
+	s := newTestServer(t)
+	user := s.createUser("ali", "Ali Veli", "")
+	if user.Role != "temizlikci" || !user.IsActive || user.Password != hashPassword("parola-ali") {
+		t.Fatalf("Beklenmeyen kullanıcı: %+v", user)
+	}
+	other := s.createUser("veli", "Veli Can", "admin")
+
+	rec := s.request(http.MethodPost, "/api/admin/users", CreateUserRequest{Username: "ali", Password: "x", Name: "Başka Ali"}, s.adminHeaders())
+	expectStatus(t, rec, http.StatusConflict)
+
+	rec = s.request(http.MethodPost, "/api/admin/users", map[string]string{"username": "eksik"}, s.adminHeaders())
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	rec = s.request(http.MethodGet, "/api/admin/users", nil, s.adminHeaders())
+	expectStatus(t, rec, http.StatusOK)
+	var users UsersResponse
+	decode(t, rec, &users)
+	// İstekleri gönderen admin de listelenir
+	if len(users.Users) != 3 {
+		t.Fatalf("Kullanıcı sayısı %d, beklenen 3", len(users.Users))
+	}
+	for _, listed := range users.Users {
+		if listed.Password != "" {
+			t.Fatalf("Şifre hash'i yanıtta döndü: %+v", listed)
+		}
+	}
+
+	// Güncelleme
+	path := fmt.Sprintf("/api/admin/users/%d", user.ID)
+	rec = s.request(http.MethodPut, path, UpdateUserRequest{Username: other.Username}, s.adminHeaders())
+	expectStatus(t, rec, http.StatusConflict)
+
+	rec = s.request(http.MethodPut, path, UpdateUserRequest{Name: "Ali Yeni", Password: "yeni-parola"}, s.adminHeaders())
+	expectStatus(t, rec, http.StatusOK)
+	var updated UserResponse
+	decode(t, rec, &updated)
+	if updated.User.Name != "Ali Yeni" || updated.User.Username != "ali" {
+		t.Fatalf("Beklenmeyen güncelleme: %+v", updated.User)
+	}
+
+	rec = s.request(http.MethodPost, "/api/login", LoginRequest{Username: "ali", Password: "yeni-parola"}, nil)
+	expectStatus(t, rec, http.StatusOK)
+
+	rec = s.request(http.MethodPut, "/api/admin/users/abc", UpdateUserRequest{Name: "x"}, s.adminHeaders())
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	rec = s.request(http.MethodPut, "/api/admin/users/999", UpdateUserRequest{Name: "x"}, s.adminHeaders())
+	expectStatus(t, rec, http.StatusNotFound)
+
+	// Silme bildirim tercihlerini ve push aboneliklerini de kaldırır
+	if err := s.db.Create(&NotificationPreference{UserID: user.ID, Channel: ChannelEmail, Target: "ali@example.com", Events: "*", IsEnabled: true}).Error; err != nil {
+		t.Fatalf("Tercih eklenemedi: %v", err)
+	}
+	if err := s.db.Create(&PushSubscription{UserID: user.ID, Endpoint: "https://push.example.com/ali", P256dh: "p256dh", Auth: "auth"}).Error; err != nil {
+		t.Fatalf("Push aboneliği eklenemedi: %v", err)
+	}
+
+	rec = s.request(http.MethodDelete, "/api/admin/users/abc", nil, s.adminHeaders())
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	rec = s.request(http.MethodDelete, path, nil, s.adminHeaders())
+	expectStatus(t, rec, http.StatusOK)
+
+	rec = s.request(http.MethodDelete, path, nil, s.adminHeaders())
+	expectStatus(t, rec, http.StatusNotFound)
+
+	var prefCount int64
+	s.db.Model(&NotificationPreference{}).Where("user_id = ?", user.ID).Count(&prefCount)
+	if prefCount != 0 {
+		t.Fatalf("Silinen kullanıcının %d bildirim tercihi kaldı", prefCount)
+	}
+	var subscriptionCount int64
+	s.db.Model(&PushSubscription{}).Where("user_id = ?", user.ID).Count(&subscriptionCount)
+	if subscriptionCount != 0 {
+		t.Fatalf("Silinen kullanıcının %d push aboneliği kaldı", subscriptionCount)
+	}
+}
+
+func TestUserRoutesRequireAdmin(t *testing.T) {
+	s := newTestServer(t)
+	cleaner := s.createUser("temizlikci", "Temizlikçi", "temizlikci")
+	path := fmt.Sprintf("/api/admin/users/%d", cleaner.ID)
+
+	routes := []struct {
+		method, path string
+		body         interface{}
+	}{
+		{http.MethodGet, "/api/admin/users", nil},
+		{http.MethodPost, "/api/admin/users", CreateUserRequest{Username: "saldirgan", Password: "x", Name: "Saldırgan", Role: "admin"}},
+		{http.MethodPut, path, UpdateUserRequest{Role: "admin"}},
+		{http.MethodDelete, path, nil},
+	}
+	for _, route := range routes {
+		t.Run(route.method+" "+route.path, func(t *testing.T) {
+			expectError(t, s, route.method, route.path, route.body, nil, http.StatusUnauthorized, CodeUnauthorized)
+			expectError(t, s, route.method, route.path, route.body, staffHeaders(cleaner), http.StatusForbidden, CodeForbidden)
+		})
+	}
+
+	// Reddedilen istekler kullanıcı oluşturmamalı ve rol değiştirmemeli
+	var count int64
+	s.db.Model(&User{}).Where("username = ?", "saldirgan").Count(&count)
+	if count != 0 || s.loadUser(cleaner.ID).Role != "temizlikci" {
+		t.Fatal("Yetkisiz istek kullanıcıları değiştirdi")
+	}
+
+	if _, err := createAdmin(NewGormRepositories(s.db).Users, "temizlikci", "parola", "Başka"); err == nil {
+		t.Fatal("Kullanılan kullanıcı adıyla admin oluşturuldu")
+	}
+}
+
+func TestAdminStats(t *testing.T) {
+	s := newTestServer(t)
+	cleaner := s.createUser("hasan", "Hasan", "temizlikci")
+	passive := s.createUser("pasif", "Pasif", "temizlikci")
+	s.createUser("admin", "Admin", "admin")
+
+	inactive := false
+	rec := s.request(http.MethodPut, fmt.Sprintf("/api/admin/users/%d", passive.ID), UpdateUserRequest{IsActive: &inactive}, s.adminHeaders())
+	expectStatus(t, rec, http.StatusOK)
+
+	for _, r := range []RatingRequest{
+		{Token: s.ratingToken(1), Rating: 2, Problems: []int{1}},
+		{Token: s.ratingToken(2), Rating: 4},
+		{Token: s.ratingToken(2), Rating: 3, Problems: []int{3}},
+	} {
+		rec := s.request(http.MethodPost, "/api/rating", r, nil)
+		expectStatus(t, rec, http.StatusCreated)
+	}
+
+	now := time.Now()
+	s.insertTask(cleaner, 3, "completed", timePtr(now.Add(-10*time.Minute)), timePtr(now))
+	s.insertTask(cleaner, 4, "in_progress", timePtr(now), nil)
+
+	rec = s.request(http.MethodGet, "/api/admin/stats", nil, s.adminHeaders())
+	expectStatus(t, rec, http.StatusOK)
+	var resp StatsResponse
+	decode(t, rec, &resp)
+
+	want := SystemStats{
+		TotalToilets:        6,
+		ActiveToilets:       6,
+		ToiletsWithProblems: 2,
+		TotalCleaners:       2,
+		ActiveCleaners:      1,
+		TotalRatings:        3,
+		AverageRating:       3,
+		CompletedTasksToday: 1,
+		OngoingTasks:        1,
+	}
+	if *resp.SystemStats != want {
+		t.Fatalf("Sistem istatistikleri %+v, beklenen %+v", *resp.SystemStats, want)
+	}
+
+	if len(resp.CleanerStats) != 2 {
+		t.Fatalf("Temizlikçi sayısı %d, beklenen 2", len(resp.CleanerStats))
+	}
+	stats := resp.CleanerStats[0]
+	if stats.TotalCompletedTasks != 1 || stats.OngoingTasks != 1 || stats.AverageCleaningTime == nil || math.Abs(*stats.AverageCleaningTime-10) > 0.01 {
+		t.Fatalf("Beklenmeyen temizlikçi istatistikleri: %+v", stats)
+	}
+	if resp.CleanerStats[1].AverageCleaningTime != nil || resp.CleanerStats[1].IsActive {
+		t.Fatalf("Görevi olmayan pasif temizlikçi: %+v", resp.CleanerStats[1])
+	}
+
+	expectError(t, s, http.MethodGet, "/api/admin/stats", nil, nil, http.StatusUnauthorized, CodeUnauthorized)
+	expectError(t, s, http.MethodGet, "/api/admin/stats", nil, staffHeaders(cleaner), http.StatusForbidden, CodeForbidden)
+}
+
+// TestCleanerStatsMatchPerCleanerLogic gruplanmış sorguların sonucunu eski getAdminStats döngüsünün
+// temizlikçi başına ayrı sorgularla hesapladığı değerlerle karşılaştırır. Süreler tam dakika ve aykırı
+// değer yok; bu veride saniye hassasiyeti ve aykırı değer ayıklaması eski sonucu değiştirmemeli.
+func TestCleanerStatsMatchPerCleanerLogic(t *testing.T) {
+	s := newTestServer(t)
+	setFacilityTimezone(t, "Europe/Istanbul")
+
+	now := time.Now()
+	var cleaners []User
+	for i := 0; i < 5; i++ {
+		cleaners = append(cleaners, s.createUser(fmt.Sprintf("temizlikci%d", i), fmt.Sprintf("Temizlikçi %d", i), "temizlikci"))
+	}
+
+	// Farklı süre, tarih ve durumlarda görevler; süresi eksik görevler ve hiç görevi olmayan temizlikçi dahil
+	for i, cleaner := range cleaners[:4] {
+		for j := 0; j < 3+i*2; j++ {
+			completedAt := now.Add(-time.Duration(i*j*37) * time.Hour)
+			duration := time.Duration(3+(i*7+j*5)%40) * time.Minute
+			s.insertTask(cleaner, j%6+1, "completed", timePtr(completedAt.Add(-duration)), timePtr(completedAt))
+		}
+		if i%2 == 0 {
+			s.insertTask(cleaner, 1, "assigned", nil, nil)
+			s.insertTask(cleaner, 3, "in_progress", timePtr(now), nil)
+			s.insertTask(cleaner, 2, "completed", nil, timePtr(now))
+		}
+	}
+
+	got, err := computeCleanerStats(s.db, analyticsFilter{})
+	if err != nil {
+		t.Fatalf("İstatistikler hesaplanamadı: %v", err)
+	}
+	if len(got) != len(cleaners) {
+		t.Fatalf("Temizlikçi sayısı %d, beklenen %d", len(got), len(cleaners))
+	}
+
+	for i, cleaner := range cleaners {
+		want := baselineCleanerStats(t, s.db, cleaner, now)
+		compareCleanerStats(t, got[i], want)
+	}
+}
+
+// baselineCleanerStats eski getAdminStats döngüsünü tek temizlikçi için tekrarlar: her değer ayrı sorguyla,
+// son hafta/ay kayan 7/30 günle, süreler TIMESTAMPDIFF(MINUTE) gibi tam dakikaya kesilerek ve aykırı değer
+// ayıklamadan hesaplanır. Eski kodda olmayan medyan ve %90 aynı süre listesinden sıralı sıraya göre
+// doğrusal enterpolasyonla bulunur.
+func baselineCleanerStats(t *testing.T, db *gorm.DB, cleaner User, now time.Time) CleanerStats {
+	t.Helper()
+
+	stats := CleanerStats{CleanerID: cleaner.ID, CleanerName: cleaner.Name, IsActive: cleaner.IsActive}
+	completed := db.Model(&CleaningTask{}).Where("cleaner_id = ? AND status = ?", cleaner.ID, "completed")
+
+	// Tamamlanan görev sayısı
+	if err := completed.Session(&gorm.Session{}).Count(&stats.TotalCompletedTasks).Error; err != nil {
+		t.Fatalf("Görevler sayılamadı: %v", err)
+	}
+
+	// Son hafta ve son ay görev sayısı
+	completed.Session(&gorm.Session{}).Where("completed_at >= ?", now.Add(-7*24*time.Hour)).Count(&stats.LastWeekTasks)
+	completed.Session(&gorm.Session{}).Where("completed_at >= ?", now.Add(-30*24*time.Hour)).Count(&stats.LastMonthTasks)
+
+	// Devam eden görev sayısı
+	db.Model(&CleaningTask{}).
+		Where("cleaner_id = ? AND status IN (?)", cleaner.ID, []string{"assigned", "in_progress"}).
+		Count(&stats.OngoingTasks)
+
+	// TIMESTAMPDIFF(MINUTE, started_at, completed_at); SQLite'ta olmadığı için Go tarafında tam dakikaya kesilir
+	var timed []CleaningTask
+	completed.Session(&gorm.Session{}).Where("started_at IS NOT NULL AND completed_at IS NOT NULL").Find(&timed)
+
+	var minutes []float64
+	for _, task := range timed {
+		minutes = append(minutes, math.Trunc(task.CompletedAt.Sub(*task.StartedAt).Minutes()))
+	}
+	if len(minutes) == 0 {
+		return stats
+	}
+
+	sort.Float64s(minutes)
+	total := 0.0
+	for _, m := range minutes {
+		total += m
+	}
+	average := total / float64(len(minutes))
+	median := interpolatedRank(minutes, 0.5)
+	p90 := interpolatedRank(minutes, 0.9)
+
+	stats.TotalCleaningTime = total
+	stats.AverageCleaningTime = &average
+	stats.MedianCleaningTime = &median
+	stats.P90CleaningTime = &p90
+	stats.FastestCleaningTime = &minutes[0]
+	stats.SlowestCleaningTime = &minutes[len(minutes)-1]
+	return stats
+}
+
+// interpolatedRank sıralı değerlerde (n-1)*p sırasındaki değeri komşu iki değer arasında enterpolasyonla bulur
+func interpolatedRank(sorted []float64, p float64) float64 {
+	rank := p * float64(len(sorted)-1)
+	i := int(rank)
+	if i+1 >= len(sorted) {
+		return sorted[i]
+	}
+	return sorted[i] + (sorted[i+1]-sorted[i])*(rank-float64(i))
+}
+
+// compareCleanerStats iki istatistik kaydını küçük kayan nokta farklarını yok sayarak karşılaştırır
+func compareCleanerStats(t *testing.T, got, want CleanerStats) {
+	t.Helper()
+
+	if got.CleanerID != want.CleanerID || got.CleanerName != want.CleanerName || got.IsActive != want.IsActive ||
+		got.TotalCompletedTasks != want.TotalCompletedTasks || got.LastWeekTasks != want.LastWeekTasks ||
+		got.LastMonthTasks != want.LastMonthTasks || got.OngoingTasks != want.OngoingTasks ||
+		got.ExcludedOutliers != want.ExcludedOutliers || math.Abs(got.TotalCleaningTime-want.TotalCleaningTime) > 1e-6 {
+		t.Fatalf("Temizlikçi %d: %+v, beklenen %+v", want.CleanerID, got, want)
+	}
+
+	pointers := []struct {
+		name      string
+		got, want *float64
+	}{
+		{"ortalama", got.AverageCleaningTime, want.AverageCleaningTime},
+		{"medyan", got.MedianCleaningTime, want.MedianCleaningTime},
+		{"%90", got.P90CleaningTime, want.P90CleaningTime},
+		{"en hızlı", got.FastestCleaningTime, want.FastestCleaningTime},
+		{"en yavaş", got.SlowestCleaningTime, want.SlowestCleaningTime},
+	}
+	for _, p := range pointers {
+		if (p.got == nil) != (p.want == nil) || (p.got != nil && math.Abs(*p.got-*p.want) > 1e-6) {
+			t.Fatalf("Temizlikçi %d %s süresi farklı: %v, beklenen %v", want.CleanerID, p.name, p.got, p.want)
+		}
+	}
+}
+
+// toiletStatuses /api/toilets/status yanıtını tuvalet ID'sine göre döner
+func (s *testServer) toiletStatuses() map[int]ToiletStatus {
+	s.t.Helper()
+
+	rec := s.request(http.MethodGet, "/api/toilets/status", nil, nil)
+	expectStatus(s.t, rec, http.StatusOK)
+
+	var resp struct {
+		Data []ToiletStatus `json:"data"`
+	}
+	decode(s.t, rec, &resp)
+
+	statuses := make(map[int]ToiletStatus, len(resp.Data))
+	for _, status := range resp.Data {
+		statuses[status.Toilet.ID] = status
+	}
+	return statuses
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// backgroundJobs arka plan işçileri ve bildirim gönderimleri; kapanırken bitmeleri beklenir
+var backgroundJobs sync.WaitGroup
+
+// goBackground fn'i kapanışta beklenecek şekilde arka planda çalıştırır
+func goBackground(fn func()) {
+	backgroundJobs.Add(1)
+	go func() {
+		defer backgroundJobs.Done()
+		fn()
+	}()
+}
+
+// runServer sunucuyu başlatır ve SIGINT/SIGTERM gelene kadar bekler. Sinyal gelince cancelWorkers ile arka plan
+// işçilerini durdurur, yeni bağlantıları reddedip devam eden istekleri ve arka plan işlerini SHUTDOWN_TIMEOUT
+// süresince bekler, ardından veritabanı bağlantısını kapatır.
+func runServer(server *http.Server, cancelWorkers context.CancelFunc) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		cancelWorkers()
+		return err
+	case <-ctx.Done():
+	}
+	stop()
+
+	log.Println("Kapanma sinyali alındı, devam eden işlerin bitmesi bekleniyor...")
+	shuttingDown.Store(true)
+	cancelWorkers()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.Server.ShutdownTimeout.Duration)
+	defer cancel()
+
+	var errs []error
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		errs = append(errs, err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		backgroundJobs.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-shutdownCtx.Done():
+		errs = append(errs, errors.New("arka plan işleri zamanında bitmedi"))
+	}
+
+	if sqlDB, err := DB.DB(); err == nil {
+		if err := sqlDB.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := <-serverErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// sqliteTimeFormat SQLite zamanları metin olarak sakladığı için sabit genişlikte UTC formatı kullanılır;
+// böylece metin karşılaştırması kronolojik sırayla aynı sonucu verir.
+const sqliteTimeFormat = "2006-01-02T15:04:05.000000000Z07:00"
+
+// openSQLite SQLite bağlantısını zaman parametrelerini normalleştiren bağlantı havuzuyla açar
+func openSQLite(dsn string) (gorm.Dialector, error) {
+	conn, err := sql.Open(sqlite.DriverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite tek yazıcı destekler; ":memory:" veritabanı da bağlantıya özeldir
+	conn.SetMaxOpenConns(1)
+
+	return sqlite.Dialector{Conn: &sqliteConnPool{db: conn}}, nil
+}
+
+// sqliteArgs zaman parametrelerini sqliteTimeFormat'a çevirir
+func sqliteArgs(args []interface{}) []interface{} {
+	converted := make([]interface{}, len(args))
+	for i, arg := range args {
+		converted[i] = arg
+		switch v := arg.(type) {
+		case time.Time:
+			converted[i] = v.UTC().Format(sqliteTimeFormat)
+		case *time.Time:
+			if v != nil {
+				converted[i] = v.UTC().Format(sqliteTimeFormat)
+			}
+		}
+	}
+	return converted
+}
+
+// sqliteConnPool gorm.ConnPool arayüzünü *sql.DB üzerinde zaman dönüşümüyle uygular
+type sqliteConnPool struct {
+	db *sql.DB
+}
+
+func (p *sqliteConnPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return p.db.PrepareContext(ctx, query)
+}
+
+func (p *sqliteConnPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return p.db.ExecContext(ctx, query, sqliteArgs(args)...)
+}
+
+func (p *sqliteConnPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return p.db.QueryContext(ctx, query, sqliteArgs(args)...)
+}
+
+func (p *sqliteConnPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return p.db.QueryRowContext(ctx, query, sqliteArgs(args)...)
+}
+
+func (p *sqliteConnPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	tx, err := p.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteTx{tx: tx}, nil
+}
+
+func (p *sqliteConnPool) GetDBConn() (*sql.DB, error) {
+	return p.db, nil
+}
+
+// sqliteTx işlem içindeki sorgular için aynı dönüşümü uygular
+type sqliteTx struct {
+	tx *sql.Tx
+}
+
+func (t *sqliteTx) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return t.tx.PrepareContext(ctx, query)
+}
+
+func (t *sqliteTx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return t.tx.ExecContext(ctx, query, sqliteArgs(args)...)
+}
+
+func (t *sqliteTx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return t.tx.QueryContext(ctx, query, sqliteArgs(args)...)
+}
+
+func (t *sqliteTx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return t.tx.QueryRowContext(ctx, query, sqliteArgs(args)...)
+}
+
+func (t *sqliteTx) Commit() error {
+	return t.tx.Commit()
+}
+
+func (t *sqliteTx) Rollback() error {
+	return t.tx.Rollback()
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ratingProblemIDs puanlamanın JSON olarak saklanan problem ID'lerini döner
+func ratingProblemIDs(rating Rating) []int {
+	var problemIDs []int
+	json.Unmarshal([]byte(rating.Problems), &problemIDs)
+	return problemIDs
+}
+
+// average özetteki ortalama puanı döner
+func (s ToiletRatingSummary) average() float64 {
+	if s.RatingCount == 0 {
+		return 0
+	}
+	return float64(s.RatingSum) / float64(s.RatingCount)
+}
+
+// problemCountMap özetteki problem sayılarını map olarak döner
+func (s ToiletRatingSummary) problemCountMap() map[string]int64 {
+	counts := map[string]int64{}
+	if s.ProblemCounts != "" {
+		json.Unmarshal([]byte(s.ProblemCounts), &counts)
+	}
+	return counts
+}
+
+// addRating puanlamayı özete ekler
+func (s *ToiletRatingSummary) addRating(rating Rating) {
+	s.RatingCount++
+	s.RatingSum += int64(rating.Rating)
+
+	if s.LastRatedAt == nil || !rating.CreatedAt.Before(*s.LastRatedAt) {
+		createdAt := rating.CreatedAt
+		s.LastRatingID = rating.ID
+		s.LastRating = rating.Rating
+		s.LastRatedAt = &createdAt
+	}
+
+	problemIDs := ratingProblemIDs(rating)
+	if len(problemIDs) == 0 {
+		return
+	}
+
+	if s.LastProblemAt == nil || rating.CreatedAt.After(*s.LastProblemAt) {
+		createdAt := rating.CreatedAt
+		s.LastProblemAt = &createdAt
+	}
+
+	counts := s.problemCountMap()
+	for _, problemID := range problemIDs {
+		counts[strconv.Itoa(problemID)]++
+	}
+	encoded, _ := json.Marshal(counts)
+	s.ProblemCounts = string(encoded)
+}
+
+// applyRatingToSummary yeni puanlamayı tuvaletin özetine transaction içinde işler.
+// Özet satırı yoksa oluşturulur, eşzamanlı güncellemeler için satır kilitlenir.
+func applyRatingToSummary(tx *gorm.DB, rating Rating) error {
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&ToiletRatingSummary{ToiletID: rating.ToiletID, ProblemCounts: "{}"}).Error; err != nil {
+		return err
+	}
+
+	var summary ToiletRatingSummary
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&summary, "toilet_id = ?", rating.ToiletID).Error; err != nil {
+		return err
+	}
+
+	summary.addRating(rating)
+	return tx.Save(&summary).Error
+}
+
+// rebuildRatingSummaries tüm özetleri puanlama geçmişinden yeniden hesaplar; her tuvalet ayrı bir transaction'da işlenir
+func rebuildRatingSummaries(db *gorm.DB) (int, error) {
+	// Puanı olan tuvaletler ve artık puanı olmayan eski özetler
+	var toiletIDs []int
+	err := db.Raw("SELECT toilet_id FROM ratings UNION SELECT toilet_id FROM toilet_rating_summary").
+		Scan(&toiletIDs).Error
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, toiletID := range toiletIDs {
+		rebuilt, err := rebuildToiletRatingSummary(db, toiletID)
+		if err != nil {
+			return count, fmt.Errorf("tuvalet %d: %w", toiletID, err)
+		}
+		if rebuilt {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// rebuildToiletRatingSummary tek tuvaletin özetini puanlamalarından yeniden hesaplar.
+// Özet satırı puanlamalar okunmadan önce kilitlendiği için aynı anda kaydedilen bir puanlama
+// (applyRatingToSummary de aynı satırı kilitler) ya bu okumaya girer ya da yeniden hesaplanan özete sonradan eklenir.
+// Tuvaletin hiç puanı kalmamışsa özet silinir ve false döner.
+func rebuildToiletRatingSummary(db *gorm.DB, toiletID int) (bool, error) {
+	rebuilt := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&ToiletRatingSummary{ToiletID: toiletID, ProblemCounts: "{}"}).Error; err != nil {
+			return err
+		}
+
+		var locked ToiletRatingSummary
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&locked, "toilet_id = ?", toiletID).Error; err != nil {
+			return err
+		}
+
+		summary := ToiletRatingSummary{ToiletID: toiletID, ProblemCounts: "{}"}
+		var batch []Rating
+		err := tx.Where("toilet_id = ?", toiletID).Order("id ASC").FindInBatches(&batch, 1000, func(*gorm.DB, int) error {
+			for _, rating := range batch {
+				summary.addRating(rating)
+			}
+			return nil
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if summary.RatingCount == 0 {
+			return tx.Delete(&ToiletRatingSummary{}, "toilet_id = ?", toiletID).Error
+		}
+
+		rebuilt = true
+		return tx.Save(&summary).Error
+	})
+	return rebuilt, err
+}
+
+// ensureRatingSummaries özet tablosu boş ama puanlama varsa özetleri bir kez oluşturur
+func ensureRatingSummaries() {
+	var summaryCount, ratingCount int64
+	DB.Model(&ToiletRatingSummary{}).Count(&summaryCount)
+	DB.Model(&Rating{}).Count(&ratingCount)
+
+	if summaryCount > 0 || ratingCount == 0 {
+		return
+	}
+
+	start := time.Now()
+	count, err := rebuildRatingSummaries(DB)
+	if err != nil {
+		log.Printf("Puanlama özetleri oluşturulamadı: %v", err)
+		return
+	}
+	log.Printf("%d tuvalet için puanlama özeti oluşturuldu (%s)", count, time.Since(start).Round(time.Millisecond))
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+// expectedSummary tuvaletin özetini puanlamalarından doğrudan hesaplar
+func expectedSummary(t *testing.T, s *testServer, toiletID int) ToiletRatingSummary {
+	t.Helper()
+
+	var ratings []Rating
+	if err := s.db.Where("toilet_id = ?", toiletID).Order("id ASC").Find(&ratings).Error; err != nil {
+		t.Fatalf("Puanlamalar okunamadı: %v", err)
+	}
+	summary := ToiletRatingSummary{ToiletID: toiletID, ProblemCounts: "{}"}
+	for _, rating := range ratings {
+		summary.addRating(rating)
+	}
+	return summary
+}
+
+// expectSummaryMatchesRatings tuvaletin kayıtlı özetinin puanlama geçmişiyle aynı olduğunu kontrol eder
+func expectSummaryMatchesRatings(t *testing.T, s *testServer, toiletID int) {
+	t.Helper()
+
+	want := expectedSummary(t, s, toiletID)
+	var got ToiletRatingSummary
+	err := s.db.First(&got, "toilet_id = ?", toiletID).Error
+	if want.RatingCount == 0 {
+		if err == nil {
+			t.Fatalf("Puanı olmayan tuvalet %d için özet kalmamalı: %+v", toiletID, got)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("Tuvalet %d özeti bulunamadı: %v", toiletID, err)
+	}
+	if got.RatingCount != want.RatingCount || got.RatingSum != want.RatingSum || got.LastRatingID != want.LastRatingID ||
+		got.LastRating != want.LastRating || got.ProblemCounts != want.ProblemCounts {
+		t.Fatalf("Tuvalet %d özeti %+v, beklenen %+v", toiletID, got, want)
+	}
+}
+
+func TestCreateRatingUpdatesSummary(t *testing.T) {
+	s := newTestServer(t)
+
+	for _, r := range []RatingRequest{
+		{Token: s.ratingToken(1), Rating: 2, Problems: []int{1, 3}},
+		{Token: s.ratingToken(1), Rating: 5},
+		{Token: s.ratingToken(1), Rating: 1, Problems: []int{3}},
+		{Token: s.ratingToken(2), Rating: 4, Problems: []int{2}},
+	} {
+		expectStatus(t, s.request(http.MethodPost, "/api/rating", r, nil), http.StatusCreated)
+	}
+
+	var summary ToiletRatingSummary
+	if err := s.db.First(&summary, "toilet_id = ?", 1).Error; err != nil {
+		t.Fatalf("Özet bulunamadı: %v", err)
+	}
+	counts := summary.problemCountMap()
+	if summary.RatingCount != 3 || summary.RatingSum != 8 || summary.LastRating != 1 || counts["1"] != 1 || counts["3"] != 2 {
+		t.Fatalf("Beklenmeyen özet: %+v", summary)
+	}
+	for _, id := range []int{1, 2, 3} {
+		expectSummaryMatchesRatings(t, s, id)
+	}
+}
+
+func TestCreateRatingRollsBackWhenSummaryFails(t *testing.T) {
+	s := newTestServer(t)
+
+	// Özet güncellenemezse puanlama da kaydedilmemeli
+	if err := s.db.Exec("DROP TABLE toilet_rating_summary").Error; err != nil {
+		t.Fatalf("Tablo silinemedi: %v", err)
+	}
+	expectError(t, s, http.MethodPost, "/api/rating", RatingRequest{Token: s.ratingToken(1), Rating: 3}, nil, http.StatusInternalServerError, CodeInternal)
+
+	var count int64
+	s.db.Model(&Rating{}).Count(&count)
+	if count != 0 {
+		t.Fatalf("Transaction geri alınmalıydı, %d puanlama kaydedildi", count)
+	}
+}
+
+func TestRebuildRatingSummaries(t *testing.T) {
+	s := newTestServer(t)
+
+	for i := 0; i < 12; i++ {
+		r := RatingRequest{Token: s.ratingToken(i%3 + 1), Rating: i%5 + 1}
+		if i%4 == 0 {
+			r.Problems = []int{i%6 + 1}
+		}
+		expectStatus(t, s.request(http.MethodPost, "/api/rating", r, nil), http.StatusCreated)
+	}
+
+	// Bozuk özetler: yanlış değerler, eksik satır ve puanı olmayan tuvalet için kalmış satır
+	s.db.Model(&ToiletRatingSummary{}).Where("toilet_id = ?", 1).Updates(map[string]interface{}{"rating_count": 99, "problem_counts": "{}"})
+	s.db.Delete(&ToiletRatingSummary{}, "toilet_id = ?", 2)
+	s.db.Create(&ToiletRatingSummary{ToiletID: 5, RatingCount: 7, RatingSum: 20, ProblemCounts: "{}"})
+
+	count, err := rebuildRatingSummaries(s.db)
+	if err != nil {
+		t.Fatalf("Özetler yeniden oluşturulamadı: %v", err)
+	}
+	if count != 3 {
+		t.Fatalf("%d özet oluşturuldu, beklenen 3", count)
+	}
+	for id := 1; id <= 6; id++ {
+		expectSummaryMatchesRatings(t, s, id)
+	}
+}
+
+func TestRebuildRatingSummariesWithConcurrentRatings(t *testing.T) {
+	s := newTestServer(t)
+	tokens := map[int]string{}
+	for id := 1; id <= 3; id++ {
+		tokens[id] = s.ratingToken(id)
+		expectStatus(t, s.request(http.MethodPost, "/api/rating", RatingRequest{Token: tokens[id], Rating: 3}, nil), http.StatusCreated)
+	}
+
+	// Yeniden oluşturma sürerken gelen puanlamalar kaybolmamalı veya iki kez sayılmamalı
+	var wg sync.WaitGroup
+	errs := make(chan error, 64)
+	for i := 0; i < 60; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec := serve(t, s.router, http.MethodPost, "/api/rating", RatingRequest{Token: tokens[i%3+1], Rating: i%5 + 1, Problems: []int{i%6 + 1}}, nil)
+			if rec.Code != http.StatusCreated {
+				errs <- fmt.Errorf("puanlama kaydedilemedi: %d %s", rec.Code, rec.Body.String())
+			}
+		}()
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := rebuildRatingSummaries(s.db); err != nil {
+			errs <- err
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	for id := 1; id <= 3; id++ {
+		expectSummaryMatchesRatings(t, s, id)
+	}
+}
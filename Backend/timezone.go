@@ -0,0 +1,48 @@
+package main
+
+import (
+	"log"
+	"time"
+	_ "time/tzdata" // Sistemde zoneinfo olmayan ortamlar için gömülü saat dilimi verisi
+)
+
+// facilityLocation binanın bulunduğu saat dilimi; "bugün", hafta/ay sınırları ve analiz aralıkları buna göre hesaplanır.
+// Veritabanı bağlantısı (loc=Local) zamanları mutlak olarak okuduğu için sadece gösterim ve gruplama etkilenir.
+var facilityLocation = time.Local
+
+// InitFacilityTimezone yapılandırmadaki FACILITY_TIMEZONE (ör. Europe/Istanbul) saat dilimini yükler, boşsa sunucunun saat dilimi kullanılır
+func InitFacilityTimezone() {
+	name := config.FacilityTimezone
+	if name == "" {
+		return
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		log.Fatalf("Geçersiz FACILITY_TIMEZONE (%s): %v", name, err)
+	}
+	facilityLocation = loc
+}
+
+// facilityNow binanın saat dilimindeki şu anki zamanı döner
+func facilityNow() time.Time {
+	return time.Now().In(facilityLocation)
+}
+
+// startOfDay t'nin bina saatine göre gün başlangıcını döner
+func startOfDay(t time.Time) time.Time {
+	t = t.In(facilityLocation)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, facilityLocation)
+}
+
+// startOfWeek t'nin bina saatine göre hafta başlangıcını (pazartesi 00:00) döner
+func startOfWeek(t time.Time) time.Time {
+	day := startOfDay(t)
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+// startOfMonth t'nin bina saatine göre ay başlangıcını döner
+func startOfMonth(t time.Time) time.Time {
+	t = t.In(facilityLocation)
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, facilityLocation)
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestStartOfDayAroundMidnight(t *testing.T) {
+	loc := setFacilityTimezone(t, "Europe/Istanbul")
+
+	tests := []struct {
+		name string
+		in   time.Time
+		want time.Time
+	}{
+		{"gece yarısından hemen önce", time.Date(2026, 3, 10, 23, 59, 59, 0, loc), time.Date(2026, 3, 10, 0, 0, 0, 0, loc)},
+		{"tam gece yarısı", time.Date(2026, 3, 11, 0, 0, 0, 0, loc), time.Date(2026, 3, 11, 0, 0, 0, 0, loc)},
+		// UTC'de hâlâ önceki gün, binada ertesi gün
+		{"UTC'de önceki gün", time.Date(2026, 3, 10, 21, 30, 0, 0, time.UTC), time.Date(2026, 3, 11, 0, 0, 0, 0, loc)},
+		{"UTC gece yarısı", time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 11, 0, 0, 0, 0, loc)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := startOfDay(tt.in); !got.Equal(tt.want) {
+				t.Fatalf("startOfDay(%s) = %s, beklenen %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartOfWeekAndMonth(t *testing.T) {
+	loc := setFacilityTimezone(t, "Europe/Istanbul")
+
+	// 2026-03-15 pazar, 2026-03-16 pazartesi
+	sunday := time.Date(2026, 3, 15, 23, 59, 59, 0, loc)
+	monday := time.Date(2026, 3, 16, 0, 0, 0, 0, loc)
+
+	if got, want := startOfWeek(sunday), time.Date(2026, 3, 9, 0, 0, 0, 0, loc); !got.Equal(want) {
+		t.Fatalf("Pazar için hafta başı %s, beklenen %s", got, want)
+	}
+	if got := startOfWeek(monday); !got.Equal(monday) {
+		t.Fatalf("Pazartesi için hafta başı %s, beklenen %s", got, monday)
+	}
+
+	lastSecond := time.Date(2026, 3, 31, 23, 59, 59, 0, loc)
+	if got, want := startOfMonth(lastSecond), time.Date(2026, 3, 1, 0, 0, 0, 0, loc); !got.Equal(want) {
+		t.Fatalf("Ay başı %s, beklenen %s", got, want)
+	}
+	// UTC'de mart, binada nisan
+	if got, want := startOfMonth(time.Date(2026, 3, 31, 22, 0, 0, 0, time.UTC)), time.Date(2026, 4, 1, 0, 0, 0, 0, loc); !got.Equal(want) {
+		t.Fatalf("UTC'ye göre önceki ayda kalan zaman için ay başı %s, beklenen %s", got, want)
+	}
+}
+
+func TestDayBoundariesAcrossDST(t *testing.T) {
+	loc := setFacilityTimezone(t, "Europe/Berlin")
+
+	tests := []struct {
+		name  string
+		day   time.Time
+		hours int
+	}{
+		{"yaz saatine geçiş", time.Date(2026, 3, 29, 12, 0, 0, 0, loc), 23},
+		{"kış saatine dönüş", time.Date(2026, 10, 25, 12, 0, 0, 0, loc), 25},
+		{"normal gün", time.Date(2026, 10, 26, 12, 0, 0, 0, loc), 24},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := startOfDay(tt.day)
+			if start.Hour() != 0 || start.Day() != tt.day.Day() {
+				t.Fatalf("Gün başı %s", start)
+			}
+
+			end := nextBucket(start, "day")
+			if got := end.Sub(start); got != time.Duration(tt.hours)*time.Hour {
+				t.Fatalf("Gün uzunluğu %s, beklenen %d saat", got, tt.hours)
+			}
+
+			buckets := 0
+			for b := truncateToBucket(start, "hour"); b.Before(end); b = nextBucket(b, "hour") {
+				buckets++
+			}
+			if buckets != tt.hours {
+				t.Fatalf("Saatlik aralık sayısı %d, beklenen %d", buckets, tt.hours)
+			}
+		})
+	}
+
+	// Kış saatine dönüşte 02:30 iki kez yaşanır; iki ayrı saatlik aralığa düşmeli
+	first := time.Date(2026, 10, 25, 0, 30, 0, 0, time.UTC)  // 02:30 CEST
+	second := time.Date(2026, 10, 25, 1, 30, 0, 0, time.UTC) // 02:30 CET
+	if a, b := truncateToBucket(first, "hour"), truncateToBucket(second, "hour"); a.Equal(b) || a.In(loc).Hour() != 2 || b.In(loc).Hour() != 2 {
+		t.Fatalf("Tekrar eden saat aralıkları hatalı: %s, %s", a, b)
+	}
+
+	// Geçiş haftasında hafta başı pazartesi 00:00'da kalır
+	if got, want := startOfWeek(time.Date(2026, 3, 30, 9, 0, 0, 0, loc)), time.Date(2026, 3, 30, 0, 0, 0, 0, loc); !got.Equal(want) {
+		t.Fatalf("Hafta başı %s, beklenen %s", got, want)
+	}
+	if got, want := startOfWeek(time.Date(2026, 3, 29, 23, 0, 0, 0, loc)), time.Date(2026, 3, 23, 0, 0, 0, 0, loc); !got.Equal(want) {
+		t.Fatalf("Hafta başı %s, beklenen %s", got, want)
+	}
+}
+
+func TestCompletedTasksTodayUsesFacilityMidnight(t *testing.T) {
+	s := newTestServer(t)
+	setFacilityTimezone(t, "Pacific/Kiritimati") // UTC+14, UTC günüyle neredeyse hiç örtüşmez
+	cleaner := s.createUser("gece", "Gece", "temizlikci")
+
+	today := startOfDay(time.Now())
+	s.insertTask(cleaner, 1, "completed", timePtr(today.Add(-20*time.Minute)), timePtr(today.Add(-time.Second)))
+	s.insertTask(cleaner, 2, "completed", timePtr(today.Add(-10*time.Minute)), timePtr(today))
+
+	stats, err := computeSystemStats(s.db)
+	if err != nil {
+		t.Fatalf("Sistem istatistikleri hesaplanamadı: %v", err)
+	}
+	if stats.CompletedTasksToday != 1 {
+		t.Fatalf("Bugün tamamlanan görev %d, beklenen 1", stats.CompletedTasksToday)
+	}
+}
+
+func TestAnalyticsBucketsFollowFacilityTimezone(t *testing.T) {
+	s := newTestServer(t)
+	loc := setFacilityTimezone(t, "Europe/Berlin")
+
+	ratings := []Rating{
+		// Kış saatine dönüş günü, yerel saatle gün sonuna yakın
+		{ToiletID: 1, Rating: 2, Problems: "[1]", CreatedAt: time.Date(2026, 10, 25, 23, 30, 0, 0, loc)},
+		// UTC'de 25 Ekim, binada 26 Ekim
+		{ToiletID: 1, Rating: 4, Problems: "[]", CreatedAt: time.Date(2026, 10, 25, 23, 30, 0, 0, time.UTC)},
+		{ToiletID: 2, Rating: 5, Problems: "[]", CreatedAt: time.Date(2026, 10, 25, 0, 15, 0, 0, loc)},
+	}
+	for i := range ratings {
+		if err := s.db.Create(&ratings[i]).Error; err != nil {
+			t.Fatalf("Puanlama eklenemedi: %v", err)
+		}
+	}
+
+	rec := s.request(http.MethodGet, "/api/admin/analytics?bucket=day&from=2026-10-25&to=2026-10-26", nil, s.adminHeaders())
+	expectStatus(t, rec, http.StatusOK)
+	var daily AnalyticsResponse
+	decode(t, rec, &daily)
+
+	if len(daily.Series) != 2 {
+		t.Fatalf("Günlük aralık sayısı %d, beklenen 2", len(daily.Series))
+	}
+	for i, want := range []time.Time{time.Date(2026, 10, 25, 0, 0, 0, 0, loc), time.Date(2026, 10, 26, 0, 0, 0, 0, loc)} {
+		if !daily.Series[i].Start.Equal(want) {
+			t.Fatalf("%d. aralık başı %s, beklenen %s", i, daily.Series[i].Start, want)
+		}
+	}
+	if daily.Series[0].RatingCount != 2 || daily.Series[0].ProblemCount != 1 || daily.Series[1].RatingCount != 1 {
+		t.Fatalf("Puanlamalar yanlış günlere dağıtıldı: %+v", daily.Series)
+	}
+
+	rec = s.request(http.MethodGet, "/api/admin/analytics?bucket=hour&from=2026-10-25&to=2026-10-25", nil, s.adminHeaders())
+	expectStatus(t, rec, http.StatusOK)
+	var hourly AnalyticsResponse
+	decode(t, rec, &hourly)
+
+	if len(hourly.Series) != 25 {
+		t.Fatalf("Saatlik aralık sayısı %d, beklenen 25", len(hourly.Series))
+	}
+	if hourly.Series[0].RatingCount != 1 || hourly.Series[24].RatingCount != 1 {
+		t.Fatalf("İlk ve son saatlik aralıkta birer puanlama bekleniyordu: %+v, %+v", hourly.Series[0], hourly.Series[24])
+	}
+}
+
+func TestCleanerStatsUseRollingWindows(t *testing.T) {
+	s := newTestServer(t)
+	setFacilityTimezone(t, "Europe/Berlin")
+	cleaner := s.createUser("pencere", "Pencere", "temizlikci")
+
+	// Takvim haftası/ayı başlangıcından bağımsız olarak son 7 ve 30 gün sayılır
+	now := time.Now()
+	for _, ago := range []time.Duration{
+		time.Hour,
+		7*24*time.Hour - time.Minute, // son hafta içinde
+		7*24*time.Hour + time.Minute, // son hafta dışında, son ay içinde
+		30*24*time.Hour - time.Minute,
+		30*24*time.Hour + time.Minute, // son ay dışında
+	} {
+		completedAt := now.Add(-ago)
+		s.insertTask(cleaner, 1, "completed", timePtr(completedAt.Add(-10*time.Minute)), timePtr(completedAt))
+	}
+
+	stats, err := computeCleanerStats(s.db, analyticsFilter{})
+	if err != nil {
+		t.Fatalf("Temizlikçi istatistikleri hesaplanamadı: %v", err)
+	}
+	if got := stats[0]; got.TotalCompletedTasks != 5 || got.LastWeekTasks != 2 || got.LastMonthTasks != 4 {
+		t.Fatalf("Beklenmeyen pencereler: toplam %d, son hafta %d, son ay %d", got.TotalCompletedTasks, got.LastWeekTasks, got.LastMonthTasks)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// countQueries veritabanına giden sorguları sayan bir sayaç kaydeder
+func countQueries(t *testing.T, db *gorm.DB) *int64 {
+	t.Helper()
+
+	var count int64
+	increment := func(*gorm.DB) { atomic.AddInt64(&count, 1) }
+	if err := db.Callback().Query().After("gorm:query").Register("test:count_query", increment); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Callback().Raw().After("gorm:raw").Register("test:count_raw", increment); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Callback().Row().After("gorm:row").Register("test:count_row", increment); err != nil {
+		t.Fatal(err)
+	}
+	return &count
+}
+
+// TestToiletStatusQueryCountIsConstant tuvalet sayısı arttığında durum sorgularının sayısının değişmediğini doğrular
+func TestToiletStatusQueryCountIsConstant(t *testing.T) {
+	s := newTestServer(t)
+	cleaner := s.createUser("sayac", "Sayaç", "temizlikci")
+	queries := countQueries(t, s.db)
+
+	seed := func(fromID, toID int) {
+		for id := fromID; id <= toID; id++ {
+			if id > 6 {
+				toilet := Toilet{ID: id, Name: fmt.Sprintf("Tuvalet %d", id), Location: fmt.Sprintf("%d. Kat", id/10), IsActive: true, TokenVersion: 1}
+				if err := s.db.Create(&toilet).Error; err != nil {
+					t.Fatalf("Tuvalet eklenemedi: %v", err)
+				}
+			}
+			rec := s.request(http.MethodPost, "/api/rating", RatingRequest{Token: s.ratingToken(id), Rating: id%5 + 1, Problems: []int{id%6 + 1}}, nil)
+			expectStatus(t, rec, http.StatusCreated)
+			if id%2 == 0 {
+				s.insertTask(cleaner, id, "assigned", nil, nil)
+			}
+		}
+	}
+
+	measure := func() (int64, map[int]ToiletStatus) {
+		atomic.StoreInt64(queries, 0)
+		statuses := s.toiletStatuses()
+		return atomic.LoadInt64(queries), statuses
+	}
+
+	seed(1, 6)
+	small, statuses := measure()
+	if len(statuses) != 6 {
+		t.Fatalf("Tuvalet sayısı %d, beklenen 6", len(statuses))
+	}
+
+	seed(7, 120)
+	large, statuses := measure()
+	if len(statuses) != 120 {
+		t.Fatalf("Tuvalet sayısı %d, beklenen 120", len(statuses))
+	}
+
+	if small != large {
+		t.Fatalf("Sorgu sayısı tuvalet sayısıyla değişiyor: 6 tuvalet için %d, 120 tuvalet için %d", small, large)
+	}
+
+	status := statuses[42]
+	if status.TotalRatings != 1 || status.LastRating == nil || !status.HasProblems || status.CleaningTask == nil {
+		t.Fatalf("Beklenmeyen tuvalet durumu: %+v", status)
+	}
+}
+
+// BenchmarkToiletStatus 400 tuvaletlik bir bina için durum hesaplamasını ölçer
+func BenchmarkToiletStatus(b *testing.B) {
+	s := newTestServer(b)
+
+	var toilets []Toilet
+	for id := 7; id <= 400; id++ {
+		toilets = append(toilets, Toilet{ID: id, Name: fmt.Sprintf("Tuvalet %d", id), Location: "Kat", IsActive: true, TokenVersion: 1})
+	}
+	if err := s.db.CreateInBatches(&toilets, 100).Error; err != nil {
+		b.Fatal(err)
+	}
+
+	h := NewHandlers(NewGormRepositories(s.db), nopEventNotifier{})
+	active, err := h.Toilets.ListActive()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := h.buildToiletStatuses(active); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,615 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Webhook olay isimleri
+const (
+	EventRatingCreated = "rating.created"
+	EventTaskAssigned  = "task.assigned"
+	EventTaskStarted   = "task.started"
+	EventTaskCompleted = "task.completed"
+	EventWebhookTest   = "webhook.test"
+)
+
+// WebhookEvents abone olunabilecek olaylar
+var WebhookEvents = []string{
+	EventRatingCreated,
+	EventTaskAssigned,
+	EventTaskStarted,
+	EventTaskCompleted,
+}
+
+const (
+	webhookPollInterval   = 5 * time.Second
+	webhookBatchSize      = 50
+	webhookMaxAttempts    = 8
+	webhookBaseBackoff    = 30 * time.Second
+	webhookMaxBackoff     = time.Hour
+	webhookRequestTimeout = 10 * time.Second
+)
+
+// webhookClient bağlanılan her adresi webhookDialControl ile kontrol eder; yönlendirmeler de aynı kontrolden geçer
+var webhookClient = &http.Client{
+	Timeout: webhookRequestTimeout,
+	Transport: &http.Transport{
+		DialContext:         (&net.Dialer{Timeout: 5 * time.Second, Control: webhookDialControl}).DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+		MaxIdleConns:        10,
+		IdleConnTimeout:     time.Minute,
+	},
+}
+
+// errWebhookTargetBlocked webhook adresi yerel veya özel bir ağ adresine çözüldüğünde döner
+var errWebhookTargetBlocked = errors.New("webhook adresi yerel veya özel bir ağ adresine çözülüyor")
+
+// publicIP adresin internetten erişilebilen bir adres olup olmadığını döner;
+// loopback, özel ağ, link-local (bulut metadata servisleri dahil) ve multicast adresler reddedilir
+func publicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast())
+}
+
+// webhookHostAllowed abonelik kaydedilirken URL'deki host'u kontrol eder.
+// Alan adları burada çözülmez; çözülen adres her bağlantıda webhookDialControl ile kontrol edilir.
+func webhookHostAllowed(host string) bool {
+	if config.Webhooks.AllowPrivateTargets {
+		return true
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return publicIP(ip)
+	}
+	return true
+}
+
+// webhookDialControl DNS çözümlemesinden sonra bağlanılacak adresi kontrol eder;
+// böylece herkese açık görünen bir alan adı iç ağdaki bir adrese çözülse bile istek gönderilmez
+func webhookDialControl(network, address string, _ syscall.RawConn) error {
+	if config.Webhooks.AllowPrivateTargets {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+		return errWebhookTargetBlocked
+	}
+	return nil
+}
+
+// subscribes aboneliğin verilen olayı dinleyip dinlemediğini kontrol eder
+func (s WebhookSubscription) subscribes(event string) bool {
+	if event == EventWebhookTest {
+		return true
+	}
+	for _, e := range strings.Split(s.Events, ",") {
+		e = strings.TrimSpace(e)
+		if e == "*" || e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// signWebhookPayload zaman damgası ve gövdeyi HMAC-SHA256 ile imzalar.
+// Alıcı, "X-Webhook-Timestamp" + "." + gövde üzerinden aynı imzayı hesaplayarak doğrular.
+func signWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// generateWebhookSecret rastgele bir imza anahtarı üretir
+func generateWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// webhookBackoff deneme sayısına göre bir sonraki denemeye kadar beklenecek süreyi hesaplar
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookBaseBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= webhookMaxBackoff {
+			return webhookMaxBackoff
+		}
+	}
+	return backoff
+}
+
+// enqueueWebhookEvent olayı dinleyen tüm aktif aboneliklere gönderim kaydı ekler.
+// Olayı oluşturan değişiklikle aynı transaction içinde çağrılmalı; hata dönerse transaction geri alınır,
+// böylece kaydedilen her değişikliğin gönderimi de kuyruğa girmiş olur.
+func enqueueWebhookEvent(db *gorm.DB, event string, data interface{}) error {
+	var subscriptions []WebhookSubscription
+	if err := db.Where("is_active = ?", true).Find(&subscriptions).Error; err != nil {
+		return fmt.Errorf("webhook abonelikleri getirilemedi (%s): %w", event, err)
+	}
+
+	var body []byte
+	now := time.Now()
+	for _, sub := range subscriptions {
+		if !sub.subscribes(event) {
+			continue
+		}
+
+		if body == nil {
+			var err error
+			body, err = json.Marshal(WebhookPayload{Event: event, OccurredAt: now, Data: data})
+			if err != nil {
+				return fmt.Errorf("webhook verisi işlenemedi (%s): %w", event, err)
+			}
+		}
+
+		delivery := WebhookDelivery{
+			SubscriptionID: sub.ID,
+			Event:          event,
+			Payload:        string(body),
+			Status:         "pending",
+			NextAttemptAt:  now,
+		}
+		if err := db.Create(&delivery).Error; err != nil {
+			return fmt.Errorf("webhook gönderimi kuyruğa eklenemedi (abonelik %d): %w", sub.ID, err)
+		}
+	}
+	return nil
+}
+
+// startWebhookWorker bekleyen webhook gönderimlerini periyodik olarak işleyen arka plan işçisini başlatır;
+// ctx iptal edilince elindeki gönderimi bitirip durur
+func startWebhookWorker(ctx context.Context) {
+	goBackground(func() {
+		ticker := time.NewTicker(webhookPollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				processPendingWebhooks(ctx)
+			}
+		}
+	})
+}
+
+// processPendingWebhooks zamanı gelmiş gönderimleri sırayla dener
+func processPendingWebhooks(ctx context.Context) {
+	var deliveries []WebhookDelivery
+	if err := DB.Where("status = ? AND next_attempt_at <= ?", "pending", time.Now()).
+		Order("next_attempt_at ASC").
+		Limit(webhookBatchSize).
+		Find(&deliveries).Error; err != nil {
+		log.Printf("Bekleyen webhook gönderimleri getirilemedi: %v", err)
+		return
+	}
+
+	for i := range deliveries {
+		// Kalan gönderimler bir sonraki açılışta denenir
+		if ctx.Err() != nil {
+			return
+		}
+		deliverWebhook(&deliveries[i])
+	}
+}
+
+// deliverWebhook tek bir gönderimi dener ve sonucu kaydeder
+func deliverWebhook(delivery *WebhookDelivery) {
+	var sub WebhookSubscription
+	if err := DB.First(&sub, delivery.SubscriptionID).Error; err != nil {
+		delivery.Status = "failed"
+		delivery.LastError = "Webhook aboneliği bulunamadı"
+		DB.Save(delivery)
+		return
+	}
+
+	delivery.Attempts++
+	statusCode, err := sendWebhookRequest(sub, delivery)
+	delivery.ResponseStatus = statusCode
+
+	if err == nil {
+		now := time.Now()
+		delivery.Status = "delivered"
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+	} else {
+		delivery.LastError = err.Error()
+		if delivery.Attempts >= webhookMaxAttempts {
+			delivery.Status = "failed"
+		} else {
+			delivery.NextAttemptAt = time.Now().Add(webhookBackoff(delivery.Attempts))
+		}
+	}
+
+	if err := DB.Save(delivery).Error; err != nil {
+		log.Printf("Webhook gönderim sonucu kaydedilemedi (%d): %v", delivery.ID, err)
+	}
+}
+
+// sendWebhookRequest imzalı HTTP isteğini gönderir; 2xx dışındaki yanıtlar hata sayılır
+func sendWebhookRequest(sub WebhookSubscription, delivery *WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequest(http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Temizlik-Takip-Webhook/1.0")
+	req.Header.Set("X-Webhook-Event", delivery.Event)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", signWebhookPayload(sub.Secret, timestamp, body))
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("beklenmeyen yanıt kodu: %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// validateWebhookRequest abonelik isteğindeki URL ve olay listesini doğrular, hatalı alanları döner
+func validateWebhookRequest(req WebhookSubscriptionRequest, requireURL bool) []FieldError {
+	var errs []FieldError
+
+	if req.URL == "" {
+		if requireURL {
+			errs = append(errs, fieldError("url", RuleRequired, ""))
+		}
+	} else {
+		parsed, err := url.Parse(req.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" || parsed.User != nil {
+			errs = append(errs, fieldError("url", RuleURL, ""))
+		} else if !webhookHostAllowed(parsed.Hostname()) {
+			errs = append(errs, fieldError("url", RulePublicHost, ""))
+		}
+	}
+
+	for i, event := range req.Events {
+		if event == "*" {
+			continue
+		}
+		known := false
+		for _, e := range WebhookEvents {
+			if e == event {
+				known = true
+				break
+			}
+		}
+		if !known {
+			errs = append(errs, fieldError(fmt.Sprintf("events[%d]", i), RuleUnknownValue, event))
+		}
+	}
+
+	return errs
+}
+
+// joinWebhookEvents olay listesini veritabanı formatına çevirir
+func joinWebhookEvents(events []string) string {
+	if len(events) == 0 {
+		return "*"
+	}
+	return strings.Join(events, ",")
+}
+
+// getWebhooks tüm webhook aboneliklerini getirir (sadece admin erişimi)
+func getWebhooks(c *gin.Context) {
+	var subscriptions []WebhookSubscription
+	if err := DB.Order("id ASC").Find(&subscriptions).Error; err != nil {
+		respondError(c, internalError(err, "Webhook abonelikleri getirilirken hata oluştu"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    subscriptions,
+		"events":  WebhookEvents,
+	})
+}
+
+// createWebhook yeni webhook aboneliği oluşturur (sadece admin erişimi)
+func createWebhook(c *gin.Context) {
+	var req WebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, bindError(err))
+		return
+	}
+
+	if errs := validateWebhookRequest(req, true); len(errs) > 0 {
+		respondError(c, validationError(errs...))
+		return
+	}
+
+	// Secret verilmemişse rastgele üret
+	secret := req.Secret
+	if secret == "" {
+		generated, err := generateWebhookSecret()
+		if err != nil {
+			respondError(c, internalError(err, "İmza anahtarı oluşturulamadı"))
+			return
+		}
+		secret = generated
+	}
+
+	sub := WebhookSubscription{
+		URL:         req.URL,
+		Secret:      secret,
+		Events:      joinWebhookEvents(req.Events),
+		Description: req.Description,
+		IsActive:    true,
+	}
+	if req.IsActive != nil {
+		sub.IsActive = *req.IsActive
+	}
+
+	if err := DB.Create(&sub).Error; err != nil {
+		respondError(c, internalError(err, "Webhook aboneliği oluşturulurken hata oluştu"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, WebhookSubscriptionResponse{
+		Success:      true,
+		Message:      messageText(c, MsgWebhookCreated),
+		Subscription: &sub,
+		Secret:       secret,
+	})
+}
+
+// updateWebhook webhook aboneliğini günceller (sadece admin erişimi)
+func updateWebhook(c *gin.Context) {
+	idStr := c.Param("id")
+	subID, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		respondError(c, invalidParam("id"))
+		return
+	}
+
+	var req WebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, bindError(err))
+		return
+	}
+
+	if errs := validateWebhookRequest(req, false); len(errs) > 0 {
+		respondError(c, validationError(errs...))
+		return
+	}
+
+	var sub WebhookSubscription
+	if err := DB.First(&sub, uint(subID)).Error; err != nil {
+		respondError(c, lookupError(err, CodeWebhookNotFound))
+		return
+	}
+
+	if req.URL != "" {
+		sub.URL = req.URL
+	}
+	if req.Secret != "" {
+		sub.Secret = req.Secret
+	}
+	if req.Events != nil {
+		sub.Events = joinWebhookEvents(req.Events)
+	}
+	if req.Description != "" {
+		sub.Description = req.Description
+	}
+	if req.IsActive != nil {
+		sub.IsActive = *req.IsActive
+	}
+
+	if err := DB.Save(&sub).Error; err != nil {
+		respondError(c, internalError(err, "Webhook aboneliği güncellenirken hata oluştu"))
+		return
+	}
+
+	c.JSON(http.StatusOK, WebhookSubscriptionResponse{
+		Success:      true,
+		Message:      messageText(c, MsgWebhookUpdated),
+		Subscription: &sub,
+	})
+}
+
+// deleteWebhook webhook aboneliğini ve bekleyen gönderimlerini siler (sadece admin erişimi)
+func deleteWebhook(c *gin.Context) {
+	idStr := c.Param("id")
+	subID, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		respondError(c, invalidParam("id"))
+		return
+	}
+
+	var sub WebhookSubscription
+	if err := DB.First(&sub, uint(subID)).Error; err != nil {
+		respondError(c, lookupError(err, CodeWebhookNotFound))
+		return
+	}
+
+	err = DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("subscription_id = ? AND status = ?", sub.ID, "pending").Delete(&WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&sub).Error
+	})
+	if err != nil {
+		respondError(c, internalError(err, "Webhook aboneliği silinirken hata oluştu"))
+		return
+	}
+
+	c.JSON(http.StatusOK, WebhookSubscriptionResponse{
+		Success: true,
+		Message: messageText(c, MsgWebhookDeleted),
+	})
+}
+
+// getWebhookDeliveries bir aboneliğin gönderim kayıtlarını sayfalı olarak getirir (sadece admin erişimi)
+func getWebhookDeliveries(c *gin.Context) {
+	idStr := c.Param("id")
+	subID, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		respondError(c, invalidParam("id"))
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	query := DB.Model(&WebhookDelivery{}).Where("subscription_id = ?", subID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var totalCount int64
+	query.Count(&totalCount)
+
+	var deliveries []WebhookDelivery
+	if err := query.Order("created_at DESC").
+		Limit(limit).
+		Offset((page - 1) * limit).
+		Find(&deliveries).Error; err != nil {
+		respondError(c, internalError(err, "Webhook gönderimleri getirilirken hata oluştu"))
+		return
+	}
+
+	c.JSON(http.StatusOK, WebhookDeliveriesResponse{
+		Success:    true,
+		Message:    messageText(c, MsgWebhookDeliveriesFetched),
+		Deliveries: deliveries,
+		Page:       page,
+		Limit:      limit,
+		TotalCount: int(totalCount),
+	})
+}
+
+// testWebhook aboneliğe deneme olayı gönderir ve sonucu döner (sadece admin erişimi)
+func testWebhook(c *gin.Context) {
+	idStr := c.Param("id")
+	subID, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		respondError(c, invalidParam("id"))
+		return
+	}
+
+	var sub WebhookSubscription
+	if err := DB.First(&sub, uint(subID)).Error; err != nil {
+		respondError(c, lookupError(err, CodeWebhookNotFound))
+		return
+	}
+
+	body, _ := json.Marshal(WebhookPayload{
+		Event:      EventWebhookTest,
+		OccurredAt: time.Now(),
+		Data:       gin.H{"subscription_id": sub.ID},
+	})
+
+	delivery := WebhookDelivery{
+		SubscriptionID: sub.ID,
+		Event:          EventWebhookTest,
+		Payload:        string(body),
+		Status:         "pending",
+		NextAttemptAt:  time.Now(),
+	}
+	if err := DB.Create(&delivery).Error; err != nil {
+		respondError(c, internalError(err, "Deneme gönderimi oluşturulamadı"))
+		return
+	}
+
+	// Deneme gönderimini hemen dene, başarısız olursa kuyruk tekrar deneyecek
+	deliverWebhook(&delivery)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": delivery.Status == "delivered",
+		"message": messageText(c, MsgWebhookTestSent),
+		"data":    delivery,
+	})
+}
+
+// retryWebhookDelivery başarısız bir gönderimi tekrar kuyruğa alır (sadece admin erişimi)
+func retryWebhookDelivery(c *gin.Context) {
+	idStr := c.Param("deliveryId")
+	deliveryID, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		respondError(c, invalidParam("deliveryId"))
+		return
+	}
+
+	var delivery WebhookDelivery
+	if err := DB.First(&delivery, uint(deliveryID)).Error; err != nil {
+		respondError(c, lookupError(err, CodeWebhookDeliveryNotFound))
+		return
+	}
+
+	if delivery.Status == "delivered" {
+		respondError(c, apiError(CodeWebhookDeliveryDelivered))
+		return
+	}
+
+	delivery.Status = "pending"
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+
+	if err := DB.Save(&delivery).Error; err != nil {
+		respondError(c, internalError(err, "Webhook gönderimi güncellenirken hata oluştu"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": messageText(c, MsgWebhookDeliveryRequeued),
+		"data":    delivery,
+	})
+}
+
+// ratingEventData puanlama olayı için webhook verisini hazırlar
+func ratingEventData(rating Rating) gin.H {
+	// Alıcının dili bilinmediği için metinler varsayılan dilde gönderilir
+	return gin.H{
+		"rating":        rating,
+		"problem_texts": ratingProblemTexts(rating, defaultLanguage),
+		"has_problems":  len(ratingProblemIDs(rating)) > 0,
+	}
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// webhookReceiver httptest sunucusuna gelen webhook isteklerini kaydeder
+type webhookReceiver struct {
+	mu       sync.Mutex
+	requests []receivedWebhook
+	statuses []int // Sırayla dönülecek yanıt kodları, bitince 200
+}
+
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	r.requests = append(r.requests, receivedWebhook{header: req.Header.Clone(), body: body})
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	r.mu.Unlock()
+
+	w.WriteHeader(status)
+}
+
+func (r *webhookReceiver) received() []receivedWebhook {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]receivedWebhook(nil), r.requests...)
+}
+
+// allowPrivateWebhookTargets httptest sunucusu 127.0.0.1'de çalıştığı için test süresince özel adreslere izin verir
+func allowPrivateWebhookTargets(t *testing.T) {
+	t.Helper()
+	previous := config.Webhooks.AllowPrivateTargets
+	config.Webhooks.AllowPrivateTargets = true
+	t.Cleanup(func() { config.Webhooks.AllowPrivateTargets = previous })
+}
+
+// createWebhookSubscription admin olarak abonelik oluşturur
+func (s *testServer) createWebhookSubscription(url, secret string, events ...string) WebhookSubscription {
+	s.t.Helper()
+
+	rec := s.request(http.MethodPost, "/api/admin/webhooks", WebhookSubscriptionRequest{URL: url, Secret: secret, Events: events}, s.adminHeaders())
+	expectStatus(s.t, rec, http.StatusCreated)
+
+	var resp WebhookSubscriptionResponse
+	decode(s.t, rec, &resp)
+	return *resp.Subscription
+}
+
+func TestWebhookRoutesRequireAdmin(t *testing.T) {
+	s := newTestServer(t)
+	cleaner := s.createUser("temizlikci", "Temizlikçi", "temizlikci")
+	body := WebhookSubscriptionRequest{URL: "https://ornek.example/hook"}
+
+	routes := []struct {
+		method, path string
+		body         interface{}
+	}{
+		{http.MethodGet, "/api/admin/webhooks", nil},
+		{http.MethodPost, "/api/admin/webhooks", body},
+		{http.MethodPut, "/api/admin/webhooks/1", body},
+		{http.MethodDelete, "/api/admin/webhooks/1", nil},
+		{http.MethodGet, "/api/admin/webhooks/1/deliveries", nil},
+		{http.MethodPost, "/api/admin/webhooks/1/test", nil},
+		{http.MethodPost, "/api/admin/webhooks/deliveries/1/retry", nil},
+	}
+	for _, route := range routes {
+		t.Run(route.method+" "+route.path, func(t *testing.T) {
+			expectError(t, s, route.method, route.path, route.body, nil, http.StatusUnauthorized, CodeUnauthorized)
+			expectError(t, s, route.method, route.path, route.body, staffHeaders(cleaner), http.StatusForbidden, CodeForbidden)
+		})
+	}
+}
+
+func TestWebhookRejectsPrivateTargets(t *testing.T) {
+	s := newTestServer(t)
+
+	for _, target := range []string{
+		"http://localhost:8080/hook",
+		"http://api.localhost/hook",
+		"http://127.0.0.1/hook",
+		"http://10.0.0.5/hook",
+		"http://192.168.1.10:9000/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]/hook",
+		"http://0.0.0.0/hook",
+	} {
+		t.Run(target, func(t *testing.T) {
+			resp := expectError(t, s, http.MethodPost, "/api/admin/webhooks", WebhookSubscriptionRequest{URL: target}, s.adminHeaders(), http.StatusBadRequest, CodeValidationFailed)
+			if len(resp.Details) != 1 || resp.Details[0].Field != "url" || resp.Details[0].Code != RulePublicHost {
+				t.Fatalf("Beklenmeyen alan hataları: %+v", resp.Details)
+			}
+		})
+	}
+
+	// Güncellemede de aynı kontrol yapılmalı
+	sub := s.createWebhookSubscription("https://ornek.example/hook", "")
+	resp := expectError(t, s, http.MethodPut, "/api/admin/webhooks/"+fmt.Sprint(sub.ID), WebhookSubscriptionRequest{URL: "http://127.0.0.1:6379"}, s.adminHeaders(), http.StatusBadRequest, CodeValidationFailed)
+	if len(resp.Details) != 1 || resp.Details[0].Code != RulePublicHost {
+		t.Fatalf("Beklenmeyen alan hataları: %+v", resp.Details)
+	}
+}
+
+func TestWebhookDialBlocksPrivateAddresses(t *testing.T) {
+	receiver := &webhookReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	// Kayıt sırasında alan adı olarak geçen ama iç ağa çözülen adresler bağlantı anında engellenir
+	_, err := sendWebhookRequest(WebhookSubscription{URL: server.URL}, &WebhookDelivery{Event: EventWebhookTest, Payload: "{}"})
+	if !errors.Is(err, errWebhookTargetBlocked) {
+		t.Fatalf("Özel adrese bağlantı engellenmeliydi, hata: %v", err)
+	}
+	if len(receiver.received()) != 0 {
+		t.Fatal("Engellenen adrese istek ulaşmamalı")
+	}
+}
+
+func TestWebhookDeliverySignatureRetryAndLog(t *testing.T) {
+	allowPrivateWebhookTargets(t)
+	receiver := &webhookReceiver{statuses: []int{http.StatusInternalServerError}}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	s := newTestServer(t)
+	const secret = "paylasilan-gizli-anahtar"
+	sub := s.createWebhookSubscription(server.URL+"/hook", secret, EventRatingCreated)
+
+	rec := s.request(http.MethodPost, "/api/rating", RatingRequest{Token: s.ratingToken(1), Rating: 2, Problems: []int{1}}, nil)
+	expectStatus(t, rec, http.StatusCreated)
+
+	// İlk deneme 500 alır ve geri çekilme süresiyle tekrar kuyruğa girer
+	before := time.Now()
+	processPendingWebhooks(context.Background())
+
+	var delivery WebhookDelivery
+	if err := s.db.Where("subscription_id = ?", sub.ID).First(&delivery).Error; err != nil {
+		t.Fatalf("Gönderim kaydı bulunamadı: %v", err)
+	}
+	if delivery.Status != "pending" || delivery.Attempts != 1 || delivery.ResponseStatus != http.StatusInternalServerError || delivery.LastError == "" {
+		t.Fatalf("Başarısız denemeden sonra beklenmeyen kayıt: %+v", delivery)
+	}
+	if wait := delivery.NextAttemptAt.Sub(before); wait < webhookBackoff(1) || wait > webhookBackoff(1)+time.Minute {
+		t.Fatalf("Sonraki deneme %v sonra, beklenen yaklaşık %v", wait, webhookBackoff(1))
+	}
+
+	// Zamanı gelmeyen gönderim tekrar denenmez
+	processPendingWebhooks(context.Background())
+	if got := len(receiver.received()); got != 1 {
+		t.Fatalf("Geri çekilme süresi dolmadan %d istek gönderildi", got)
+	}
+
+	if err := s.db.Model(&delivery).Update("next_attempt_at", time.Now().Add(-time.Second)).Error; err != nil {
+		t.Fatalf("Gönderim güncellenemedi: %v", err)
+	}
+	processPendingWebhooks(context.Background())
+
+	requests := receiver.received()
+	if len(requests) != 2 {
+		t.Fatalf("%d istek alındı, beklenen 2", len(requests))
+	}
+	for i, req := range requests {
+		timestamp := req.header.Get("X-Webhook-Timestamp")
+		if want := signWebhookPayload(secret, timestamp, req.body); req.header.Get("X-Webhook-Signature") != want {
+			t.Fatalf("%d. isteğin imzası %q, beklenen %q", i, req.header.Get("X-Webhook-Signature"), want)
+		}
+		if req.header.Get("X-Webhook-Event") != EventRatingCreated || req.header.Get("X-Webhook-Delivery") != fmt.Sprint(delivery.ID) {
+			t.Fatalf("%d. isteğin başlıkları hatalı: %v", i, req.header)
+		}
+	}
+
+	var payload WebhookPayload
+	if err := json.Unmarshal(requests[1].body, &payload); err != nil || payload.Event != EventRatingCreated {
+		t.Fatalf("Beklenmeyen gövde: %s (%v)", requests[1].body, err)
+	}
+
+	// Gönderim kaydı admin panelinde teslim edildi olarak görünmeli
+	rec = s.request(http.MethodGet, "/api/admin/webhooks/"+fmt.Sprint(sub.ID)+"/deliveries", nil, s.adminHeaders())
+	expectStatus(t, rec, http.StatusOK)
+	var log WebhookDeliveriesResponse
+	decode(t, rec, &log)
+	if log.TotalCount != 1 || len(log.Deliveries) != 1 {
+		t.Fatalf("Beklenmeyen gönderim kaydı: %+v", log)
+	}
+	got := log.Deliveries[0]
+	if got.Status != "delivered" || got.Attempts != 2 || got.ResponseStatus != http.StatusOK || got.DeliveredAt == nil || got.LastError != "" {
+		t.Fatalf("Teslim edilen gönderim kaydı hatalı: %+v", got)
+	}
+}
+
+func TestWebhookDeliveryFailsAfterMaxAttempts(t *testing.T) {
+	allowPrivateWebhookTargets(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	s := newTestServer(t)
+	sub := s.createWebhookSubscription(server.URL, "")
+
+	delivery := WebhookDelivery{SubscriptionID: sub.ID, Event: EventWebhookTest, Payload: "{}", Status: "pending", Attempts: webhookMaxAttempts - 1, NextAttemptAt: time.Now()}
+	if err := s.db.Create(&delivery).Error; err != nil {
+		t.Fatalf("Gönderim eklenemedi: %v", err)
+	}
+
+	deliverWebhook(&delivery)
+	if delivery.Status != "failed" || delivery.Attempts != webhookMaxAttempts || delivery.ResponseStatus != http.StatusBadGateway {
+		t.Fatalf("Son denemeden sonra gönderim başarısız sayılmalı: %+v", delivery)
+	}
+
+	// Başarısız gönderim elle tekrar kuyruğa alınabilir
+	rec := s.request(http.MethodPost, "/api/admin/webhooks/deliveries/"+fmt.Sprint(delivery.ID)+"/retry", nil, s.adminHeaders())
+	expectStatus(t, rec, http.StatusOK)
+	if err := s.db.First(&delivery, delivery.ID).Error; err != nil || delivery.Status != "pending" || delivery.Attempts != 0 {
+		t.Fatalf("Gönderim tekrar kuyruğa alınmadı: %+v (%v)", delivery, err)
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, webhookBaseBackoff},
+		{2, 2 * webhookBaseBackoff},
+		{3, 4 * webhookBaseBackoff},
+		{webhookMaxAttempts, webhookMaxBackoff},
+		{100, webhookMaxBackoff},
+	}
+	for _, tt := range tests {
+		if got := webhookBackoff(tt.attempts); got != tt.want {
+			t.Errorf("webhookBackoff(%d) = %v, beklenen %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestWebhookEnqueueErrorRollsBackRating(t *testing.T) {
+	s := newTestServer(t)
+	s.createWebhookSubscription("https://ornek.example/hook", "", EventRatingCreated)
+
+	// Kuyruğa ekleme başarısız olursa puan da kaydedilmemeli
+	if err := s.db.Exec("DROP TABLE webhook_deliveries").Error; err != nil {
+		t.Fatalf("Tablo silinemedi: %v", err)
+	}
+	expectError(t, s, http.MethodPost, "/api/rating", RatingRequest{Token: s.ratingToken(1), Rating: 4}, nil, http.StatusInternalServerError, CodeInternal)
+
+	var count int64
+	s.db.Model(&Rating{}).Count(&count)
+	if count != 0 {
+		t.Fatalf("Transaction geri alınmalıydı, %d puan kaydedildi", count)
+	}
+}
+
+func TestWebhookCreatedInactive(t *testing.T) {
+	s := newTestServer(t)
+	inactive := false
+
+	rec := s.request(http.MethodPost, "/api/admin/webhooks", WebhookSubscriptionRequest{URL: "https://ornek.example/hook", IsActive: &inactive}, s.adminHeaders())
+	expectStatus(t, rec, http.StatusCreated)
+
+	var sub WebhookSubscription
+	if err := s.db.First(&sub).Error; err != nil || sub.IsActive {
+		t.Fatalf("Pasif oluşturulan abonelik pasif kalmalı: %+v (%v)", sub, err)
+	}
+}
@@ -0,0 +1,37 @@
+// Temizlik görevlisi cihazlarına gelen push bildirimlerini gösterir
+self.addEventListener('push', (event) => {
+  let data = {};
+  try {
+    data = event.data ? event.data.json() : {};
+  } catch (error) {
+    data = { subject: 'Temizlik Takip', body: event.data ? event.data.text() : '' };
+  }
+
+  event.waitUntil(
+    self.registration.showNotification(data.subject || 'Temizlik Takip', {
+      body: data.body || '',
+      tag: data.event,
+      renotify: true,
+      requireInteraction: data.event === 'task.assigned',
+      vibrate: [200, 100, 200],
+      data: { url: data.url || '/cleaner' }
+    })
+  );
+});
+
+// Bildirime tıklanınca paneli aç
+self.addEventListener('notificationclick', (event) => {
+  event.notification.close();
+  const url = event.notification.data && event.notification.data.url;
+
+  event.waitUntil(
+    self.clients.matchAll({ type: 'window', includeUncontrolled: true }).then((clients) => {
+      for (const client of clients) {
+        if (client.url.includes(url) && 'focus' in client) {
+          return client.focus();
+        }
+      }
+      return self.clients.openWindow(url);
+    })
+  );
+});
@@ -3,6 +3,15 @@ import { useNavigate } from 'react-router-dom';
 import Header from '../components/Header';
 import '../components/AdminPanel.css';
 
+// Yönetim route'ları admin oturumu ister; kayıtlı oturumdan başlıkları oluşturur
+const authHeaders = () => {
+  const savedUser = JSON.parse(localStorage.getItem('user') || '{}');
+  return {
+    'Authorization': `Bearer ${localStorage.getItem('authToken')}`,
+    'X-User-ID': String(savedUser.id)
+  };
+};
+
 const AdminPanel = () => {
   const [user, setUser] = useState(null);
   const [loading, setLoading] = useState(true);
@@ -24,7 +33,8 @@ const AdminPanel = () => {
     username: '',
     password: '',
     name: '',
-    role: 'temizlikci'
+    role: 'temizlikci',
+    language: ''
   });
   const navigate = useNavigate();
 
@@ -62,7 +72,9 @@ const AdminPanel = () => {
   const fetchUsers = async () => {
     setLoadingUsers(true);
     try {
-      const response = await fetch('http://localhost:8080/api/admin/users');
+      const response = await fetch('http://localhost:8080/api/admin/users', {
+        headers: authHeaders()
+      });
       const data = await response.json();
       if (data.success) {
         setUsers(data.users || []);
@@ -93,10 +105,36 @@ const AdminPanel = () => {
     }
   };
 
+  // QR sayfası sadece admin oturumuyla indirilebildiği için PDF başlıklarla alınıp yeni sekmede açılır
+  const printQRSheet = async () => {
+    try {
+      const token = localStorage.getItem('authToken');
+      const response = await fetch('http://localhost:8080/api/admin/qr/sheet', {
+        headers: {
+          'Authorization': `Bearer ${token}`,
+          'X-User-ID': user.id.toString()
+        }
+      });
+      if (!response.ok) {
+        const data = await response.json();
+        alert(data.message);
+        return;
+      }
+      const url = URL.createObjectURL(await response.blob());
+      window.open(url, '_blank');
+      setTimeout(() => URL.revokeObjectURL(url), 60000);
+    } catch (error) {
+      console.error('QR kodları getirilirken hata oluştu:', error);
+      alert('QR kodları getirilirken hata oluştu!');
+    }
+  };
+
   const fetchStats = async () => {
     setLoadingStats(true);
     try {
-      const response = await fetch('http://localhost:8080/api/admin/stats');
+      const response = await fetch('http://localhost:8080/api/admin/stats', {
+        headers: authHeaders()
+      });
       const data = await response.json();
       if (data.success) {
         setStats(data);
@@ -110,6 +148,29 @@ const AdminPanel = () => {
     }
   };
 
+  // Dışa aktarmalar admin oturumu istediği için dosya başlıklarla alınıp indirilir
+  const downloadExport = async (name) => {
+    try {
+      const response = await fetch(`http://localhost:8080/api/admin/export/${name}?format=xlsx`, {
+        headers: authHeaders()
+      });
+      if (!response.ok) {
+        const data = await response.json();
+        alert(data.message);
+        return;
+      }
+      const url = URL.createObjectURL(await response.blob());
+      const link = document.createElement('a');
+      link.href = url;
+      link.download = `${name}.xlsx`;
+      link.click();
+      setTimeout(() => URL.revokeObjectURL(url), 60000);
+    } catch (error) {
+      console.error('Dosya indirilirken hata oluştu:', error);
+      alert('Dosya indirilirken hata oluştu!');
+    }
+  };
+
   const handleAddUser = async (e) => {
     e.preventDefault();
     try {
@@ -117,6 +178,7 @@ const AdminPanel = () => {
         method: 'POST',
         headers: {
           'Content-Type': 'application/json',
+          ...authHeaders()
         },
         body: JSON.stringify(newUser),
       });
@@ -124,7 +186,7 @@ const AdminPanel = () => {
       const data = await response.json();
       if (data.success) {
         alert('Kullanıcı başarıyla eklendi!');
-        setNewUser({ username: '', password: '', name: '', role: 'temizlikci' });
+        setNewUser({ username: '', password: '', name: '', role: 'temizlikci', language: '' });
         setShowAddUser(false);
         fetchUsers();
       } else {
@@ -143,11 +205,13 @@ const AdminPanel = () => {
         method: 'PUT',
         headers: {
           'Content-Type': 'application/json',
+          ...authHeaders()
         },
         body: JSON.stringify({
           username: editingUser.username,
           name: editingUser.name,
           role: editingUser.role,
+          ...(editingUser.language && { language: editingUser.language }),
           ...(editingUser.password && { password: editingUser.password })
         }),
       });
@@ -174,6 +238,7 @@ const AdminPanel = () => {
     try {
       const response = await fetch(`http://localhost:8080/api/admin/users/${userId}`, {
         method: 'DELETE',
+        headers: authHeaders()
       });
 
       const data = await response.json();
@@ -192,7 +257,14 @@ const AdminPanel = () => {
   const fetchToiletRatings = async (toiletId, page = 1) => {
     setLoadingRatings(true);
     try {
-      const response = await fetch(`http://localhost:8080/api/toilet/${toiletId}/ratings/paginated?page=${page}&limit=10`);
+      // Problem metinleri adminin dil tercihinde gelsin diye oturum bilgisi gönderilir
+      const token = localStorage.getItem('authToken');
+      const response = await fetch(`http://localhost:8080/api/toilet/${toiletId}/ratings/paginated?page=${page}&limit=10`, {
+        headers: {
+          'Authorization': `Bearer ${token}`,
+          'X-User-ID': user.id.toString()
+        }
+      });
       const data = await response.json();
       if (data.success) {
         setToiletRatings(data.data || []);
@@ -275,12 +347,20 @@ const AdminPanel = () => {
           <div className="toilet-status-section">
             <div className="section-header">
               <h2>Tuvalet Durumları</h2>
-              <button 
-                className="btn-secondary" 
-                onClick={fetchToiletStatuses}
-              >
-                Yenile
-              </button>
+              <div>
+                <button 
+                  className="btn-secondary" 
+                  onClick={printQRSheet}
+                >
+                  QR Kodlarını Yazdır
+                </button>
+                <button 
+                  className="btn-secondary" 
+                  onClick={fetchToiletStatuses}
+                >
+                  Yenile
+                </button>
+              </div>
             </div>
 
             {loadingToilets ? (
@@ -472,6 +552,26 @@ const AdminPanel = () => {
                 {/* Temizlikçi İstatistikleri */}
                 <div className="cleaner-stats">
                   <h3>Temizlikçi Performansları</h3>
+                  <div className="export-buttons">
+                    <button
+                      className="btn-secondary"
+                      onClick={() => downloadExport('cleaner-stats')}
+                    >
+                      İstatistikleri İndir (Excel)
+                    </button>
+                    <button
+                      className="btn-secondary"
+                      onClick={() => downloadExport('ratings')}
+                    >
+                      Değerlendirmeleri İndir (Excel)
+                    </button>
+                    <button
+                      className="btn-secondary"
+                      onClick={() => downloadExport('tasks')}
+                    >
+                      Görevleri İndir (Excel)
+                    </button>
+                  </div>
                   <div className="cleaner-stats-table">
                     <table>
                       <thead>
@@ -480,10 +580,12 @@ const AdminPanel = () => {
                           <th>Durum</th>
                           <th>Toplam Temizlik</th>
                           <th>Ortalama Süre</th>
+                          <th>Medyan</th>
+                          <th>%90</th>
                           <th>En Hızlı</th>
                           <th>En Yavaş</th>
-                          <th>Bu Hafta</th>
-                          <th>Bu Ay</th>
+                          <th>Son 7 Gün</th>
+                          <th>Son 30 Gün</th>
                           <th>Devam Eden</th>
                         </tr>
                       </thead>
@@ -498,18 +600,28 @@ const AdminPanel = () => {
                             </td>
                             <td>{cleaner.total_completed_tasks}</td>
                             <td>
-                              {cleaner.average_cleaning_time >= 0 
-                                ? `${Math.round(cleaner.average_cleaning_time)} dk` 
+                              {cleaner.average_cleaning_time != null
+                                ? `${cleaner.average_cleaning_time.toFixed(1)} dk`
+                                : '-'}
+                            </td>
+                            <td>
+                              {cleaner.median_cleaning_time != null
+                                ? `${cleaner.median_cleaning_time.toFixed(1)} dk`
                                 : '-'}
                             </td>
                             <td>
-                              {cleaner.fastest_cleaning_time >= 0 
-                                ? `${Math.round(cleaner.fastest_cleaning_time)} dk` 
+                              {cleaner.p90_cleaning_time != null
+                                ? `${cleaner.p90_cleaning_time.toFixed(1)} dk`
                                 : '-'}
                             </td>
                             <td>
-                              {cleaner.slowest_cleaning_time >= 0 
-                                ? `${Math.round(cleaner.slowest_cleaning_time)} dk` 
+                              {cleaner.fastest_cleaning_time != null
+                                ? `${cleaner.fastest_cleaning_time.toFixed(1)} dk`
+                                : '-'}
+                            </td>
+                            <td>
+                              {cleaner.slowest_cleaning_time != null
+                                ? `${cleaner.slowest_cleaning_time.toFixed(1)} dk`
                                 : '-'}
                             </td>
                             <td>{cleaner.last_week_tasks}</td>
@@ -573,6 +685,17 @@ const AdminPanel = () => {
                     <option value="admin">Admin</option>
                   </select>
                 </div>
+                <div className="form-group">
+                  <label>Dil:</label>
+                  <select
+                    value={newUser.language || ''}
+                    onChange={(e) => setNewUser({...newUser, language: e.target.value})}
+                  >
+                    <option value="">Tarayıcı dili</option>
+                    <option value="tr">Türkçe</option>
+                    <option value="en">English</option>
+                  </select>
+                </div>
                 <div className="modal-buttons">
                   <button type="submit" className="btn-primary">Ekle</button>
                   <button 
@@ -630,6 +753,17 @@ const AdminPanel = () => {
                     <option value="admin">Admin</option>
                   </select>
                 </div>
+                <div className="form-group">
+                  <label>Dil:</label>
+                  <select
+                    value={editingUser.language || ''}
+                    onChange={(e) => setEditingUser({...editingUser, language: e.target.value})}
+                  >
+                    <option value="">Tarayıcı dili</option>
+                    <option value="tr">Türkçe</option>
+                    <option value="en">English</option>
+                  </select>
+                </div>
                 <div className="modal-buttons">
                   <button type="submit" className="btn-primary">Güncelle</button>
                   <button 
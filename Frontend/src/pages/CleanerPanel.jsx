@@ -7,8 +7,11 @@ const CleanerPanel = () => {
   const [user, setUser] = useState(null);
   const [toilets, setToilets] = useState([]);
   const [loading, setLoading] = useState(true);
+  const [pushEnabled, setPushEnabled] = useState(false);
   const navigate = useNavigate();
 
+  const pushSupported = 'serviceWorker' in navigator && 'PushManager' in window;
+
   // Problem türleri
   const problemTypes = {
     1: "Tuvalet Kağıdı yok",
@@ -67,6 +70,102 @@ const CleanerPanel = () => {
     }
   }, [navigate]);
 
+  // Bu cihazda push aboneliği var mı kontrol et
+  useEffect(() => {
+    if (!pushSupported) return;
+
+    navigator.serviceWorker.getRegistration('/sw.js').then(async (registration) => {
+      if (!registration) return;
+      const subscription = await registration.pushManager.getSubscription();
+      setPushEnabled(!!subscription);
+    });
+  }, [pushSupported]);
+
+  // VAPID açık anahtarını PushManager'ın beklediği formata çevir
+  const urlBase64ToUint8Array = (base64String) => {
+    const padding = '='.repeat((4 - (base64String.length % 4)) % 4);
+    const base64 = (base64String + padding).replace(/-/g, '+').replace(/_/g, '/');
+    const rawData = window.atob(base64);
+    return Uint8Array.from([...rawData].map((char) => char.charCodeAt(0)));
+  };
+
+  // Cihaz bildirimlerini aç
+  const enablePushNotifications = async () => {
+    try {
+      const permission = await Notification.requestPermission();
+      if (permission !== 'granted') {
+        alert('Bildirim izni verilmedi');
+        return;
+      }
+
+      const keyResponse = await fetch('http://localhost:8080/api/push/vapid-public-key');
+      const keyData = await keyResponse.json();
+      if (!keyData.success) {
+        alert(keyData.message);
+        return;
+      }
+
+      const registration = await navigator.serviceWorker.register('/sw.js');
+      await navigator.serviceWorker.ready;
+
+      const subscription = await registration.pushManager.subscribe({
+        userVisibleOnly: true,
+        applicationServerKey: urlBase64ToUint8Array(keyData.public_key)
+      });
+
+      const token = localStorage.getItem('authToken');
+      const response = await fetch('http://localhost:8080/api/push/subscriptions', {
+        method: 'POST',
+        headers: {
+          'Content-Type': 'application/json',
+          'Authorization': `Bearer ${token}`,
+          'X-User-ID': user.id.toString()
+        },
+        body: JSON.stringify(subscription.toJSON())
+      });
+
+      const data = await response.json();
+      if (data.success) {
+        setPushEnabled(true);
+        alert('Bildirimler bu cihaz için açıldı!');
+      } else {
+        alert(data.message);
+      }
+    } catch (error) {
+      console.error('Bildirimler açılamadı:', error);
+      alert('Bildirimler açılırken hata oluştu');
+    }
+  };
+
+  // Cihaz bildirimlerini kapat
+  const disablePushNotifications = async () => {
+    try {
+      const registration = await navigator.serviceWorker.getRegistration('/sw.js');
+      const subscription = registration && await registration.pushManager.getSubscription();
+      if (!subscription) {
+        setPushEnabled(false);
+        return;
+      }
+
+      const token = localStorage.getItem('authToken');
+      await fetch('http://localhost:8080/api/push/subscriptions', {
+        method: 'DELETE',
+        headers: {
+          'Content-Type': 'application/json',
+          'Authorization': `Bearer ${token}`,
+          'X-User-ID': user.id.toString()
+        },
+        body: JSON.stringify({ endpoint: subscription.endpoint })
+      });
+
+      await subscription.unsubscribe();
+      setPushEnabled(false);
+    } catch (error) {
+      console.error('Bildirimler kapatılamadı:', error);
+      alert('Bildirimler kapatılırken hata oluştu');
+    }
+  };
+
   // Problemleri parse et
   const parseProblems = (problemsStr) => {
     try {
@@ -362,6 +461,14 @@ const CleanerPanel = () => {
             >
               Durumları Yenile
             </button>
+            {pushSupported && (
+              <button
+                className="btn-secondary"
+                onClick={pushEnabled ? disablePushNotifications : enablePushNotifications}
+              >
+                {pushEnabled ? 'Bildirimleri Kapat' : 'Bildirimleri Aç'}
+              </button>
+            )}
           </div>
         </div>
       </div>
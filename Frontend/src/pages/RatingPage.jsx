@@ -7,6 +7,8 @@ const RatingPage = () => {
   const location = useLocation();
   const navigate = useNavigate();
   const [toiletId, setToiletId] = useState(null);
+  const [ratingToken, setRatingToken] = useState(null);
+  const [tokenError, setTokenError] = useState('');
   const [toiletName, setToiletName] = useState('');
   const [toilets, setToilets] = useState([]);
   const [showToiletSelection, setShowToiletSelection] = useState(false);
@@ -14,23 +16,80 @@ const RatingPage = () => {
   const [hoveredRating, setHoveredRating] = useState(0);
   const [selectedProblems, setSelectedProblems] = useState([]);
   const [otherProblemText, setOtherProblemText] = useState('');
+  // Katalog yüklenemezse varsayılan Türkçe seçenekler gösterilir
+  const [problemTypes, setProblemTypes] = useState([
+    { id: 1, label: 'Tuvalet Kağıdı yok' },
+    { id: 2, label: 'Sabun yok' },
+    { id: 3, label: 'Peçete yok' },
+    { id: 4, label: 'Çöp kutusu dolu' },
+    { id: 5, label: 'Klozet kirli' },
+    { id: 6, label: 'Diğer' }
+  ]);
+
+  // Giriş yapmış personel tuvalet ID'si ile, ziyaretçiler QR koddaki token ile puanlar
+  const staffUser = JSON.parse(localStorage.getItem('user') || 'null');
+  const staffToken = localStorage.getItem('authToken');
+  const isStaff = !!(staffUser && staffToken);
 
   useEffect(() => {
-    // URL parametresinden veya query parametresinden toilet ID'sini al
+    // QR koddan gelen token'ı veya personel için toilet ID'sini al
     const searchParams = new URLSearchParams(location.search);
+    const queryToken = searchParams.get('token');
     const queryToiletId = searchParams.get('toilet');
     
     const finalToiletId = paramToiletId || queryToiletId;
     
-    if (finalToiletId) {
+    if (queryToken) {
+      setRatingToken(queryToken);
+      resolveToken(queryToken);
+    } else if (finalToiletId && isStaff) {
       setToiletId(finalToiletId);
       fetchToiletInfo(finalToiletId);
-    } else {
+    } else if (isStaff) {
       // Tuvalet seçilmemişse, seçim ekranını göster
       setShowToiletSelection(true);
       fetchToilets();
     }
-  }, [paramToiletId, location.search]);
+  }, [paramToiletId, location.search, isStaff]);
+
+  useEffect(() => {
+    fetchProblemTypes();
+  }, []);
+
+  // Problem seçeneklerini ziyaretçinin tarayıcı dilinde getirir
+  const fetchProblemTypes = async () => {
+    try {
+      const response = await fetch('http://localhost:8080/api/problem-types', {
+        headers: {
+          'Accept-Language': navigator.language || 'tr'
+        }
+      });
+      const data = await response.json();
+
+      if (data.success && data.problem_types.length > 0) {
+        setProblemTypes(data.problem_types);
+      }
+    } catch (error) {
+      console.error('Problem türleri getirilemedi:', error);
+    }
+  };
+
+  const resolveToken = async (token) => {
+    try {
+      const response = await fetch(`http://localhost:8080/api/rating-token/${encodeURIComponent(token)}`);
+      const data = await response.json();
+      
+      if (data.success) {
+        setToiletId(data.data.id);
+        setToiletName(data.data.name);
+      } else {
+        setTokenError(data.message);
+      }
+    } catch (error) {
+      console.error('QR kod doğrulanamadı:', error);
+      setTokenError('Bağlantı hatası oluştu. Lütfen tekrar deneyin.');
+    }
+  };
 
   const fetchToilets = async () => {
     try {
@@ -95,13 +154,18 @@ const RatingPage = () => {
   const handleSubmit = async () => {
     if (rating > 0) {
       try {
+        const headers = { 'Content-Type': 'application/json' };
+        if (!ratingToken) {
+          headers['Authorization'] = `Bearer ${staffToken}`;
+          headers['X-User-ID'] = staffUser.id.toString();
+        }
+
         const response = await fetch('http://localhost:8080/api/rating', {
           method: 'POST',
-          headers: {
-            'Content-Type': 'application/json',
-          },
+          headers,
           body: JSON.stringify({
-            toilet_id: parseInt(toiletId),
+            token: ratingToken || undefined,
+            toilet_id: ratingToken ? undefined : parseInt(toiletId),
             rating: rating,
             problems: selectedProblems,
             other_text: otherProblemText
@@ -165,7 +229,12 @@ const RatingPage = () => {
     return (
       <div className="rating-page">
         <div className="rating-container">
-          <h1>Geçersiz tuvalet seçimi</h1>
+          <h1>
+            {tokenError ||
+              (ratingToken
+                ? 'QR kod doğrulanıyor...'
+                : 'Değerlendirme yapmak için tuvalet kapısındaki QR kodu okutun')}
+          </h1>
           <button onClick={() => navigate('/')} className="submit-button">
             Geri Dön
           </button>
@@ -207,20 +276,15 @@ const RatingPage = () => {
         <h2 className="page-subtitle">Sorunlardan biri varsa seçiniz yoksa devam edin</h2>
 
         <div className="problem-options">
-          {[1, 2, 3, 4, 5, 6].map((problemId) => (
+          {problemTypes.map((problem) => (
             <button
-              key={problemId}
+              key={problem.id}
               className={`problem-button ${
-                selectedProblems.includes(problemId) ? 'problem-selected' : 'problem-unselected'
+                selectedProblems.includes(problem.id) ? 'problem-selected' : 'problem-unselected'
               }`}
-              onClick={() => handleProblemToggle(problemId)}
+              onClick={() => handleProblemToggle(problem.id)}
             >
-              {problemId === 1 ? "Tuvalet Kağıdı yok" : ""}
-              {problemId === 2 ? "Sabun yok" : ""}
-              {problemId === 3 ? "Peçete yok" : ""}
-              {problemId === 4 ? "Çöp kutusu dolu" : ""}
-              {problemId === 5 ? "Klozet kirli" : ""}
-              {problemId === 6 ? "Diğer" : ""}
+              {problem.label}
             </button>
           ))}
         </div>